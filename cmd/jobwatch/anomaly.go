@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/hunterheston/airbnb/pkg/config"
+	"github.com/hunterheston/airbnb/pkg/notify"
+	"github.com/hunterheston/airbnb/pkg/store"
+)
+
+// checkAnomaly compares run, not yet recorded, with the runs before it and
+// tells the anomaly notifiers if its job count looks wrong.
+func checkAnomaly(ctx context.Context, cfg *config.Config, db *store.Store, run store.Run) {
+	notifier, err := cfg.AnomalyNotifier()
+	if err != nil {
+		slog.Error("configuring anomaly notifiers", "err", err)
+		return
+	}
+	if notifier == nil || ctx.Err() != nil {
+		return
+	}
+	runs, err := db.Runs(cfg.Anomalies.Window)
+	if err != nil {
+		slog.Error("reading past runs", "err", err)
+		return
+	}
+	earlier := make([]int, len(runs))
+	for i, r := range runs {
+		earlier[i] = r.Found
+	}
+	reason := cfg.Anomalies.Check(run.Found, earlier)
+	if reason == "" {
+		return
+	}
+	slog.Warn("anomalous run", "reason", reason)
+	msg := "The last scrape " + reason + ". The selectors may be broken: try `jobwatch doctor`."
+	if err := notify.Alert(ctx, notifier, msg); err != nil {
+		slog.Error("sending anomaly alert", "err", err)
+	}
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/hunterheston/airbnb/pkg/config"
+	"github.com/hunterheston/airbnb/pkg/store"
+)
+
+// runBackfill seeds the database with every job the sources list now and
+// marks them as sent, so that a new setup's first digest only has the jobs
+// posted after it rather than all the ones already open.
+func runBackfill(ctx context.Context, args []string) error {
+	fs, configPath := flagSet("backfill")
+	force := forceFlag(fs)
+	cfg, err := parse(fs, configPath, args)
+	if err != nil {
+		return err
+	}
+	release, err := lock(cfg, *force)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	db, err := store.Open(cfg.Database)
+	if err != nil {
+		return fmt.Errorf("opening store: %w", err)
+	}
+	defer db.Close()
+
+	// The jobs are old news, so nothing is pushed.
+	quiet := *cfg
+	quiet.Push = config.Push{}
+	quiet.Routes = slices.DeleteFunc(slices.Clone(cfg.Routes), func(r config.Route) bool { return r.Push })
+
+	ctx, cancel := runContext(ctx, cfg)
+	defer cancel()
+	matched, fresh, err := scrape(ctx, &quiet, db)
+	// What an interrupted backfill recorded is skipped too; running it
+	// again picks up the rest.
+	skipped, serr := db.SkipPending(time.Now())
+	if serr != nil {
+		return fmt.Errorf("marking jobs as sent: %w", serr)
+	}
+	if err != nil {
+		return err
+	}
+	slog.Info("backfill finished", "matched", len(matched), "new", len(fresh), "marked_sent", skipped)
+	fmt.Printf("%d jobs on record, %d of them new; the next digest starts from here\n", len(matched), len(fresh))
+	return nil
+}
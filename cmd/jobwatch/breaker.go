@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/hunterheston/airbnb/pkg/config"
+	"github.com/hunterheston/airbnb/pkg/notify"
+	"github.com/hunterheston/airbnb/pkg/scraper"
+	"github.com/hunterheston/airbnb/pkg/store"
+)
+
+// skipBroken drops the sources whose circuit breaker is open, returning an
+// error for each so the digest says what was left out. The health it read
+// is returned for trackHealth.
+func skipBroken(cfg *config.Config, db *store.Store, sources []scraper.Source, now time.Time) ([]scraper.Source, []error, map[string]store.SourceHealth) {
+	health, err := db.SourceHealth()
+	if err != nil {
+		// Without the history, scrape everything rather than nothing.
+		slog.Error("reading source health", "err", err)
+		return sources, nil, nil
+	}
+	var kept []scraper.Source
+	var skipped []error
+	for _, src := range sources {
+		h := health[src.Name()]
+		if until, skip := cfg.CircuitBreaker.Skip(h.OpenedAt, now); skip {
+			slog.Warn("skipping failing source", "source", src.Name(), "failures", h.Failures, "until", until)
+			skipped = append(skipped, fmt.Errorf("%s: skipped until %s after %d failed runs in a row", src.Name(), until.Format(time.DateTime), h.Failures))
+			continue
+		}
+		kept = append(kept, src)
+	}
+	return kept, skipped, health
+}
+
+// trackHealth counts the sources that failed outright, with no jobs at all,
+// opening the breaker of any that reached the limit, and clears the record
+// of those that worked. Both changes of state are alerted on.
+func trackHealth(ctx context.Context, cfg *config.Config, db *store.Store, results []scraper.Result, health map[string]store.SourceHealth, now time.Time) {
+	if health == nil || ctx.Err() != nil {
+		// An interrupted run says nothing about the sources.
+		return
+	}
+	for _, res := range results {
+		before, failing := health[res.Source]
+		if res.Err == nil || len(res.Jobs) > 0 {
+			if !failing {
+				continue
+			}
+			if err := db.SourceSucceeded(res.Source); err != nil {
+				slog.Error("recording source health", "source", res.Source, "err", err)
+			}
+			if before.OpenedAt != nil {
+				slog.Info("source works again", "source", res.Source)
+				alert(ctx, cfg, fmt.Sprintf("Scraping %s works again after %d failed runs in a row.", res.Source, before.Failures))
+			}
+			continue
+		}
+
+		h, err := db.SourceFailed(res.Source, res.Err.Error())
+		if err != nil {
+			slog.Error("recording source health", "source", res.Source, "err", err)
+			continue
+		}
+		if !cfg.CircuitBreaker.Trips(h.Failures) {
+			continue
+		}
+		if err := db.OpenBreaker(res.Source, now); err != nil {
+			slog.Error("recording source health", "source", res.Source, "err", err)
+			continue
+		}
+		until := now.Add(cfg.CircuitBreaker.Cooldown)
+		slog.Warn("circuit breaker opened", "source", res.Source, "failures", h.Failures, "until", until)
+		alert(ctx, cfg, fmt.Sprintf("Stopped scraping %s after %d failed runs in a row (last error: %v). It will be tried again after %s.",
+			res.Source, h.Failures, res.Err, until.Format(time.DateTime)))
+	}
+}
+
+// alert sends msg to the alert notifiers, logging any failure.
+func alert(ctx context.Context, cfg *config.Config, msg string) {
+	notifier, err := cfg.AlertNotifier()
+	if err != nil {
+		slog.Error("configuring alert notifiers", "err", err)
+		return
+	}
+	if err := notify.Alert(ctx, notifier, msg); err != nil {
+		slog.Error("sending alert", "err", err)
+	}
+}
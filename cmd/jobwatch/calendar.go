@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/hunterheston/airbnb/pkg/config"
+	"github.com/hunterheston/airbnb/pkg/gcal"
+	"github.com/hunterheston/airbnb/pkg/store"
+)
+
+// syncCalendar writes the interviews and deadlines changed since the last
+// sync to the configured calendar, deleting the events of the ones that
+// were cleared, and returns how many jobs it wrote. A job that fails is
+// tried again next time.
+func syncCalendar(ctx context.Context, cfg *config.Config, db *store.Store) (int, error) {
+	gc := cfg.GoogleCalendar()
+	if gc == nil {
+		return 0, nil
+	}
+	// Taken first, so that a date changed during the sync is synced again.
+	now := time.Now()
+	jobs, err := db.CalendarPending()
+	if err != nil || len(jobs) == 0 {
+		return 0, err
+	}
+	cal, err := gcal.Open(ctx, *gc)
+	if err != nil {
+		return 0, err
+	}
+	for i, j := range jobs {
+		if err := putDates(ctx, cal, j); err != nil {
+			return i, fmt.Errorf("job %d: %w", j.ID, err)
+		}
+		if err := db.MarkCalendared(j.ID, now); err != nil {
+			return i, err
+		}
+	}
+	return len(jobs), nil
+}
+
+// putDates writes j's interview and deadline events, or deletes them if
+// it has none.
+func putDates(ctx context.Context, cal *gcal.Calendar, j store.Job) error {
+	title := fmt.Sprintf("%s at %s", j.Title, j.Company)
+	description := j.URL
+	if j.Application != "" {
+		description = "Application: " + strings.ReplaceAll(j.Application, "_", " ") + "\n" + description
+	}
+
+	id := gcal.EventID("jobwatch", "interview", j.Key())
+	if j.InterviewAt == nil {
+		if err := cal.Delete(ctx, id); err != nil {
+			return err
+		}
+	} else if err := cal.Put(ctx, gcal.Event{
+		ID:          id,
+		Summary:     "Interview: " + title,
+		Description: description,
+		Start:       *j.InterviewAt,
+		End:         j.InterviewAt.Add(cal.InterviewLength),
+	}); err != nil {
+		return err
+	}
+
+	id = gcal.EventID("jobwatch", "deadline", j.Key())
+	if j.Deadline == nil {
+		return cal.Delete(ctx, id)
+	}
+	day := j.Deadline.Local()
+	return cal.Put(ctx, gcal.Event{
+		ID:          id,
+		Summary:     "Application deadline: " + title,
+		Description: description,
+		Start:       day,
+		End:         day,
+		AllDay:      true,
+	})
+}
+
+// updateCalendar is syncCalendar for runs, which log what goes wrong and
+// carry on.
+func updateCalendar(ctx context.Context, cfg *config.Config, db *store.Store) {
+	n, err := syncCalendar(ctx, cfg, db)
+	if n > 0 {
+		slog.Info("updated the calendar", "jobs", n)
+	}
+	if err != nil {
+		slog.Error("updating the calendar", "err", err)
+	}
+}
+
+// pollCalendar syncs the calendar every cfg.Calendar.Interval until ctx is
+// cancelled, so that dates set in the dashboard show up without waiting
+// for a run. A sync in progress gets work, so that it finishes first.
+func pollCalendar(ctx, work context.Context, cfg *config.Config) {
+	ticker := time.NewTicker(cfg.Calendar.Interval)
+	defer ticker.Stop()
+	for {
+		syncCtx, cancel := runContext(work, cfg)
+		db, err := store.Open(cfg.Database)
+		if err != nil {
+			slog.Error("opening store", "err", err)
+		} else {
+			updateCalendar(syncCtx, cfg, db)
+			db.Close()
+		}
+		cancel()
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
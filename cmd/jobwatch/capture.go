@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/hunterheston/airbnb/pkg/errreport"
+	"github.com/hunterheston/airbnb/pkg/trace"
+)
+
+// reporter is the loaded config's error reporter, or nil.
+var reporter errreport.Reporter
+
+// capture sends e to the error reporter, if there is one. It has a
+// deadline of its own, so that a run cancelled on shutdown still gets its
+// errors reported.
+func capture(e errreport.Event) {
+	if reporter == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := reporter.Report(ctx, e); err != nil {
+		slog.Warn("reporting an error failed", "err", err)
+	}
+}
+
+// flushTraces exports the spans recorded so far, if tracing is on, with a
+// deadline of its own like capture's.
+func flushTraces() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := trace.Default().Flush(ctx); err != nil {
+		slog.Warn("exporting traces failed", "err", err)
+	}
+}
+
+// withTags returns e tagged with the key-value pairs kv.
+func withTags(e errreport.Event, kv ...string) errreport.Event {
+	if e.Tags == nil {
+		e.Tags = map[string]string{}
+	}
+	for i := 0; i+1 < len(kv); i += 2 {
+		e.Tags[kv[i]] = kv[i+1]
+	}
+	return e
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/hunterheston/airbnb/pkg/store"
+)
+
+// runDB manages the database: "db migrate" applies pending schema
+// migrations and "db vacuum" compacts the file.
+func runDB(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return errors.New("db: missing subcommand (migrate or vacuum)")
+	}
+	sub, args := args[0], args[1:]
+	switch sub {
+	case "migrate":
+		return runDBMigrate(args)
+	case "vacuum":
+		return runDBVacuum(args)
+	default:
+		return fmt.Errorf("db: unknown subcommand %q (want migrate or vacuum)", sub)
+	}
+}
+
+func runDBMigrate(args []string) error {
+	fs, configPath := flagSet("db migrate")
+	cfg, err := parse(fs, configPath, args)
+	if err != nil {
+		return err
+	}
+
+	db, err := store.OpenUnmigrated(cfg.Database)
+	if err != nil {
+		return fmt.Errorf("opening store: %w", err)
+	}
+	defer db.Close()
+
+	from, to, err := db.Migrate()
+	if err != nil {
+		return err
+	}
+	if from == to {
+		fmt.Printf("%s is up to date (schema version %d)\n", cfg.Database, to)
+	} else {
+		fmt.Printf("%s migrated from schema version %d to %d\n", cfg.Database, from, to)
+	}
+	return nil
+}
+
+func runDBVacuum(args []string) error {
+	fs, configPath := flagSet("db vacuum")
+	cfg, err := parse(fs, configPath, args)
+	if err != nil {
+		return err
+	}
+
+	db, err := store.Open(cfg.Database)
+	if err != nil {
+		return fmt.Errorf("opening store: %w", err)
+	}
+	defer db.Close()
+
+	before, err := fileSize(cfg.Database)
+	if err != nil {
+		return err
+	}
+	if err := db.Vacuum(); err != nil {
+		return fmt.Errorf("vacuuming: %w", err)
+	}
+	after, err := fileSize(cfg.Database)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s vacuumed: %d to %d bytes\n", cfg.Database, before, after)
+	return nil
+}
+
+func fileSize(path string) (int64, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return 0, err
+	}
+	return fi.Size(), nil
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hunterheston/airbnb/pkg/scraper"
+)
+
+// runDoctor checks every source against the live site and prints what
+// passed and what didn't, so stale selectors show up before a run comes
+// back empty.
+func runDoctor(ctx context.Context, args []string) error {
+	fs, configPath := flagSet("doctor")
+	cfg, err := parse(fs, configPath, args)
+	if err != nil {
+		return err
+	}
+	sources, err := cfg.NewSources(nil)
+	if err != nil {
+		return fmt.Errorf("configuring sources: %w", err)
+	}
+
+	ctx, cancel := runContext(ctx, cfg)
+	defer cancel()
+	failed := 0
+	for _, src := range sources {
+		checks := scraper.Diagnose(ctx, src)
+		status := "PASS"
+		if scraper.Failed(checks) {
+			status = "FAIL"
+			failed++
+		}
+		fmt.Printf("%s  %s\n", status, src.Name())
+		for _, c := range checks {
+			mark := "ok"
+			switch {
+			case c.Err != nil && c.Optional:
+				mark = "warn"
+			case c.Err != nil:
+				mark = "FAIL"
+			}
+			line := fmt.Sprintf("  %-4s  %s", mark, c.Name)
+			if c.Note != "" && c.Err == nil {
+				line += " (" + c.Note + ")"
+			}
+			if c.Err != nil {
+				line += ": " + c.Err.Error()
+			}
+			fmt.Println(line)
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d sources failed their checks", failed, len(sources))
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/hunterheston/airbnb/pkg/config"
+	"github.com/hunterheston/airbnb/pkg/filter"
+	"github.com/hunterheston/airbnb/pkg/notify"
+	"github.com/hunterheston/airbnb/pkg/scraper"
+	"github.com/hunterheston/airbnb/pkg/store"
+)
+
+// dryRunFlags adds -dry-run and -dry-run-out to fs.
+func dryRunFlags(fs *flag.FlagSet) (enabled *bool, out *string) {
+	enabled = fs.Bool("dry-run", false, "write the email that would be sent instead of sending anything, and leave the database untouched")
+	out = fs.String("dry-run-out", "", "with -dry-run, write the email to this file instead of standard output")
+	return enabled, out
+}
+
+// dryRun rewires cfg so that nothing leaves the machine: the email is
+// written to out (standard output if empty) instead of being sent, the
+// other channels, including those of subscriptions and pushes, and the
+// heartbeat are dropped, and the database is replaced by a temporary copy. The returned cleanup
+// removes the copy and closes out.
+func dryRun(cfg *config.Config, out string) (cleanup func(), err error) {
+	var w io.WriteCloser = nopCloser{os.Stdout}
+	if out != "" {
+		f, err := os.Create(out)
+		if err != nil {
+			return nil, err
+		}
+		w = f
+	}
+
+	dir, err := os.MkdirTemp("", "jobwatch-dry-run-")
+	if err != nil {
+		w.Close()
+		return nil, err
+	}
+	cleanup = func() {
+		os.RemoveAll(dir)
+		w.Close()
+	}
+
+	snapshot := filepath.Join(dir, "jobs.db")
+	if err := copyStore(cfg.Database, snapshot); err != nil {
+		cleanup()
+		return nil, fmt.Errorf("copying database: %w", err)
+	}
+	cfg.Database = snapshot
+
+	cfg.Email.Transport = &notify.WriterTransport{W: w}
+	// Routes that email with the digest are kept to their email too.
+	var routes []config.Route
+	for _, r := range cfg.Routes {
+		if !r.Push && slices.Contains(r.Notifiers, "email") {
+			r.Notifiers = []string{"email"}
+			routes = append(routes, r)
+		}
+	}
+	cfg.Routes = routes
+	if len(cfg.Subscriptions) == 0 && len(routes) == 0 || slices.Contains(cfg.Notifiers, "email") {
+		cfg.Notifiers = []string{"email"}
+	} else {
+		cfg.Notifiers = nil
+	}
+	channelFilters := map[string]filter.Config{}
+	if fc, ok := cfg.ChannelFilters["email"]; ok {
+		channelFilters["email"] = fc
+	}
+	cfg.ChannelFilters = channelFilters
+
+	// Subscriptions only keep their email, printed like the main one.
+	var subs []config.Subscription
+	for _, sub := range cfg.Subscriptions {
+		if slices.Contains(sub.Notifiers, "email") {
+			sub.Notifiers = []string{"email"}
+			subs = append(subs, sub)
+		}
+	}
+	cfg.Subscriptions = subs
+	cfg.Push.Notifiers = nil
+	// Reminders go to the remaining notifiers.
+	cfg.Reminders.Notifiers = nil
+	cfg.Anomalies.Notifiers = nil
+	cfg.Outbox.Enabled = false
+	cfg.Heartbeat.URL = ""
+	return cleanup, nil
+}
+
+// replayRun rewires cfg to parse the pages archived in dir: nothing is
+// fetched, cached or archived again, no alerts or pushes go out, and the
+// database is replaced by a temporary copy. The returned cleanup removes the
+// copy.
+func replayRun(cfg *config.Config, dir string) (cleanup func(), err error) {
+	if _, err := os.Stat(filepath.Join(dir, scraper.ArchiveIndex)); err != nil {
+		return nil, fmt.Errorf("%s is not a page archive: %w", dir, err)
+	}
+	tmp, err := os.MkdirTemp("", "jobwatch-replay-")
+	if err != nil {
+		return nil, err
+	}
+	cleanup = func() { os.RemoveAll(tmp) }
+
+	snapshot := filepath.Join(tmp, "jobs.db")
+	if err := copyStore(cfg.Database, snapshot); err != nil {
+		cleanup()
+		return nil, fmt.Errorf("copying database: %w", err)
+	}
+	cfg.Database = snapshot
+	cfg.Replay = dir
+	cfg.Cache = ""
+	cfg.Archive = ""
+	cfg.Notifiers = nil
+	cfg.ChannelFilters = nil
+	cfg.Subscriptions = nil
+	cfg.Routes = nil
+	cfg.Push.Notifiers = nil
+	cfg.Anomalies.Notifiers = nil
+	return cleanup, nil
+}
+
+// copyStore copies the database at src to dst. A missing src is left
+// missing, so the dry run starts from an empty database.
+func copyStore(src, dst string) error {
+	if _, err := os.Stat(src); errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	db, err := store.Open(src)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	return db.CopyTo(dst)
+}
+
+type nopCloser struct{ io.Writer }
+
+func (nopCloser) Close() error { return nil }
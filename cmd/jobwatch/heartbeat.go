@@ -0,0 +1,27 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/hunterheston/airbnb/pkg/config"
+)
+
+// heartbeat tells the configured heartbeat how a run that ended with err
+// went. A run stopped because ctx was cancelled, by a signal or a shutdown,
+// isn't reported either way. Failing to ping is only logged.
+func heartbeat(ctx context.Context, cfg *config.Config, err error) {
+	p := cfg.Pinger()
+	if p == nil || ctx.Err() != nil {
+		return
+	}
+	var pingErr error
+	if err != nil {
+		pingErr = p.Fail(ctx, err)
+	} else {
+		pingErr = p.Success(ctx)
+	}
+	if pingErr != nil {
+		slog.Warn("heartbeat failed", "err", pingErr)
+	}
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hunterheston/airbnb/pkg/config"
+	"github.com/hunterheston/airbnb/pkg/inbox"
+	"github.com/hunterheston/airbnb/pkg/store"
+)
+
+// runInbox carries out the commands in unread replies to the digest, for
+// running from cron when "serve" doesn't check the inbox itself.
+func runInbox(ctx context.Context, args []string) error {
+	fs, configPath := flagSet("inbox")
+	cfg, err := parse(fs, configPath, args)
+	if err != nil {
+		return err
+	}
+	if cfg.Inbox.Host == "" {
+		return errors.New("inbox: no IMAP host configured")
+	}
+	ctx, cancel := runContext(ctx, cfg)
+	defer cancel()
+	return checkInbox(ctx, cfg)
+}
+
+// checkInbox reads the replies to the digest and marks the jobs they name.
+// A command that fails, e.g. on a job that isn't stored, is logged and
+// skipped: the reply is read either way, so it isn't retried forever.
+func checkInbox(ctx context.Context, cfg *config.Config) error {
+	db, err := store.Open(cfg.Database)
+	if err != nil {
+		return fmt.Errorf("opening store: %w", err)
+	}
+	defer db.Close()
+
+	return cfg.ReplyInbox().Check(ctx, func(r inbox.Reply) {
+		if len(r.Commands) == 0 {
+			slog.Info("reply has no commands", "from", r.From, "subject", r.Subject)
+			return
+		}
+		now := time.Now()
+		for _, c := range r.Commands {
+			for _, arg := range c.Jobs {
+				job, err := replyJob(db, arg)
+				if err == nil {
+					err = obey(db, c.Verb, job.ID, now)
+				}
+				if err != nil {
+					slog.Warn("reply command failed", "from", r.From, "command", c.Verb, "job", arg, "err", err)
+					continue
+				}
+				slog.Info("reply command", "from", r.From, "command", c.Verb, "id", job.ID, "title", job.Title)
+			}
+		}
+	})
+}
+
+// replyJob finds the job a reply names: by its posting URL, by the ID the
+// site gives it in that URL, which is what reads naturally in the digest,
+// or failing that by the ID "list" prints.
+func replyJob(db *store.Store, arg string) (store.Job, error) {
+	if strings.Contains(arg, "/") {
+		return db.Lookup(arg)
+	}
+	job, err := db.ByPostingID(arg)
+	if !errors.Is(err, store.ErrNotFound) {
+		return job, err
+	}
+	id, perr := strconv.ParseInt(arg, 10, 64)
+	if perr != nil {
+		return store.Job{}, err
+	}
+	return db.Get(id)
+}
+
+// obey carries out a reply command on the job with the given ID.
+func obey(db *store.Store, verb string, id int64, now time.Time) error {
+	switch verb {
+	case inbox.Mute:
+		return db.Mute(id, false, now)
+	case inbox.Unmute:
+		return db.Unmute(id)
+	case inbox.Interested:
+		return db.SetInterested(id, true, now)
+	case inbox.Up:
+		return db.SetFeedback(id, store.FeedbackUp)
+	case inbox.Down:
+		return db.SetFeedback(id, store.FeedbackDown)
+	default:
+		// The rest are application states.
+		return db.SetApplication(id, verb, now)
+	}
+}
+
+// pollInbox checks the inbox every cfg.Inbox.Interval until ctx is
+// cancelled. A check in progress gets work, so that it finishes first.
+func pollInbox(ctx, work context.Context, cfg *config.Config) {
+	ticker := time.NewTicker(cfg.Inbox.Interval)
+	defer ticker.Stop()
+	for {
+		checkCtx, cancel := runContext(work, cfg)
+		if err := checkInbox(checkCtx, cfg); err != nil {
+			slog.Error("checking the inbox", "err", err)
+		}
+		cancel()
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
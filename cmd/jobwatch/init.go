@@ -0,0 +1,383 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/x/term"
+	"gopkg.in/yaml.v3"
+
+	"github.com/hunterheston/airbnb/pkg/config"
+	"github.com/hunterheston/airbnb/pkg/filter"
+	"github.com/hunterheston/airbnb/pkg/notify"
+	"github.com/hunterheston/airbnb/pkg/schedule"
+	"github.com/hunterheston/airbnb/pkg/scraper"
+	"github.com/hunterheston/airbnb/pkg/secrets"
+)
+
+// The config file init writes: only what it asked about, so that the
+// defaults and config.example.yaml cover the rest.
+type (
+	initFile struct {
+		Database  string          `yaml:"database"`
+		Schedule  string          `yaml:"schedule"`
+		Sources   []initSource    `yaml:"sources"`
+		Filter    initFilter      `yaml:"filter"`
+		Notifiers []string        `yaml:"notifiers,flow"`
+		Email     *initEmail      `yaml:"email,omitempty"`
+		Slack     *initWebhookURL `yaml:"slack,omitempty"`
+		Discord   *initWebhookURL `yaml:"discord,omitempty"`
+		Webhook   *initWebhook    `yaml:"webhook,omitempty"`
+	}
+	initSource struct {
+		Name        string   `yaml:"name,omitempty"`
+		Type        string   `yaml:"type"`
+		Board       string   `yaml:"board,omitempty"`
+		URL         string   `yaml:"url,omitempty"`
+		Departments []string `yaml:"departments,omitempty,flow"`
+		Offices     []string `yaml:"offices,omitempty,flow"`
+	}
+	initFilter struct {
+		Include   []string `yaml:"include,omitempty,flow"`
+		Exclude   []string `yaml:"exclude,omitempty,flow"`
+		Levels    []string `yaml:"levels,omitempty,flow"`
+		Locations []string `yaml:"locations,omitempty,flow"`
+	}
+	initEmail struct {
+		Host     string   `yaml:"host"`
+		Port     string   `yaml:"port,omitempty"`
+		From     string   `yaml:"from"`
+		Password string   `yaml:"password"`
+		To       []string `yaml:"to,flow"`
+	}
+	initWebhookURL struct {
+		WebhookURL string `yaml:"webhook_url"`
+	}
+	initWebhook struct {
+		URLs   []string `yaml:"urls"`
+		Secret string   `yaml:"secret,omitempty"`
+	}
+)
+
+// sourceTypes are the source types init offers, which need no more than a
+// board or URL; the others are set up by editing the file.
+var sourceTypes = []string{"greenhouse", "lever", "ashby", "smartrecruiters", "recruitee", "workday", "feed", "airbnb"}
+
+// runInit asks for the sources, filter, schedule and notification channel,
+// checks the channel with a test message and writes the config file.
+func runInit(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("jobwatch init", flag.ExitOnError)
+	configPath := fs.String("config", "config.yaml", "path of the config file to write")
+	force := fs.Bool("force", false, "overwrite the config file if it exists")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments: %v", fs.Args())
+	}
+	if _, err := os.Stat(*configPath); err == nil && !*force {
+		return fmt.Errorf("%s already exists; pass -force to replace it", *configPath)
+	}
+
+	p := &prompter{in: bufio.NewReader(os.Stdin), out: os.Stderr}
+	fmt.Fprintf(p.out, "This writes %s. Press enter to take the [default], or - to leave a list empty.\n", *configPath)
+	file := initFile{Database: "jobs.db"}
+
+	p.section("Sources")
+	for {
+		src, err := p.source()
+		if err != nil {
+			return err
+		}
+		file.Sources = append(file.Sources, src)
+		if !p.yes("Add another source?", false) {
+			break
+		}
+	}
+
+	p.section("Filter")
+	for {
+		def := filter.MidLevelSoftwareEngineer()
+		f := initFilter{
+			Include:   p.list("Titles must contain (comma-separated)", def.Include),
+			Exclude:   p.list("Titles must not contain", def.Exclude),
+			Levels:    p.list("Seniority levels (intern, junior, mid, senior, staff, manager; blank for any)", def.Levels),
+			Locations: p.list("Locations (blank for anywhere)", nil),
+		}
+		fc := filter.Config{Include: f.Include, Exclude: f.Exclude, Levels: f.Levels, Locations: f.Locations}
+		if _, err := fc.Build(); err != nil {
+			if err := p.retry(err); err != nil {
+				return err
+			}
+			continue
+		}
+		file.Filter = f
+		break
+	}
+
+	p.section("Schedule")
+	for {
+		file.Schedule = p.ask(`When "jobwatch serve" runs, as a cron expression`, "0 9 * * *")
+		if _, err := schedule.Parse(file.Schedule); err != nil {
+			if err := p.retry(err); err != nil {
+				return err
+			}
+			continue
+		}
+		break
+	}
+
+	p.section("Notifications")
+	for {
+		if err := p.channel(ctx, &file); err != nil {
+			p.fail(err)
+			if !p.eof && p.yes("Try again?", true) {
+				continue
+			}
+			if !p.yes("Write the config anyway?", false) {
+				return errors.New("init: nothing written")
+			}
+		}
+		break
+	}
+	keyring := false
+	if e := file.Email; e != nil && p.yes("Keep the password in the OS keyring rather than the file?", true) {
+		err := (&secrets.Keyring{Service: secrets.KeyringService}).Set(ctx, "SMTP_PASSWORD", e.Password)
+		if err != nil {
+			p.fail(fmt.Errorf("%w; it goes in the file instead", err))
+		} else {
+			keyring = true
+		}
+	}
+
+	// Load expands ${...} in values and reads $$ as $, so the $s of the
+	// answers are doubled.
+	var doc yaml.Node
+	if err := doc.Encode(file); err != nil {
+		return err
+	}
+	escapeValues(&doc)
+	if err := doc.Decode(&file); err != nil {
+		return err
+	}
+	if keyring {
+		file.Email.Password = "${keyring:SMTP_PASSWORD}"
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "# Written by \"jobwatch init\" on %s. Every other setting is\n", time.Now().Format("2006-01-02"))
+	fmt.Fprintf(&buf, "# described in config.example.yaml.\n")
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(file); err != nil {
+		return err
+	}
+	// It may hold a password.
+	if err := os.WriteFile(*configPath, buf.Bytes(), 0o600); err != nil {
+		return err
+	}
+	if _, err := config.Load(*configPath); err != nil {
+		return fmt.Errorf("wrote %s, but it doesn't load: %w", *configPath, err)
+	}
+	fmt.Fprintf(p.out, "\nWrote %s. Try \"jobwatch run -config %s\", then schedule it or run \"jobwatch serve\".\n", *configPath, *configPath)
+	return nil
+}
+
+// escapeValues writes each $ in n's strings as $$.
+func escapeValues(n *yaml.Node) {
+	if n.Kind == yaml.ScalarNode && n.Tag == "!!str" {
+		n.Value = strings.ReplaceAll(n.Value, "$", "$$")
+	}
+	for _, c := range n.Content {
+		escapeValues(c)
+	}
+}
+
+// source asks for one source until it is one that can be built.
+func (p *prompter) source() (initSource, error) {
+	for {
+		var src initSource
+		src.Type = p.ask("Type ("+strings.Join(sourceTypes, ", ")+")", "greenhouse")
+		switch src.Type {
+		case "greenhouse", "lever", "ashby", "smartrecruiters", "recruitee":
+			src.Name = p.ask("Company name, as the digest shows it", "")
+			src.Board = p.ask("Board (the last part of the job board's URL, e.g. airbnb in boards.greenhouse.io/airbnb)", strings.ToLower(src.Name))
+			src.Departments = p.list("Departments (blank for all)", nil)
+			src.Offices = p.list("Offices (blank for all)", nil)
+		case "workday":
+			src.Name = p.ask("Company name", "")
+			src.URL = p.ask("Career site URL, e.g. https://acme.wd5.myworkdayjobs.com/External", "")
+		case "feed":
+			src.Name = p.ask("Name", "")
+			src.URL = p.ask("RSS or Atom feed URL", "")
+		case "airbnb":
+			src.Departments = p.list("Departments (careers.airbnb.com slugs)", []string{"engineering"})
+			src.Offices = p.list("Offices", []string{"united-states"})
+		default:
+			if err := p.retry(fmt.Errorf("unknown type %q", src.Type)); err != nil {
+				return src, err
+			}
+			continue
+		}
+		sc := scraper.SourceConfig{
+			Name: src.Name, Type: src.Type, Board: src.Board, URL: src.URL,
+			Departments: src.Departments, Offices: src.Offices,
+		}
+		if _, err := scraper.NewSource(sc, scraper.Options{}); err != nil {
+			if err := p.retry(err); err != nil {
+				return src, err
+			}
+			continue
+		}
+		return src, nil
+	}
+}
+
+// channel asks for a notification channel and sends a test message
+// through it, returning the error if that fails.
+func (p *prompter) channel(ctx context.Context, file *initFile) error {
+	cfg := config.Default()
+	name := p.ask("Send the digest by (email, slack, discord, webhook)", "email")
+	file.Email, file.Slack, file.Discord, file.Webhook = nil, nil, nil, nil
+	switch name {
+	case "email":
+		e := &initEmail{
+			From: p.ask("Send from address", cfg.Email.From),
+			Host: p.ask("SMTP server", "smtp.gmail.com"),
+		}
+		if e.Host != "smtp.gmail.com" {
+			e.Port = p.ask("SMTP port (blank to follow from TLS)", "")
+		}
+		password := p.secret("Password (for Gmail, an app password)")
+		e.To = p.list("Send to", []string{e.From})
+		e.Password = password
+		cfg.Email.From, cfg.Email.Host, cfg.Email.Port, cfg.Email.Password, cfg.Email.To = e.From, e.Host, e.Port, password, e.To
+		file.Email = e
+	case "slack", "discord":
+		u := &initWebhookURL{WebhookURL: p.ask("Incoming webhook URL", "")}
+		if name == "slack" {
+			cfg.Slack.WebhookURL, file.Slack = u.WebhookURL, u
+		} else {
+			cfg.Discord.WebhookURL, file.Discord = u.WebhookURL, u
+		}
+	case "webhook":
+		w := &initWebhook{URLs: p.list("URLs to post to", nil), Secret: p.secret("Signing secret (blank for none)")}
+		cfg.Webhook.URLs, cfg.Webhook.Secret, file.Webhook = w.URLs, w.Secret, w
+	default:
+		return fmt.Errorf("unknown channel %q", name)
+	}
+	file.Notifiers = []string{name}
+
+	n, err := cfg.Channel(name)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(p.out, "Sending a test message through %s...\n", name)
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+	if err := notify.Alert(ctx, n, "jobwatch is set up: this is the test message from \"jobwatch init\"."); err != nil {
+		return fmt.Errorf("test message: %w", err)
+	}
+	fmt.Fprintln(p.out, "Sent; check it arrived.")
+	return nil
+}
+
+// prompter asks questions on the terminal.
+type prompter struct {
+	in  *bufio.Reader
+	out io.Writer
+	// eof is set once the input has ended, after which every answer is
+	// the default.
+	eof bool
+}
+
+func (p *prompter) section(name string) {
+	fmt.Fprintf(p.out, "\n%s\n", name)
+}
+
+func (p *prompter) fail(err error) {
+	fmt.Fprintf(p.out, "  %v\n", err)
+}
+
+// retry reports a bad answer before asking again, or returns it if there
+// is no more input to ask with.
+func (p *prompter) retry(err error) error {
+	p.fail(err)
+	if p.eof {
+		return fmt.Errorf("init: %w", err)
+	}
+	return nil
+}
+
+// ask asks question and returns the answer, or def for none.
+func (p *prompter) ask(question, def string) string {
+	if def != "" {
+		fmt.Fprintf(p.out, "%s [%s]: ", question, def)
+	} else {
+		fmt.Fprintf(p.out, "%s: ", question)
+	}
+	line := strings.TrimSpace(p.line())
+	if line == "" {
+		return def
+	}
+	return line
+}
+
+func (p *prompter) line() string {
+	line, err := p.in.ReadString('\n')
+	if err != nil && !p.eof {
+		p.eof = true
+		fmt.Fprintln(p.out)
+	}
+	return line
+}
+
+// list asks for a comma-separated list; "-" clears the default.
+func (p *prompter) list(question string, def []string) []string {
+	answer := p.ask(question, strings.Join(def, ", "))
+	if answer == "-" {
+		return nil
+	}
+	var out []string
+	for _, s := range strings.Split(answer, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func (p *prompter) yes(question string, def bool) bool {
+	hint := "y/N"
+	if def {
+		hint = "Y/n"
+	}
+	fmt.Fprintf(p.out, "%s [%s]: ", question, hint)
+	switch strings.ToLower(strings.TrimSpace(p.line())) {
+	case "y", "yes":
+		return true
+	case "n", "no":
+		return false
+	default:
+		return def
+	}
+}
+
+// secret asks for a value without echoing it, when on a terminal.
+func (p *prompter) secret(question string) string {
+	if !term.IsTerminal(os.Stdin.Fd()) {
+		return p.ask(question, "")
+	}
+	fmt.Fprintf(p.out, "%s: ", question)
+	b, _ := term.ReadPassword(os.Stdin.Fd())
+	fmt.Fprintln(p.out)
+	return strings.TrimSpace(string(b))
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/hunterheston/airbnb/pkg/output"
+	"github.com/hunterheston/airbnb/pkg/store"
+)
+
+func runList(ctx context.Context, args []string) error {
+	fs, configPath := flagSet("list")
+	pending := fs.Bool("pending", false, "only list open jobs that haven't been sent yet")
+	format := outputFlag(fs)
+	cfg, err := parse(fs, configPath, args)
+	if err != nil {
+		return err
+	}
+	f, err := output.ParseFormat(*format)
+	if err != nil {
+		return err
+	}
+
+	db, err := store.Open(cfg.Database)
+	if err != nil {
+		return fmt.Errorf("opening store: %w", err)
+	}
+	defer db.Close()
+
+	jobs, err := db.List()
+	if err != nil {
+		return err
+	}
+	if *pending {
+		var unsent []store.Job
+		for _, j := range jobs {
+			if j.Status() == store.StatusNew && j.MutedAt == nil {
+				unsent = append(unsent, j)
+			}
+		}
+		jobs = unsent
+	}
+	return output.WriteStored(os.Stdout, f, jobs)
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/hunterheston/airbnb/pkg/config"
+	"github.com/hunterheston/airbnb/pkg/lockfile"
+)
+
+// forceFlag adds the -force flag to fs.
+func forceFlag(fs *flag.FlagSet) *bool {
+	return fs.Bool("force", false, "take the database lock even if another run holds it")
+}
+
+// lock takes the lock that keeps two runs from working on cfg's database
+// at once. The returned function releases it.
+func lock(cfg *config.Config, force bool) (release func(), err error) {
+	path := cfg.Database + ".lock"
+	var l *lockfile.Lock
+	if force {
+		l, err = lockfile.Force(path)
+	} else {
+		l, err = lockfile.Acquire(path, cfg.Timeouts.Lock)
+	}
+	var held *lockfile.HeldError
+	if errors.As(err, &held) {
+		return nil, fmt.Errorf("another run is in progress: %w (use -force if it isn't)", err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("taking the lock: %w", err)
+	}
+	return func() { l.Release() }, nil
+}
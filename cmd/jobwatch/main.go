@@ -0,0 +1,179 @@
+// Command jobwatch scrapes careers pages and notifies about new job postings.
+//
+// Usage:
+//
+//	jobwatch <command> [flags]
+//
+// Run "jobwatch help" for the list of commands.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/hunterheston/airbnb/pkg/config"
+	"github.com/hunterheston/airbnb/pkg/errreport"
+	"github.com/hunterheston/airbnb/pkg/trace"
+)
+
+// command is one jobwatch subcommand.
+type command struct {
+	name    string
+	summary string
+	// run receives the arguments after the command name. ctx is cancelled
+	// on SIGINT or SIGTERM.
+	run func(ctx context.Context, args []string) error
+}
+
+var commands []command
+
+func init() {
+	commands = []command{
+		{"init", "set up a config file by answering a few questions", runInit},
+		{"run", "scrape, then send the new jobs (what a cron job wants)", runRun},
+		{"scrape", "fetch every source and store the matching jobs", runScrape},
+		{"backfill", "record every job listed now as already sent, so the first digest isn't all of them", runBackfill},
+		{"send", "notify about stored jobs that haven't been sent yet", runSend},
+		{"list", "print the stored jobs", runList},
+		{"render", "preview a notifier's message or a template for the next digest", runRender},
+		{"search", "find stored jobs, closed ones too, by words in their descriptions", runSearch},
+		{"runs", "list the recent scrapes, or show one with -id", runRuns},
+		{"notify", "check the channels by sending a sample digest through each: \"notify -test\"", runNotify},
+		{"mute", "keep jobs out of future digests: \"mute [-similar] [-undo] ID|URL...\"", runMute},
+		{"track", "set a job's interview and application deadline, e.g. \"track -interview '2026-11-03 14:00' ID\"", runTrack},
+		{"inbox", "carry out the commands in replies to the digest, e.g. \"mute 12345\"", runInbox},
+		{"tui", "browse and triage the stored jobs in the terminal", runTUI},
+		{"doctor", "check each source still parses on the live site", runDoctor},
+		{"report", "send the weekly summary of opened and closed jobs", runReport},
+		{"serve", "stay resident and run on the config's schedule", runServe},
+		{"secrets", "store or encrypt the secrets the config refers to: \"secrets set NAME\", \"secrets encrypt\" or \"secrets keygen\"", runSecrets},
+		{"db", "manage the database: \"db migrate\" or \"db vacuum\"", runDB},
+		{"help", "show this help", runHelp},
+	}
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	// Until the config is loaded, log at the default level.
+	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, nil)))
+
+	name := os.Args[1]
+	for _, cmd := range commands {
+		if cmd.name == name {
+			defer func() {
+				if v := recover(); v != nil {
+					capture(withTags(errreport.Recovered(v, 0), "command", name))
+					panic(v)
+				}
+			}()
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			err := cmd.run(ctx, os.Args[2:])
+			stop()
+			flushTraces()
+			if err != nil {
+				slog.Error("command failed", "command", name, "err", err)
+				capture(withTags(errreport.New(err, 0), "command", name))
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "jobwatch: unknown command %q\n\n", name)
+	usage()
+	os.Exit(2)
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: jobwatch <command> [flags]\n\nCommands:\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-8s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintf(os.Stderr, "\nRun \"jobwatch <command> -h\" for a command's flags.\n")
+}
+
+func runHelp(context.Context, []string) error {
+	usage()
+	return nil
+}
+
+// flagSet returns a FlagSet for the named command with the -config and
+// logging flags every command shares.
+func flagSet(name string) (*flag.FlagSet, *string) {
+	fs := flag.NewFlagSet("jobwatch "+name, flag.ExitOnError)
+	configPath := fs.String("config", "config.yaml", "path to the YAML config file")
+	fs.String("log-level", "", "override the config's log level: debug, info, warn or error")
+	fs.String("log-format", "", "override the config's log format: text or json")
+	return fs, configPath
+}
+
+// outputFlag adds the -output flag to fs.
+func outputFlag(fs *flag.FlagSet) *string {
+	return fs.String("output", "text", "output format: text, json or csv")
+}
+
+// runContext bounds one run by the config's run timeout.
+func runContext(ctx context.Context, cfg *config.Config) (context.Context, context.CancelFunc) {
+	if cfg.Timeouts.Run > 0 {
+		return context.WithTimeout(ctx, cfg.Timeouts.Run)
+	}
+	return context.WithCancel(ctx)
+}
+
+// parse parses args, loads the config file and sets up logging.
+func parse(fs *flag.FlagSet, configPath *string, args []string) (*config.Config, error) {
+	cfg, rest, err := parseArgs(fs, configPath, args)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) > 0 {
+		return nil, fmt.Errorf("unexpected arguments: %v", rest)
+	}
+	return cfg, nil
+}
+
+// parseArgs is parse for commands that take arguments after the flags,
+// which it returns.
+func parseArgs(fs *flag.FlagSet, configPath *string, args []string) (*config.Config, []string, error) {
+	if err := fs.Parse(args); err != nil {
+		return nil, nil, err
+	}
+	cfg, err := load(fs, *configPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cfg, fs.Args(), nil
+}
+
+// load loads the config file at path, applies fs's logging flags to it and
+// sets up logging. fs must have been parsed.
+func load(fs *flag.FlagSet, path string) (*config.Config, error) {
+	cfg, err := config.Load(path)
+	if err != nil {
+		return nil, fmt.Errorf("loading config: %w", err)
+	}
+
+	if v := fs.Lookup("log-level").Value.String(); v != "" {
+		cfg.Log.Level = v
+	}
+	if v := fs.Lookup("log-format").Value.String(); v != "" {
+		cfg.Log.Format = v
+	}
+	h, err := cfg.Log.Handler(os.Stderr)
+	if err != nil {
+		return nil, err
+	}
+	slog.SetDefault(slog.New(h))
+	reporter = cfg.Reporter()
+	trace.SetDefault(cfg.Tracer())
+	return cfg, nil
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/hunterheston/airbnb/pkg/store"
+)
+
+// runMute marks jobs as not interesting so that no later digest lists
+// them, however well they match the filters. Jobs are given by their ID,
+// as "list" prints it, or their posting URL.
+func runMute(ctx context.Context, args []string) error {
+	fs, configPath := flagSet("mute")
+	similar := fs.Bool("similar", false, "also mute the company's jobs with the same title in other locations, future ones included")
+	undo := fs.Bool("undo", false, "unmute the jobs instead, and their titles if those were muted")
+	cfg, jobs, err := parseArgs(fs, configPath, args)
+	if err != nil {
+		return err
+	}
+	if len(jobs) == 0 {
+		return errors.New("mute: no jobs given")
+	}
+
+	db, err := store.Open(cfg.Database)
+	if err != nil {
+		return fmt.Errorf("opening store: %w", err)
+	}
+	defer db.Close()
+
+	now := time.Now()
+	for _, arg := range jobs {
+		job, err := lookup(db, arg)
+		if err != nil {
+			return fmt.Errorf("%s: %w", arg, err)
+		}
+		if *undo {
+			err = db.Unmute(job.ID)
+		} else {
+			err = db.Mute(job.ID, *similar, now)
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", arg, err)
+		}
+		verb := "muted"
+		if *undo {
+			verb = "unmuted"
+		}
+		fmt.Printf("%s %d: %s at %s\n", verb, job.ID, job.Title, job.Company)
+	}
+	return nil
+}
+
+// lookup finds a job by its ID or its posting URL.
+func lookup(db *store.Store, arg string) (store.Job, error) {
+	if id, err := strconv.ParseInt(arg, 10, 64); err == nil {
+		return db.Get(id)
+	}
+	return db.Lookup(arg)
+}
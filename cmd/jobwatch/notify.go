@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hunterheston/airbnb/pkg/config"
+)
+
+// runNotify sends a digest of made-up jobs through every configured
+// channel and prints which delivered it, so that credentials can be
+// checked before a real digest depends on them.
+func runNotify(ctx context.Context, args []string) error {
+	fs, configPath := flagSet("notify")
+	test := fs.Bool("test", false, "send a sample digest through every channel")
+	only := fs.String("channel", "", "test only this channel, e.g. slack or alice/email")
+	cfg, err := parse(fs, configPath, args)
+	if err != nil {
+		return err
+	}
+	if !*test {
+		return errors.New("notify: pass -test to send a sample digest through every channel")
+	}
+
+	var channels []config.NamedChannel
+	for _, ch := range cfg.Channels() {
+		if *only == "" || ch.Name == *only {
+			channels = append(channels, ch)
+		}
+	}
+	if len(channels) == 0 {
+		if *only != "" {
+			return fmt.Errorf("notify: no channel named %q is configured", *only)
+		}
+		return errors.New("notify: no channels are configured")
+	}
+
+	ctx, cancel := runContext(ctx, cfg)
+	defer cancel()
+	d := sampleDigest(time.Now())
+	d.Partial = nil
+	for i := range d.New {
+		d.New[i].Title = "[test] " + d.New[i].Title
+	}
+	for i := range d.Closed {
+		d.Closed[i].Title = "[test] " + d.Closed[i].Title
+	}
+
+	failed := 0
+	for _, ch := range channels {
+		err := ch.Err
+		if err == nil {
+			err = ch.Notifier.Notify(ctx, d)
+		}
+		if err != nil {
+			failed++
+			fmt.Printf("FAIL  %s: %v\n", ch.Name, err)
+			continue
+		}
+		fmt.Printf("ok    %s\n", ch.Name)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d channels failed", failed, len(channels))
+	}
+	return nil
+}
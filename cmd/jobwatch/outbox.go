@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/hunterheston/airbnb/pkg/config"
+	"github.com/hunterheston/airbnb/pkg/notify"
+	"github.com/hunterheston/airbnb/pkg/store"
+)
+
+// outbox implements notify.Outbox on the database.
+type outbox struct {
+	db     *store.Store
+	policy config.Outbox
+}
+
+// Queue implements notify.Outbox.
+func (o outbox) Queue(e *notify.Email, sendErr error) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	return o.db.Enqueue(body, sendErr.Error(), now, now.Add(o.policy.Delay(1)))
+}
+
+// useOutbox makes undelivered emails wait in db's outbox, if the config
+// enables it.
+func useOutbox(cfg *config.Config, db *store.Store) {
+	cfg.Outbox.Queue = outbox{db: db, policy: cfg.Outbox}
+}
+
+// flushOutbox retries the queued emails that are due, oldest first.
+func flushOutbox(ctx context.Context, cfg *config.Config, db *store.Store) error {
+	if !cfg.Outbox.Enabled {
+		return nil
+	}
+	now := time.Now()
+	due, err := db.Due(now)
+	if err != nil {
+		return fmt.Errorf("loading the outbox: %w", err)
+	}
+	if len(due) == 0 {
+		return nil
+	}
+	email, err := cfg.EmailNotifier()
+	if err != nil {
+		return fmt.Errorf("configuring email: %w", err)
+	}
+
+	for _, m := range due {
+		if ctx.Err() != nil {
+			break
+		}
+		var e notify.Email
+		if err := json.Unmarshal(m.Body, &e); err != nil {
+			return fmt.Errorf("reading queued email %d: %w", m.ID, err)
+		}
+		if err := email.Deliver(ctx, &e); err != nil {
+			attempts := m.Attempts + 1
+			next := time.Now().Add(cfg.Outbox.Delay(attempts))
+			slog.Warn("queued email still not delivered", "subject", e.Subject, "attempts", attempts, "next", next, "err", err)
+			if err := db.Failed(m.ID, err.Error(), next); err != nil {
+				return err
+			}
+			continue
+		}
+		slog.Info("queued email delivered", "subject", e.Subject, "queued_at", m.QueuedAt)
+		if err := db.Delivered(m.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// alertStuck tells the outbox's notifiers about each queued email that has
+// failed outbox.alert_after times, once per email.
+func alertStuck(ctx context.Context, cfg *config.Config, db *store.Store) {
+	if !cfg.Outbox.Enabled {
+		return
+	}
+	notifier, err := cfg.OutboxNotifier()
+	if err != nil {
+		slog.Error("configuring outbox notifiers", "err", err)
+		return
+	}
+	if notifier == nil {
+		return
+	}
+	queued, err := db.Outbox()
+	if err != nil {
+		slog.Error("loading the outbox", "err", err)
+		return
+	}
+	for _, m := range queued {
+		if m.AlertedAt != nil || m.Attempts < cfg.Outbox.AlertAfter {
+			continue
+		}
+		var e notify.Email
+		json.Unmarshal(m.Body, &e)
+		msg := fmt.Sprintf("The email %q, queued %s, has failed to send %d times. Last error: %s. It stays queued and will be retried.",
+			e.Subject, m.QueuedAt.Format(time.DateTime), m.Attempts, m.LastError)
+		if err := notify.Alert(ctx, notifier, msg); err != nil {
+			slog.Error("alerting about a stuck email", "subject", e.Subject, "err", err)
+			continue
+		}
+		if err := db.MarkAlerted(m.ID, time.Now()); err != nil {
+			slog.Error("recording the alert", "err", err)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/hunterheston/airbnb/pkg/config"
+	"github.com/hunterheston/airbnb/pkg/notify"
+	"github.com/hunterheston/airbnb/pkg/store"
+)
+
+// remind tells the reminders' notifiers, in one message, about every
+// application that has waited on the employer for reminders.follow_up.
+// Each application is reminded of once per stage.
+func remind(ctx context.Context, cfg *config.Config, db *store.Store) {
+	if cfg.Reminders.FollowUp <= 0 {
+		return
+	}
+	now := time.Now()
+	due, err := db.FollowUps(now.Add(-cfg.Reminders.FollowUp))
+	if err != nil {
+		slog.Error("loading follow-ups", "err", err)
+		return
+	}
+	if len(due) == 0 {
+		return
+	}
+	notifier, err := cfg.RemindersNotifier()
+	if err != nil {
+		slog.Error("configuring reminder notifiers", "err", err)
+		return
+	}
+
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "Time to follow up on %d applications:\n", len(due))
+	for _, j := range due {
+		days := int(now.Sub(*j.ApplicationAt).Hours() / 24)
+		fmt.Fprintf(&msg, "\n- %s at %s: %s on %s, %d days ago. %s",
+			j.Title, j.Company, strings.ReplaceAll(j.Application, "_", " "),
+			j.ApplicationAt.Local().Format("2006-01-02"), days, j.URL)
+	}
+	if err := notify.Alert(ctx, notifier, msg.String()); err != nil {
+		slog.Error("sending follow-up reminders", "err", err)
+		return
+	}
+	if err := db.MarkReminded(due, now); err != nil {
+		slog.Error("recording the reminders", "err", err)
+	}
+	slog.Info("sent follow-up reminders", "applications", len(due))
+}
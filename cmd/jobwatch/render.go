@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/hunterheston/airbnb/pkg/config"
+	"github.com/hunterheston/airbnb/pkg/notify"
+	"github.com/hunterheston/airbnb/pkg/scraper"
+	"github.com/hunterheston/airbnb/pkg/store"
+)
+
+// runRender prints what a notifier would send for the next digest, or what
+// a template file makes of it, without sending anything or marking jobs as
+// sent.
+func runRender(ctx context.Context, args []string) error {
+	fs, configPath := flagSet("render")
+	name := fs.String("notifier", "", "the notifier to preview; the first in notifiers by default")
+	html := fs.Bool("html", false, "print the email's HTML body rather than its text")
+	tmpl := fs.String("template", "", "render this template file instead of a notifier's; .html files are parsed as HTML")
+	sample := fs.Bool("sample", false, "render made-up jobs instead of the database's pending ones")
+	cfg, err := parse(fs, configPath, args)
+	if err != nil {
+		return err
+	}
+
+	var d notify.Digest
+	if *sample {
+		d = sampleDigest(time.Now())
+	} else {
+		db, err := store.Open(cfg.Database)
+		if err != nil {
+			return fmt.Errorf("opening store: %w", err)
+		}
+		defer db.Close()
+		if d, _, _, err = nextDigest(cfg, db); err != nil {
+			return err
+		}
+	}
+
+	out, err := render(cfg, d, *name, *tmpl, *html)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprint(os.Stdout, out)
+	return err
+}
+
+// render renders d with the template at path if it is set, and otherwise
+// as the named notifier would.
+func render(cfg *config.Config, d notify.Digest, name, path string, html bool) (string, error) {
+	if path != "" {
+		return notify.RenderTemplate(path, notify.NewTemplateData(d, cfg.Links()))
+	}
+	if name == "" {
+		if len(cfg.Notifiers) == 0 {
+			return "", errors.New("no notifiers are configured; pick one with -notifier")
+		}
+		name = cfg.Notifiers[0]
+	}
+	if html {
+		if name != "email" {
+			return "", errors.New("-html only applies to the email notifier")
+		}
+		email, err := cfg.EmailNotifier()
+		if err != nil {
+			return "", err
+		}
+		return email.RenderHTML(d)
+	}
+	n, err := cfg.Channel(name)
+	if err != nil {
+		return "", err
+	}
+	r, ok := n.(notify.Renderer)
+	if !ok {
+		return "", fmt.Errorf("the %s notifier can't be previewed", name)
+	}
+	return r.Render(d)
+}
+
+// sampleDigest is a digest of made-up jobs, for previewing templates
+// before there are real ones.
+func sampleDigest(now time.Time) notify.Digest {
+	posted := now.Add(-26 * time.Hour)
+	return notify.Digest{
+		New: []scraper.JobPosting{
+			{
+				Company: "Airbnb", Title: "Software Engineer, Payments", URL: "https://careers.airbnb.com/positions/1234567",
+				Location: "Remote, US", Team: "Engineering", Level: "mid", SalaryMin: 150000, SalaryMax: 190000,
+				PostedAt: &posted, Score: 8, Fit: 81, Tags: []string{"Go", "Kafka", "PostgreSQL"},
+				Description: "Build the systems that move money between guests and hosts in 190 countries.",
+				Summary: &scraper.Summary{
+					Text:  "Builds the payment systems that move money between guests and hosts. Asks for backend experience with distributed systems.",
+					Level: "mid", Remote: "remote",
+				},
+			},
+			{
+				Company: "Example Co", Title: "Backend Engineer", URL: "https://example.com/careers/42",
+				Location: "Austin, TX", Team: "Platform", Level: "mid", Tags: []string{"Go", "PostgreSQL"},
+				Description: "Own the APIs behind our mobile apps, in Go and Postgres.",
+			},
+		},
+		Closed: []scraper.JobPosting{
+			{Company: "Airbnb", Title: "Software Engineer, Search", URL: "https://careers.airbnb.com/positions/7654321", Location: "San Francisco, CA"},
+		},
+		Partial: []string{"Example Co: fetching page 2: HTTP 503"},
+		Stats:   notify.Stats{Runs: 4, Since: now.Add(-24 * time.Hour), Pages: 38, Found: 212, Matched: 9},
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/hunterheston/airbnb/pkg/config"
+	"github.com/hunterheston/airbnb/pkg/notify"
+	"github.com/hunterheston/airbnb/pkg/store"
+)
+
+// reportPeriod is how far back the weekly report looks.
+const reportPeriod = 7 * 24 * time.Hour
+
+func runReport(ctx context.Context, args []string) error {
+	fs, configPath := flagSet("report")
+	dry, dryOut := dryRunFlags(fs)
+	cfg, err := parse(fs, configPath, args)
+	if err != nil {
+		return err
+	}
+	if *dry {
+		cleanup, err := dryRun(cfg, *dryOut)
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+	}
+	return reportOnce(ctx, cfg)
+}
+
+// reportOnce sends the weekly report for the week up to now.
+func reportOnce(ctx context.Context, cfg *config.Config) error {
+	ctx, cancel := runContext(ctx, cfg)
+	defer cancel()
+
+	db, err := store.Open(cfg.Database)
+	if err != nil {
+		return fmt.Errorf("opening store: %w", err)
+	}
+	defer db.Close()
+
+	now := time.Now()
+	r, err := weeklyReport(db, now.Add(-reportPeriod), now)
+	if err != nil {
+		return err
+	}
+
+	useOutbox(cfg, db)
+	notifier, err := cfg.Notifier()
+	if err != nil {
+		return fmt.Errorf("configuring notifiers: %w", err)
+	}
+	slog.Info("sending weekly report", "opened", len(r.Opened), "closed", len(r.Closed), "open", len(r.Open))
+	if err := notify.SendReport(ctx, notifier, r); err != nil {
+		return fmt.Errorf("sending report: %w", err)
+	}
+	return nil
+}
+
+// weeklyReport summarizes the stored jobs and runs between start and end.
+func weeklyReport(db *store.Store, start, end time.Time) (notify.Report, error) {
+	r := notify.Report{Start: start, End: end}
+
+	jobs, err := db.List()
+	if err != nil {
+		return r, fmt.Errorf("loading jobs: %w", err)
+	}
+	within := func(t time.Time) bool { return !t.Before(start) && t.Before(end) }
+	for _, j := range jobs {
+		rj := notify.ReportJob{JobPosting: j.JobPosting, FirstSeen: j.FirstSeen}
+		if j.ClosedAt != nil {
+			rj.ClosedAt = *j.ClosedAt
+		}
+		if within(j.FirstSeen) {
+			r.Opened = append(r.Opened, rj)
+		}
+		switch {
+		case j.ClosedAt == nil:
+			r.Open = append(r.Open, rj)
+		case within(*j.ClosedAt):
+			r.Closed = append(r.Closed, rj)
+		}
+	}
+
+	runs, err := db.Runs(0)
+	if err != nil {
+		return r, fmt.Errorf("loading runs: %w", err)
+	}
+	for _, run := range runs {
+		if !within(run.StartedAt) {
+			continue
+		}
+		r.Runs++
+		if run.Error != "" {
+			r.FailedRuns++
+		}
+	}
+	return r, nil
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hunterheston/airbnb/pkg/config"
+	"github.com/hunterheston/airbnb/pkg/store"
+	"github.com/hunterheston/airbnb/pkg/trace"
+)
+
+func runRun(ctx context.Context, args []string) error {
+	fs, configPath := flagSet("run")
+	dry, dryOut := dryRunFlags(fs)
+	force := forceFlag(fs)
+	cfg, err := parse(fs, configPath, args)
+	if err != nil {
+		return err
+	}
+	if *dry {
+		cleanup, err := dryRun(cfg, *dryOut)
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+	}
+	release, err := lock(cfg, *force)
+	if err != nil {
+		return err
+	}
+	defer release()
+	return runOnce(ctx, cfg)
+}
+
+// runOnce scrapes every source once, records the results and notifies
+// about the new jobs, all within the run timeout. The outcome is reported
+// to the heartbeat, if one is configured, and a failure to the alert
+// channels, if they are kept apart from the digest's.
+func runOnce(ctx context.Context, cfg *config.Config) (err error) {
+	outer := ctx
+	defer func() { heartbeat(outer, cfg, err) }()
+	defer func() {
+		if err != nil && len(cfg.Alerts.Notifiers) > 0 && outer.Err() == nil {
+			alert(outer, cfg, fmt.Sprintf("The run failed: %v", err))
+		}
+	}()
+	ctx, cancel := runContext(ctx, cfg)
+	defer cancel()
+	ctx, span := trace.Start(ctx, "run")
+	defer func() {
+		trace.SetError(span, err)
+		span.End()
+	}()
+
+	db, err := store.Open(cfg.Database)
+	if err != nil {
+		return fmt.Errorf("opening store: %w", err)
+	}
+	defer db.Close()
+
+	if _, _, err := scrape(ctx, cfg, db); err != nil {
+		return err
+	}
+	return send(ctx, cfg, db)
+}
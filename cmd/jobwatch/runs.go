@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/hunterheston/airbnb/pkg/output"
+	"github.com/hunterheston/airbnb/pkg/store"
+)
+
+// runRuns lists the most recent scrapes, or shows one in full with -id.
+func runRuns(ctx context.Context, args []string) error {
+	fs, configPath := flagSet("runs")
+	limit := fs.Int("limit", 20, "how many of the most recent runs to list; 0 lists them all")
+	id := fs.Int64("id", 0, "show this run in full, errors included")
+	format := outputFlag(fs)
+	cfg, err := parse(fs, configPath, args)
+	if err != nil {
+		return err
+	}
+	f, err := output.ParseFormat(*format)
+	if err != nil {
+		return err
+	}
+
+	db, err := store.Open(cfg.Database)
+	if err != nil {
+		return fmt.Errorf("opening store: %w", err)
+	}
+	defer db.Close()
+
+	if *id != 0 {
+		r, err := db.Run(*id)
+		if err != nil {
+			return err
+		}
+		return output.WriteRun(os.Stdout, f, r)
+	}
+	runs, err := db.Runs(*limit)
+	if err != nil {
+		return err
+	}
+	return output.WriteRuns(os.Stdout, f, runs)
+}
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/hunterheston/airbnb/pkg/config"
+	"github.com/hunterheston/airbnb/pkg/errreport"
+	"github.com/hunterheston/airbnb/pkg/filter"
+	"github.com/hunterheston/airbnb/pkg/notify"
+	"github.com/hunterheston/airbnb/pkg/output"
+	"github.com/hunterheston/airbnb/pkg/scraper"
+	"github.com/hunterheston/airbnb/pkg/store"
+	"github.com/hunterheston/airbnb/pkg/trace"
+)
+
+func runScrape(ctx context.Context, args []string) error {
+	fs, configPath := flagSet("scrape")
+	format := outputFlag(fs)
+	force := forceFlag(fs)
+	replay := fs.String("replay", "", "parse the pages archived in this directory instead of fetching them, leaving the database untouched")
+	cfg, err := parse(fs, configPath, args)
+	if err != nil {
+		return err
+	}
+	f, err := output.ParseFormat(*format)
+	if err != nil {
+		return err
+	}
+	if *replay != "" {
+		cleanup, err := replayRun(cfg, *replay)
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+	}
+	release, err := lock(cfg, *force)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	db, err := store.Open(cfg.Database)
+	if err != nil {
+		return fmt.Errorf("opening store: %w", err)
+	}
+	defer db.Close()
+
+	ctx, cancel := runContext(ctx, cfg)
+	defer cancel()
+	matched, _, err := scrape(ctx, cfg, db)
+	if err != nil {
+		return err
+	}
+	return output.Write(os.Stdout, f, matched)
+}
+
+// scrape fetches every source and records the matching jobs. It returns all
+// matching jobs and the ones among them that are new. Progress is logged.
+// If ctx is done partway, what was scraped is still recorded, and the
+// interruption is returned as an error.
+func scrape(ctx context.Context, cfg *config.Config, db *store.Store) (matched, fresh []scraper.JobPosting, err error) {
+	ctx, span := trace.Start(ctx, "scrape")
+	defer func() {
+		trace.SetError(span, err)
+		span.End()
+	}()
+
+	var stats scraper.Stats
+	sources, err := cfg.NewSources(&stats)
+	if err != nil {
+		return nil, nil, fmt.Errorf("configuring sources: %w", err)
+	}
+
+	now := time.Now()
+	sources, failures, health := skipBroken(cfg, db, sources, now)
+	var jobs []scraper.JobPosting
+	var complete []scraper.Result
+	results := scraper.ScrapeAll(ctx, sources)
+	trackHealth(ctx, cfg, db, results, health, now)
+	for _, res := range results {
+		jobs = append(jobs, res.Jobs...)
+		if res.Err != nil {
+			// Keep going with whatever was scraped so the email still goes out.
+			slog.Warn("source could not be fully scraped", "source", res.Source, "err", res.Err)
+			e := withTags(errreport.New(res.Err, 0), "source", res.Source)
+			e.Extra = map[string]any{"jobs_scraped": len(res.Jobs), "incomplete": res.Incomplete}
+			capture(e)
+			for _, err := range flatten(res.Err) {
+				failures = append(failures, fmt.Errorf("%s: %w", res.Source, err))
+			}
+			alertOnSelectorError(ctx, cfg, res)
+			continue
+		}
+		complete = append(complete, res)
+	}
+
+	filterCtx, filterSpan := trace.Start(ctx, "filter", attribute.Int("jobs_found", len(jobs)))
+	classifier := cfg.Classifier()
+	scorer := cfg.Scorer()
+	tagger := cfg.Tags.Tagger()
+	for i := range jobs {
+		jobs[i].URL = scraper.CanonicalURL(jobs[i].URL)
+		jobs[i].Level = classifier.Classify(jobs[i].Title).String()
+		if jobs[i].SalaryMax == 0 {
+			jobs[i].SalaryMin, jobs[i].SalaryMax = scraper.ParseSalary(jobs[i].Description)
+		}
+		jobs[i].Tags = tagger.Tags(jobs[i])
+		// Scored now too so that filter scripts can use the score.
+		if scorer != nil {
+			jobs[i].Score = scorer.Score(jobs[i])
+		}
+	}
+
+	f, err := cfg.Filter.Build()
+	if err != nil {
+		filterSpan.End()
+		return nil, nil, err
+	}
+	matched = filter.Apply(f, jobs)
+	if matched, err = cfg.Plugins.Filter(filterCtx, matched); err != nil {
+		// The plugin's verdict is lost, not the jobs: they all go out.
+		slog.Warn("filter plugins failed", "err", err)
+		failures = append(failures, err)
+		trace.SetError(filterSpan, err)
+	}
+	filterSpan.SetAttributes(attribute.Int("matched", len(matched)))
+	filterSpan.End()
+	if err := rate(ctx, cfg, db, matched); err != nil {
+		// The jobs are recorded unrated, or keep their earlier fit.
+		slog.Warn("rating jobs against the resume", "err", err)
+		failures = append(failures, err)
+	}
+
+	_, storeSpan := trace.Start(ctx, "store", attribute.Int("matched", len(matched)))
+	fresh, err = db.Record(matched, now)
+	storeSpan.SetAttributes(attribute.Int("new", len(fresh)))
+	trace.SetError(storeSpan, err)
+	storeSpan.End()
+	if err != nil {
+		return nil, nil, fmt.Errorf("recording jobs: %w", err)
+	}
+	slog.Info("scrape finished", "pages", stats.Pages(), "jobs_found", len(jobs), "matched", len(matched), "new", len(fresh))
+	push(ctx, cfg, db, fresh)
+
+	run := store.Run{StartedAt: now, Pages: stats.Pages(), Found: len(jobs), Matched: len(matched), New: len(fresh)}
+	if err := errors.Join(failures...); err != nil {
+		run.Error = err.Error()
+	}
+
+	// Only a complete listing proves that a job is gone. An empty one more
+	// likely means the page changed than that every job closed at once.
+	for _, res := range complete {
+		if len(res.Jobs) == 0 || res.Incomplete {
+			continue
+		}
+		closed, err := db.CloseMissing(res.Source, res.Jobs, now)
+		if err != nil {
+			return nil, nil, fmt.Errorf("closing missing %s jobs: %w", res.Source, err)
+		}
+		if closed > 0 {
+			slog.Info("jobs no longer listed", "source", res.Source, "closed", closed)
+		}
+		run.Closed += closed
+	}
+
+	run.FinishedAt = time.Now()
+	checkAnomaly(ctx, cfg, db, run)
+	if _, err := db.RecordRun(run); err != nil {
+		return nil, nil, fmt.Errorf("recording run: %w", err)
+	}
+	if err := ctx.Err(); err != nil {
+		return matched, fresh, fmt.Errorf("scrape interrupted: %w", err)
+	}
+	return matched, fresh, nil
+}
+
+// rate sets the fit of jobs against the resume, if one is configured.
+func rate(ctx context.Context, cfg *config.Config, db *store.Store, jobs []scraper.JobPosting) error {
+	fitter, err := cfg.Fitter(db)
+	if err != nil || fitter == nil {
+		return err
+	}
+	ctx, span := trace.Start(ctx, "fit", attribute.Int("jobs", len(jobs)))
+	defer span.End()
+	err = fitter.Fit(ctx, jobs)
+	trace.SetError(span, err)
+	return err
+}
+
+// push sends the standout jobs among fresh to the push channels right away.
+// A failed push is only logged: the jobs still go out with the digest.
+func push(ctx context.Context, cfg *config.Config, db *store.Store, fresh []scraper.JobPosting) {
+	notifier, pushes, err := cfg.PushNotifier()
+	if err != nil {
+		slog.Error("configuring push notifiers", "err", err)
+		return
+	}
+	if notifier == nil || len(fresh) == 0 {
+		return
+	}
+	jobs := slices.Clone(fresh)
+	if scorer := cfg.Scorer(); scorer != nil {
+		scorer.Rank(jobs)
+	}
+	if err := notifier.Notify(ctx, notify.Digest{New: jobs}); err != nil {
+		slog.Error("sending push notifications", "err", err)
+		return
+	}
+	var pushed []scraper.JobPosting
+	for _, job := range jobs {
+		if pushes.Match(job) {
+			pushed = append(pushed, job)
+		}
+	}
+	if err := db.RecordPush(pushed, time.Now()); err != nil {
+		slog.Error("recording push notifications", "err", err)
+	}
+}
+
+// flatten splits err into the errors joined in it, recursively, so each
+// failure can be reported on a line of its own.
+func flatten(err error) []error {
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		return []error{err}
+	}
+	var errs []error
+	for _, err := range joined.Unwrap() {
+		errs = append(errs, flatten(err)...)
+	}
+	return errs
+}
+
+// alertOnSelectorError warns through the notifiers when a source's
+// selectors stopped matching, since that fails silently otherwise: the
+// digest just looks like a quiet day.
+func alertOnSelectorError(ctx context.Context, cfg *config.Config, res scraper.Result) {
+	var selErr *scraper.SelectorError
+	if !errors.As(res.Err, &selErr) {
+		return
+	}
+	alert(ctx, cfg, fmt.Sprintf("Scraping %s found no job listings: %v. Check the selectors in the config.", res.Source, selErr))
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hunterheston/airbnb/pkg/output"
+	"github.com/hunterheston/airbnb/pkg/store"
+)
+
+// runSearch finds stored jobs, closed ones included, by the words in their
+// title, company, location, team or description.
+func runSearch(ctx context.Context, args []string) error {
+	fs, configPath := flagSet("search")
+	limit := fs.Int("limit", 20, "how many of the best matches to list; 0 lists them all")
+	format := outputFlag(fs)
+	cfg, words, err := parseArgs(fs, configPath, args)
+	if err != nil {
+		return err
+	}
+	f, err := output.ParseFormat(*format)
+	if err != nil {
+		return err
+	}
+	query := strings.Join(words, " ")
+	if strings.TrimSpace(query) == "" {
+		return errors.New("search: no words given")
+	}
+
+	db, err := store.Open(cfg.Database)
+	if err != nil {
+		return fmt.Errorf("opening store: %w", err)
+	}
+	defer db.Close()
+
+	hits, err := db.Search(query, *limit)
+	if err != nil {
+		return fmt.Errorf("searching: %w", err)
+	}
+	return output.WriteHits(os.Stdout, f, hits)
+}
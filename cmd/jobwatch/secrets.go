@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"filippo.io/age"
+	"github.com/charmbracelet/x/term"
+
+	"github.com/hunterheston/airbnb/pkg/secrets"
+)
+
+// runSecrets manages the secrets the config refers to: "secrets set NAME"
+// stores one, "secrets encrypt" encrypts one to write into the config and
+// "secrets keygen" creates the key that decrypts them.
+func runSecrets(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return errors.New("secrets: missing subcommand (set, encrypt or keygen)")
+	}
+	sub, args := args[0], args[1:]
+	switch sub {
+	case "set":
+		return runSecretsSet(ctx, args)
+	case "encrypt":
+		return runSecretsEncrypt(args)
+	case "keygen":
+		return runSecretsKeygen(args)
+	default:
+		return fmt.Errorf("secrets: unknown subcommand %q (want set, encrypt or keygen)", sub)
+	}
+}
+
+// runSecretsSet stores a secret, read from the terminal without echoing it
+// or else from standard input. It doesn't load the config, which may refer
+// to the secret being set.
+func runSecretsSet(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("jobwatch secrets set", flag.ExitOnError)
+	store := fs.String("store", "keyring", "where to store it: "+strings.Join(secrets.Stores, ", "))
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: jobwatch secrets set [-store keyring|vault|ssm] NAME\n\n")
+		fmt.Fprintf(fs.Output(), "NAME is e.g. GOOGLE_APP_PASSWORD for the keyring, secret/data/jobwatch#smtp\nfor Vault or /jobwatch/smtp for SSM.\n\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errors.New("secrets set: want one NAME")
+	}
+	name := fs.Arg(0)
+
+	s, err := secrets.Open(*store, nil)
+	if err != nil {
+		return err
+	}
+	value, err := readSecret(name)
+	if err != nil {
+		return err
+	}
+	if value == "" {
+		return errors.New("secrets set: empty value")
+	}
+	if err := s.Set(ctx, name, value); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "stored %s in %s; refer to it as ${%s:%s}\n", name, *store, *store, name)
+	return nil
+}
+
+// runSecretsEncrypt encrypts a value with age and prints it, armored, to
+// paste into the config as a block scalar ("password: |"). It is encrypted
+// to the -r recipients, or to the key in the key file.
+func runSecretsEncrypt(args []string) error {
+	fs := flag.NewFlagSet("jobwatch secrets encrypt", flag.ExitOnError)
+	to := fs.String("r", "", "comma-separated age recipients (age1...) to encrypt to; defaults to the key file's")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments: %v", fs.Args())
+	}
+
+	var recipients []age.Recipient
+	for _, r := range strings.Split(*to, ",") {
+		if r = strings.TrimSpace(r); r == "" {
+			continue
+		}
+		rcpt, err := age.ParseX25519Recipient(r)
+		if err != nil {
+			return err
+		}
+		recipients = append(recipients, rcpt)
+	}
+	if len(recipients) == 0 {
+		ids, err := secrets.Identities()
+		if err != nil {
+			return fmt.Errorf("%w (run \"jobwatch secrets keygen\" or pass -r)", err)
+		}
+		for _, id := range ids {
+			if x, ok := id.(*age.X25519Identity); ok {
+				recipients = append(recipients, x.Recipient())
+			}
+		}
+	}
+
+	value, err := readSecret("value")
+	if err != nil {
+		return err
+	}
+	out, err := secrets.Encrypt([]byte(value), recipients...)
+	if err != nil {
+		return err
+	}
+	_, err = os.Stdout.Write(out)
+	return err
+}
+
+// runSecretsKeygen creates an age identity in the key file, which must not
+// exist yet, and prints its public key.
+func runSecretsKeygen(args []string) error {
+	fs := flag.NewFlagSet("jobwatch secrets keygen", flag.ExitOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	path, err := secrets.KeyFile()
+	if err != nil {
+		return err
+	}
+	id, err := age.GenerateX25519Identity()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if errors.Is(err, os.ErrExist) {
+		return fmt.Errorf("%s already exists", path)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(f, "# created: %s\n# public key: %s\n%s\n", time.Now().Format(time.RFC3339), id.Recipient(), id)
+	if err := f.Close(); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "wrote %s; keep a copy somewhere safe\n", path)
+	fmt.Printf("public key: %s\n", id.Recipient())
+	return nil
+}
+
+// readSecret prompts for the value of name on the terminal, or reads it
+// from standard input when that's piped.
+func readSecret(name string) (string, error) {
+	if term.IsTerminal(os.Stdin.Fd()) {
+		fmt.Fprintf(os.Stderr, "%s: ", name)
+		b, err := term.ReadPassword(os.Stdin.Fd())
+		fmt.Fprintln(os.Stderr)
+		return string(b), err
+	}
+	b, err := io.ReadAll(os.Stdin)
+	return strings.TrimRight(string(b), "\r\n"), err
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/hunterheston/airbnb/pkg/bayes"
+	"github.com/hunterheston/airbnb/pkg/config"
+	"github.com/hunterheston/airbnb/pkg/dedup"
+	"github.com/hunterheston/airbnb/pkg/fit"
+	"github.com/hunterheston/airbnb/pkg/notify"
+	"github.com/hunterheston/airbnb/pkg/scraper"
+	"github.com/hunterheston/airbnb/pkg/store"
+	"github.com/hunterheston/airbnb/pkg/trace"
+)
+
+func runSend(ctx context.Context, args []string) error {
+	fs, configPath := flagSet("send")
+	dry, dryOut := dryRunFlags(fs)
+	force := forceFlag(fs)
+	cfg, err := parse(fs, configPath, args)
+	if err != nil {
+		return err
+	}
+	if *dry {
+		cleanup, err := dryRun(cfg, *dryOut)
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+	}
+	release, err := lock(cfg, *force)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	db, err := store.Open(cfg.Database)
+	if err != nil {
+		return fmt.Errorf("opening store: %w", err)
+	}
+	defer db.Close()
+
+	ctx, cancel := runContext(ctx, cfg)
+	defer cancel()
+	return send(ctx, cfg, db)
+}
+
+// send notifies about every stored job that hasn't been sent yet and every
+// announced job that has closed since. Jobs are only marked as sent when
+// every notifier succeeded, so a failed send is retried by the next one.
+// Scrapes that failed in part since the last digest are listed in it so a
+// quiet digest isn't mistaken for a complete one. With an outbox, queued
+// emails are retried first, and one that can't be delivered now is queued
+// and counts as sent. A digest with fewer than digest.min_new new jobs is
+// held back, unless none went out for digest.alive_every. With a summary
+// model, the new jobs are summarized first. Applications due a follow-up
+// are reminded of last, and changed interviews and deadlines are written
+// to the calendar.
+func send(ctx context.Context, cfg *config.Config, db *store.Store) (err error) {
+	ctx, span := trace.Start(ctx, "send")
+	defer func() {
+		trace.SetError(span, err)
+		span.End()
+	}()
+
+	useOutbox(cfg, db)
+	if err := flushOutbox(ctx, cfg, db); err != nil {
+		return err
+	}
+	defer alertStuck(ctx, cfg, db)
+	defer remind(ctx, cfg, db)
+	defer updateCalendar(ctx, cfg, db)
+
+	d, pending, partial, err := nextDigest(cfg, db)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if cfg.Digest.Hold(len(d.New), d.Stats.Since, now) {
+		slog.Info("holding the digest back", "new", len(d.New), "min_new", cfg.Digest.MinNew)
+		noteOutcome(db, fmt.Sprintf("held back %d new jobs, fewer than %d", len(d.New), cfg.Digest.MinNew))
+		return nil
+	}
+
+	if summarizer := cfg.Summarizer(db); summarizer != nil {
+		// The digest goes out whatever happens, with the summaries there are.
+		sumCtx, sumSpan := trace.Start(ctx, "summarize", attribute.Int("jobs", len(d.New)))
+		if err := summarizer.Summarize(sumCtx, d.New); err != nil {
+			slog.Warn("summarizing jobs", "err", err)
+			trace.SetError(sumSpan, err)
+		}
+		sumSpan.End()
+	}
+
+	notifier, err := cfg.Notifier()
+	if err != nil {
+		return fmt.Errorf("configuring notifiers: %w", err)
+	}
+	slog.Info("sending digest", "new", len(d.New), "closed", len(d.Closed), "partial", len(partial))
+	notifyCtx, notifySpan := trace.Start(ctx, "notify", attribute.Int("new", len(d.New)), attribute.Int("closed", len(d.Closed)))
+	err = notifier.Notify(notifyCtx, d)
+	trace.SetError(notifySpan, err)
+	notifySpan.End()
+	if err != nil {
+		noteOutcome(db, "failed: "+err.Error())
+		return fmt.Errorf("sending notifications: %w", err)
+	}
+	noteOutcome(db, fmt.Sprintf("sent %d new and %d closed jobs", len(d.New), len(d.Closed)))
+
+	if err := db.RecordDigest(len(d.New), len(d.Closed), now); err != nil {
+		return err
+	}
+	if err := db.MarkNotified(pending, now); err != nil {
+		return err
+	}
+	if err := db.MarkClosedNotified(d.Closed, now); err != nil {
+		return err
+	}
+	return db.MarkReported(partial, now)
+}
+
+// nextDigest gathers what the next digest holds: the jobs not sent yet,
+// ranked by interest, fit, then score, and deduplicated as configured, the
+// announced jobs closed since, and the failures and stats of the runs since
+// the last digest. It also returns the pending jobs in full and the failed
+// runs, which sending the digest marks as reported.
+func nextDigest(cfg *config.Config, db *store.Store) (d notify.Digest, pending []scraper.JobPosting, partial []store.Run, err error) {
+	if pending, err = db.Pending(); err != nil {
+		return d, nil, nil, fmt.Errorf("loading pending jobs: %w", err)
+	}
+	if d.Closed, err = db.PendingClosed(); err != nil {
+		return d, nil, nil, fmt.Errorf("loading closed jobs: %w", err)
+	}
+	if partial, err = db.Partial(); err != nil {
+		return d, nil, nil, fmt.Errorf("loading partial runs: %w", err)
+	}
+	d.Partial = failures(partial)
+	if scorer := cfg.Scorer(); scorer != nil {
+		scorer.Rank(pending)
+		// Scored too for the routes and channel filters that go by score.
+		for i := range d.Closed {
+			d.Closed[i].Score = scorer.Score(d.Closed[i])
+		}
+	}
+	if cfg.Resume.File != "" {
+		fit.Rank(pending)
+	}
+	if err := personalize(cfg, db, pending); err != nil {
+		return d, nil, nil, fmt.Errorf("learning from feedback: %w", err)
+	}
+	d.New = pending
+	if cfg.Dedup {
+		d.New = dedup.Collapse(pending)
+	}
+
+	if d.Stats.Since, err = db.LastDigest(); err != nil {
+		return d, nil, nil, fmt.Errorf("loading the last digest: %w", err)
+	}
+	runs, err := db.Runs(0)
+	if err != nil {
+		return d, nil, nil, fmt.Errorf("loading runs: %w", err)
+	}
+	for _, run := range runs {
+		if run.StartedAt.Before(d.Stats.Since) {
+			continue
+		}
+		d.Stats.Runs++
+		d.Stats.Pages += run.Pages
+		d.Stats.Found += run.Found
+		d.Stats.Matched += run.Matched
+	}
+	return d, pending, partial, nil
+}
+
+// personalize rates jobs by the interest the feedback given so far
+// predicts and moves the likely interesting ones to the top, once there is
+// enough feedback to go by.
+func personalize(cfg *config.Config, db *store.Store, jobs []scraper.JobPosting) error {
+	if !cfg.Learn.Enabled {
+		return nil
+	}
+	rated, err := db.Rated()
+	if err != nil {
+		return err
+	}
+	var c bayes.Classifier
+	for _, j := range rated {
+		c.Train(j.JobPosting, j.Feedback == store.FeedbackUp)
+	}
+	if !c.Trained(cfg.Learn.MinVotes) {
+		return nil
+	}
+	for i := range jobs {
+		jobs[i].Interest = c.Interest(jobs[i])
+	}
+	bayes.Rank(jobs, cfg.Learn.Threshold)
+	return nil
+}
+
+// noteOutcome records what became of the digest in the latest run's
+// history. Failing to is only logged.
+func noteOutcome(db *store.Store, outcome string) {
+	if err := db.SetNotification(outcome); err != nil {
+		slog.Error("recording the digest's outcome", "err", err)
+	}
+}
+
+// failures returns the distinct error lines of runs, in order.
+func failures(runs []store.Run) []string {
+	var lines []string
+	seen := map[string]bool{}
+	for _, r := range runs {
+		for _, line := range strings.Split(r.Error, "\n") {
+			if line == "" || seen[line] {
+				continue
+			}
+			seen[line] = true
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
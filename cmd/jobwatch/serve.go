@@ -0,0 +1,256 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/hunterheston/airbnb/pkg/config"
+	"github.com/hunterheston/airbnb/pkg/errreport"
+	"github.com/hunterheston/airbnb/pkg/schedule"
+	"github.com/hunterheston/airbnb/pkg/store"
+	"github.com/hunterheston/airbnb/pkg/web"
+)
+
+// runServe stays resident, running on the config's schedules. SIGINT or
+// SIGTERM lets the runs in progress finish before exiting, and a second
+// one abandons them. SIGHUP reloads the config file, which the schedules,
+// filters and channels follow from the next run on.
+func runServe(ctx context.Context, args []string) error {
+	fs, configPath := flagSet("serve")
+	listen := fs.String("listen", "", "also serve the jobs dashboard on this address, e.g. localhost:8080")
+	cfg, err := parse(fs, configPath, args)
+	if err != nil {
+		return err
+	}
+
+	// Runs get work rather than ctx, so that shutting down doesn't cut them
+	// short.
+	work, abandon := context.WithCancel(context.WithoutCancel(ctx))
+	defer abandon()
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-work.Done():
+			return
+		}
+		sig := make(chan os.Signal, 1)
+		signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+		defer signal.Stop(sig)
+		select {
+		case <-sig:
+			slog.Warn("abandoning the runs in progress")
+			abandon()
+		case <-work.Done():
+		}
+	}()
+
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	if cfg.Debug.Enabled && *listen == "" {
+		slog.Warn("the debug endpoints are only served with -listen")
+	}
+	if cfg.Debug.Enabled && cfg.Debug.Token == "" && *listen != "" && !loopback(*listen) {
+		return errors.New("debug.enabled needs a debug.token unless -listen is a loopback address such as localhost:8080")
+	}
+	if *listen != "" || cfg.Actions.Listen != "" {
+		// The dashboard keeps the database, actions, calendar settings and
+		// debug endpoints it started with until a restart.
+		db, err := store.Open(cfg.Database)
+		if err != nil {
+			return fmt.Errorf("opening store: %w", err)
+		}
+		defer db.Close()
+
+		dashboard := web.NewServer(db)
+		dashboard.FollowUp = cfg.Reminders.FollowUp
+		dashboard.InterviewLength = cfg.Calendar.InterviewLength
+		if cfg.Debug.Enabled {
+			dashboard.EnableDebug(cfg.Debug.Token)
+		}
+		if cfg.Actions.Listen != "" {
+			links := web.NewServer(db)
+			links.Actions = cfg.Actions.Signer()
+			shutdown, err := listenAndServe("action links", cfg.Actions.Listen, links.ActionHandler())
+			if err != nil {
+				return err
+			}
+			defer shutdown()
+		} else {
+			dashboard.Actions = cfg.Actions.Signer()
+		}
+		if *listen != "" {
+			shutdown, err := listenAndServe("dashboard", *listen, dashboard)
+			if err != nil {
+				return err
+			}
+			defer shutdown()
+		}
+	}
+
+	for {
+		gen, stop := context.WithCancel(ctx)
+		done := make(chan error, 1)
+		go func() { done <- daemons(gen, work, cfg) }()
+
+		var next *config.Config
+		for next == nil {
+			select {
+			case err := <-done:
+				stop()
+				return err
+			case <-hup:
+				c, err := load(fs, *configPath)
+				if err != nil {
+					slog.Error("reloading the config failed; keeping the old one", "err", err)
+					continue
+				}
+				next = c
+			}
+		}
+		slog.Info("config reloaded; the schedules restart once the runs in progress finish")
+		stop()
+		if err := <-done; err != nil {
+			return err
+		}
+		cfg = next
+	}
+}
+
+// daemons runs cfg's schedules until ctx is cancelled and the runs in
+// progress, which get work, have finished.
+func daemons(ctx, work context.Context, cfg *config.Config) error {
+	sched, err := schedule.Parse(cfg.Schedule)
+	if err != nil {
+		return fmt.Errorf("parsing schedule: %w", err)
+	}
+
+	if cfg.Inbox.Host != "" && cfg.Inbox.Interval > 0 {
+		done := make(chan struct{})
+		go func() {
+			pollInbox(ctx, work, cfg)
+			close(done)
+		}()
+		defer func() { <-done }()
+	}
+
+	if cfg.Calendar.CalendarID != "" && cfg.Calendar.Interval > 0 {
+		done := make(chan struct{})
+		go func() {
+			pollCalendar(ctx, work, cfg)
+			close(done)
+		}()
+		defer func() { <-done }()
+	}
+
+	if cfg.ReportSchedule != "" {
+		reportSched, err := schedule.Parse(cfg.ReportSchedule)
+		if err != nil {
+			return fmt.Errorf("parsing report schedule: %w", err)
+		}
+		done := make(chan error, 1)
+		go func() {
+			done <- runDaemon(ctx, work, "report", reportSched, func(ctx context.Context) error { return reportOnce(ctx, cfg) })
+		}()
+		defer func() {
+			if err := <-done; err != nil {
+				slog.Error("report schedule stopped", "err", err)
+			}
+		}()
+	}
+	return runDaemon(ctx, work, "run", sched, func(ctx context.Context) error {
+		release, err := lock(cfg, false)
+		if err != nil {
+			return err
+		}
+		defer release()
+		return runOnce(ctx, cfg)
+	})
+}
+
+// runDaemon calls job every time sched fires until ctx is cancelled. A job
+// in progress gets work instead, so that it finishes before runDaemon
+// returns. name identifies the job in the logs.
+func runDaemon(ctx, work context.Context, name string, sched *schedule.Cron, job func(context.Context) error) error {
+	for {
+		next := sched.Next(time.Now())
+		if next.IsZero() {
+			return fmt.Errorf("schedule %q never fires", sched)
+		}
+		slog.Info("next run scheduled", "job", name, "at", next)
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			slog.Info("stopping", "job", name)
+			return nil
+		case <-timer.C:
+		}
+
+		start := time.Now()
+		if err := safely(work, name, job); err != nil {
+			slog.Error("run failed", "job", name, "duration", time.Since(start), "err", err)
+			capture(withTags(errreport.New(err, 0), "command", "serve", "job", name))
+		} else {
+			slog.Info("run finished", "job", name, "duration", time.Since(start))
+		}
+		flushTraces()
+	}
+}
+
+// safely calls job, turning a panic into an error, after reporting it, so
+// that one bad run doesn't take the daemon down.
+func safely(ctx context.Context, name string, job func(context.Context) error) (err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			capture(withTags(errreport.Recovered(v, 0), "command", "serve", "job", name))
+			err = fmt.Errorf("panic: %v", v)
+		}
+	}()
+	return job(ctx)
+}
+
+// listenAndServe binds addr and serves h on it in the background, and
+// returns the function that shuts it down.
+func listenAndServe(name, addr string, h http.Handler) (shutdown func(), err error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("serving %s: %w", name, err)
+	}
+	srv := &http.Server{Addr: addr, Handler: h}
+	slog.Info("serving "+name, "url", "http://"+addr+"/")
+	go func() {
+		if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error(name+" stopped", "err", err)
+		}
+	}()
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(ctx)
+	}, nil
+}
+
+// loopback reports whether addr, a listen address, only takes connections
+// from this machine.
+func loopback(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
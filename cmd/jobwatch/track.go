@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/hunterheston/airbnb/pkg/store"
+)
+
+// runTrack sets or clears a job's interview time and application deadline
+// and writes them to the calendar, if one is configured. Without either
+// flag it prints them.
+func runTrack(ctx context.Context, args []string) error {
+	fs, configPath := flagSet("track")
+	interview := fs.String("interview", "", `the next interview, e.g. "2026-11-03 14:00" in local time; "" clears it`)
+	deadline := fs.String("deadline", "", `the day applications close, e.g. 2026-11-01; "" clears it`)
+	cfg, jobs, err := parseArgs(fs, configPath, args)
+	if err != nil {
+		return err
+	}
+	if len(jobs) != 1 {
+		return errors.New("track: give one job, by its ID or posting URL")
+	}
+	set := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+
+	db, err := store.Open(cfg.Database)
+	if err != nil {
+		return fmt.Errorf("opening store: %w", err)
+	}
+	defer db.Close()
+
+	job, err := lookup(db, jobs[0])
+	if err != nil {
+		return fmt.Errorf("%s: %w", jobs[0], err)
+	}
+	now := time.Now()
+	if set["interview"] {
+		at, err := parseWhen(*interview, "2006-01-02 15:04", "2006-01-02T15:04", time.RFC3339)
+		if err != nil {
+			return fmt.Errorf("track: -interview: %w", err)
+		}
+		if err := db.SetInterview(job.ID, at, now); err != nil {
+			return err
+		}
+	}
+	if set["deadline"] {
+		day, err := parseWhen(*deadline, time.DateOnly)
+		if err != nil {
+			return fmt.Errorf("track: -deadline: %w", err)
+		}
+		if err := db.SetDeadline(job.ID, day, now); err != nil {
+			return err
+		}
+	}
+	if job, err = db.Get(job.ID); err != nil {
+		return err
+	}
+
+	fmt.Printf("%d: %s at %s\n", job.ID, job.Title, job.Company)
+	fmt.Printf("  interview: %s\n", formatWhen(job.InterviewAt, "2006-01-02 15:04"))
+	fmt.Printf("  deadline:  %s\n", formatWhen(job.Deadline, time.DateOnly))
+	if len(set) == 0 {
+		return nil
+	}
+
+	ctx, cancel := runContext(ctx, cfg)
+	defer cancel()
+	n, err := syncCalendar(ctx, cfg, db)
+	if err != nil {
+		return fmt.Errorf("track: the dates are saved, but not in the calendar yet: %w", err)
+	}
+	if n > 0 {
+		fmt.Printf("updated the calendar (%d jobs)\n", n)
+	}
+	return nil
+}
+
+// parseWhen parses s, in local time, with the first of layouts that fits.
+// An empty s is nil.
+func parseWhen(s string, layouts ...string) (*time.Time, error) {
+	if s == "" {
+		return nil, nil
+	}
+	for _, layout := range layouts {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return &t, nil
+		}
+	}
+	return nil, fmt.Errorf("%q isn't like %s", s, layouts[0])
+}
+
+func formatWhen(t *time.Time, layout string) string {
+	if t == nil {
+		return "none"
+	}
+	return t.Local().Format(layout)
+}
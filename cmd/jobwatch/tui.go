@@ -0,0 +1,24 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hunterheston/airbnb/pkg/store"
+	"github.com/hunterheston/airbnb/pkg/tui"
+)
+
+func runTUI(ctx context.Context, args []string) error {
+	fs, configPath := flagSet("tui")
+	cfg, err := parse(fs, configPath, args)
+	if err != nil {
+		return err
+	}
+
+	db, err := store.Open(cfg.Database)
+	if err != nil {
+		return fmt.Errorf("opening store: %w", err)
+	}
+	defer db.Close()
+	return tui.Run(ctx, db)
+}
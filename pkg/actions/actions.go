@@ -0,0 +1,100 @@
+// Package actions makes the one-click links a digest carries under each
+// job, for marking it as interesting, applied to or muted straight from
+// the inbox, and checks them when the dashboard is sent one. Links are
+// signed with a secret so that nobody else can forge them.
+package actions
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"strings"
+
+	"github.com/hunterheston/airbnb/pkg/scraper"
+)
+
+// The actions a link can take.
+const (
+	Interested = "interested"
+	Applied    = "applied"
+	Mute       = "mute"
+)
+
+// All lists the actions in the order the digest shows them.
+var All = []string{Interested, Applied, Mute}
+
+// minSecret is the shortest secret accepted, in bytes.
+const minSecret = 16
+
+// Config is the actions section of the config file.
+type Config struct {
+	// URL is where "jobwatch serve -listen" can be reached from wherever
+	// the email is read, e.g. https://jobs.example.com. Without it the
+	// digest has no action links.
+	URL string `yaml:"url"`
+	// Secret signs the links. Changing it breaks the links already sent.
+	Secret string `yaml:"secret"`
+	// Listen, if set, is the address "jobwatch serve" answers the links
+	// on, e.g. :8081, by themselves rather than with the dashboard, whose
+	// other endpoints change jobs for anyone who can reach them. Point URL
+	// at it.
+	Listen string `yaml:"listen"`
+}
+
+// Validate checks c.
+func (c Config) Validate() error {
+	if c.URL == "" {
+		if c.Listen != "" {
+			return errors.New("actions: listen needs a url")
+		}
+		return nil
+	}
+	u, err := url.Parse(c.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("actions: url must be an http or https URL")
+	}
+	if len(c.Secret) < minSecret {
+		return errors.New("actions: secret must be at least 16 characters")
+	}
+	return nil
+}
+
+// Signer returns the signer for c, or nil if c has no URL.
+func (c Config) Signer() *Signer {
+	if c.URL == "" {
+		return nil
+	}
+	return &Signer{BaseURL: c.URL, Secret: []byte(c.Secret)}
+}
+
+// Signer makes and checks action links.
+type Signer struct {
+	// BaseURL is the dashboard's address; links go to its /act.
+	BaseURL string
+	Secret  []byte
+}
+
+// URL returns the link that takes action on job.
+func (s *Signer) URL(action string, job scraper.JobPosting) string {
+	key := job.Key()
+	v := url.Values{}
+	v.Set("job", key)
+	v.Set("do", action)
+	v.Set("sig", s.sign(action, key))
+	return strings.TrimSuffix(s.BaseURL, "/") + "/act?" + v.Encode()
+}
+
+// Verify reports whether sig is the signature of action on the job with
+// the given key.
+func (s *Signer) Verify(action, key, sig string) bool {
+	return hmac.Equal([]byte(sig), []byte(s.sign(action, key)))
+}
+
+func (s *Signer) sign(action, key string) string {
+	mac := hmac.New(sha256.New, s.Secret)
+	mac.Write([]byte(action + "\n" + key))
+	// Half the MAC is plenty against guessing and keeps the links short.
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)[:16])
+}
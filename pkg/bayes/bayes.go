@@ -0,0 +1,133 @@
+// Package bayes learns from thumbs-up and thumbs-down feedback which jobs
+// interest you, with a naive Bayes classifier over the words of their
+// titles and descriptions, so that the digest can put the jobs most like
+// the ones you liked first.
+package bayes
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/hunterheston/airbnb/pkg/scraper"
+)
+
+// Config is the learn section of the config file.
+type Config struct {
+	// Enabled ranks the digest by the feedback given so far.
+	Enabled bool `yaml:"enabled"`
+	// MinVotes is how many thumbs-ups and how many thumbs-downs it takes
+	// before the classifier is trusted; until then the digest keeps its
+	// order.
+	MinVotes int `yaml:"min_votes"`
+	// Threshold is the interest, in percent, from which a job is moved to
+	// the top of the digest.
+	Threshold int `yaml:"threshold"`
+}
+
+// Validate checks c.
+func (c Config) Validate() error {
+	if c.MinVotes < 0 {
+		return errors.New("learn: min_votes must not be negative")
+	}
+	if c.Threshold < 0 || c.Threshold > 100 {
+		return errors.New("learn: threshold must be between 0 and 100")
+	}
+	return nil
+}
+
+// Classes, indexing Classifier's counts.
+const (
+	dull = iota
+	interesting
+)
+
+// Classifier is a naive Bayes classifier of jobs into interesting and not.
+// The zero value is ready to train.
+type Classifier struct {
+	docs   [2]int
+	counts [2]map[string]int
+	total  [2]int
+	vocab  map[string]bool
+}
+
+// Train counts job as an example of an interesting job, or of a dull one.
+func (c *Classifier) Train(job scraper.JobPosting, isInteresting bool) {
+	class := dull
+	if isInteresting {
+		class = interesting
+	}
+	if c.counts[class] == nil {
+		c.counts[class] = map[string]int{}
+	}
+	if c.vocab == nil {
+		c.vocab = map[string]bool{}
+	}
+	c.docs[class]++
+	for _, tok := range Tokens(job) {
+		c.counts[class][tok]++
+		c.total[class]++
+		c.vocab[tok] = true
+	}
+}
+
+// Trained reports whether c has seen at least min examples of each class.
+func (c *Classifier) Trained(min int) bool {
+	return c.docs[dull] >= max(min, 1) && c.docs[interesting] >= max(min, 1)
+}
+
+// Interest returns how likely job is to be interesting, from 0 to 100.
+func (c *Classifier) Interest(job scraper.JobPosting) int {
+	if !c.Trained(1) {
+		return 0
+	}
+	var logp [2]float64
+	all := float64(c.docs[dull] + c.docs[interesting])
+	for class := range logp {
+		logp[class] = math.Log(float64(c.docs[class]) / all)
+		// Laplace smoothing, so that a word seen in one class only doesn't
+		// rule the other out.
+		denom := float64(c.total[class] + len(c.vocab))
+		for _, tok := range Tokens(job) {
+			if !c.vocab[tok] {
+				continue
+			}
+			logp[class] += math.Log(float64(c.counts[class][tok]+1) / denom)
+		}
+	}
+	p := 1 / (1 + math.Exp(logp[dull]-logp[interesting]))
+	return int(math.Round(p * 100))
+}
+
+// Rank moves the jobs whose Interest reaches threshold to the top, keeping
+// the order of both the jobs moved and the rest.
+func Rank(jobs []scraper.JobPosting, threshold int) {
+	sort.SliceStable(jobs, func(a, b int) bool {
+		return jobs[a].Interest >= threshold && jobs[b].Interest < threshold
+	})
+}
+
+// Tokens returns the distinct lower-cased words of job's title, prefixed
+// with "title:" so they count apart from the description's, and of its
+// description. A word counts once per job however often it appears, so
+// that long descriptions don't drown out short ones.
+func Tokens(job scraper.JobPosting) []string {
+	seen := map[string]bool{}
+	var toks []string
+	add := func(prefix, text string) {
+		for _, w := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '+' && r != '#'
+		}) {
+			if len(w) < 2 || seen[prefix+w] {
+				continue
+			}
+			seen[prefix+w] = true
+			toks = append(toks, prefix+w)
+		}
+	}
+	add("title:", job.Title)
+	add("", job.Description)
+	return toks
+}
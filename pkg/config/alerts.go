@@ -0,0 +1,35 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/hunterheston/airbnb/pkg/notify"
+)
+
+// Alerts are the operational messages, as opposed to the digest: a source
+// that stopped parsing or was given up on, and runs that failed outright,
+// e.g. because the mail server is down.
+type Alerts struct {
+	// Notifiers receive the alerts. They default to the top-level
+	// notifiers; listing others, e.g. "ntfy", lets failures get through
+	// when the digest's own channel is what broke, and only then are failed
+	// runs alerted on.
+	Notifiers []string `yaml:"notifiers"`
+}
+
+// AlertNotifier returns the notifier that alerts go to.
+func (c *Config) AlertNotifier() (notify.Notifier, error) {
+	names := c.Alerts.Notifiers
+	if len(names) == 0 {
+		names = c.Notifiers
+	}
+	var m notify.Multi
+	for _, name := range names {
+		n, err := c.channel(name, nil)
+		if err != nil {
+			return nil, fmt.Errorf("config: alerts: %w", err)
+		}
+		m = append(m, n)
+	}
+	return m, nil
+}
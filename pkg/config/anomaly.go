@@ -0,0 +1,64 @@
+package config
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/hunterheston/airbnb/pkg/notify"
+)
+
+// Anomalies watches how many jobs each run finds, since a sudden drop, or
+// a jump, more often means a page changed than that the jobs did.
+type Anomalies struct {
+	// Change is the percentage by which a run's job count may differ from
+	// the average of the ones before it without an alert. A run that finds
+	// no jobs at all is always reported.
+	Change float64 `yaml:"change"`
+	// Window is how many earlier runs the average covers. Fewer than that
+	// are too few to judge by, so nothing is reported until then.
+	Window int `yaml:"window"`
+	// Notifiers are the channels told about anomalous runs, kept apart from
+	// the digest's, e.g. "ntfy". Without any the check is off.
+	Notifiers []string `yaml:"notifiers"`
+}
+
+// Check compares found, the jobs the latest run found, with the earlier
+// runs' counts, newest first, and returns why it looks wrong, or "".
+func (a Anomalies) Check(found int, earlier []int) string {
+	if a.Window <= 0 || len(earlier) < a.Window {
+		return ""
+	}
+	total := 0
+	for _, n := range earlier[:a.Window] {
+		total += n
+	}
+	avg := float64(total) / float64(a.Window)
+	if avg == 0 {
+		return ""
+	}
+	if found == 0 {
+		return fmt.Sprintf("found no jobs, against %.0f on average over the last %d runs", avg, a.Window)
+	}
+	change := (float64(found) - avg) / avg * 100
+	if math.Abs(change) <= a.Change {
+		return ""
+	}
+	return fmt.Sprintf("found %d jobs, %+.0f%% against %.0f on average over the last %d runs", found, change, avg, a.Window)
+}
+
+// AnomalyNotifier returns the notifier told about anomalous runs, or nil if
+// no channels are listed.
+func (c *Config) AnomalyNotifier() (notify.Notifier, error) {
+	if len(c.Anomalies.Notifiers) == 0 {
+		return nil, nil
+	}
+	var m notify.Multi
+	for _, name := range c.Anomalies.Notifiers {
+		n, err := c.channel(name, nil)
+		if err != nil {
+			return nil, fmt.Errorf("config: anomalies: %w", err)
+		}
+		m = append(m, n)
+	}
+	return m, nil
+}
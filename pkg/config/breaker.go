@@ -0,0 +1,30 @@
+package config
+
+import "time"
+
+// Breaker stops scraping a source that keeps failing, rather than hitting
+// a broken endpoint on every run, and tries it again after a cooldown.
+type Breaker struct {
+	// Failures is how many runs in a row a source must fail outright, with
+	// no jobs scraped, for its breaker to open. Zero never opens it.
+	Failures int `yaml:"failures"`
+	// Cooldown is how long an open breaker skips its source. The next run
+	// after it tries the source once: success closes the breaker, failure
+	// opens it for another cooldown.
+	Cooldown time.Duration `yaml:"cooldown"`
+}
+
+// Trips reports whether failures runs failed in a row open the breaker.
+func (b Breaker) Trips(failures int) bool {
+	return b.Failures > 0 && failures >= b.Failures
+}
+
+// Skip reports whether a source whose breaker opened at openedAt (nil if
+// it is closed) should be skipped at now, and until when.
+func (b Breaker) Skip(openedAt *time.Time, now time.Time) (until time.Time, skip bool) {
+	if openedAt == nil || b.Failures <= 0 {
+		return time.Time{}, false
+	}
+	until = openedAt.Add(b.Cooldown)
+	return until, now.Before(until)
+}
@@ -0,0 +1,828 @@
+// Package config loads the scraper's settings from a YAML file.
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/hunterheston/airbnb/pkg/actions"
+	"github.com/hunterheston/airbnb/pkg/bayes"
+	"github.com/hunterheston/airbnb/pkg/errreport"
+	"github.com/hunterheston/airbnb/pkg/filter"
+	"github.com/hunterheston/airbnb/pkg/fit"
+	"github.com/hunterheston/airbnb/pkg/gcal"
+	"github.com/hunterheston/airbnb/pkg/heartbeat"
+	"github.com/hunterheston/airbnb/pkg/inbox"
+	"github.com/hunterheston/airbnb/pkg/level"
+	"github.com/hunterheston/airbnb/pkg/notify"
+	"github.com/hunterheston/airbnb/pkg/plugin"
+	"github.com/hunterheston/airbnb/pkg/schedule"
+	"github.com/hunterheston/airbnb/pkg/score"
+	"github.com/hunterheston/airbnb/pkg/scraper"
+	"github.com/hunterheston/airbnb/pkg/secrets"
+	"github.com/hunterheston/airbnb/pkg/summary"
+	"github.com/hunterheston/airbnb/pkg/tags"
+	"github.com/hunterheston/airbnb/pkg/trace"
+)
+
+// Config is the top-level configuration file.
+type Config struct {
+	// Database is the path of the SQLite file holding seen jobs.
+	Database string `yaml:"database"`
+	// Schedule is the cron expression used by "jobwatch serve".
+	Schedule string `yaml:"schedule"`
+	// ReportSchedule, if set, is when "jobwatch serve" sends the weekly
+	// report, e.g. "0 9 * * 1" for Monday mornings.
+	ReportSchedule string    `yaml:"report_schedule"`
+	Log            LogConfig `yaml:"log"`
+
+	Sources []scraper.SourceConfig `yaml:"sources"`
+	Retry   scraper.RetryPolicy    `yaml:"retry"`
+	// DetailWorkers bounds concurrent job detail page fetches per source.
+	DetailWorkers int `yaml:"detail_workers"`
+	// Cache is a directory where fetched pages are kept so later runs can
+	// make conditional requests. Leave empty to always fetch pages in full.
+	Cache string `yaml:"cache"`
+	// Archive is a directory where every scrape saves the pages it fetched,
+	// in a subdirectory per scrape, for replaying with "scrape -replay".
+	Archive string `yaml:"archive"`
+	// Replay is an archive subdirectory parsed instead of fetching pages.
+	// It is set by "scrape -replay", not the config file.
+	Replay string `yaml:"-"`
+	// UserAgent is sent by sources that don't set their own; it defaults to
+	// an identifying jobwatch string with a contact URL.
+	UserAgent string `yaml:"user_agent"`
+	// Proxy is the proxy URL for sources without their own; see
+	// scraper.SourceConfig.Proxy. Left empty, HTTP_PROXY, HTTPS_PROXY and
+	// NO_PROXY are honored.
+	Proxy string `yaml:"proxy"`
+	// Politeness is how gently hosts are crawled: robots.txt and the delay
+	// between requests to the same host.
+	Politeness scraper.Politeness `yaml:"politeness"`
+	// Timeouts keep a hung connection from stalling a run forever.
+	Timeouts Timeouts `yaml:"timeouts"`
+
+	Filter filter.Config `yaml:"filter"`
+	// LevelRules replace level.DefaultRules for classifying titles by
+	// seniority. They are tried in order; unmatched titles are mid-level.
+	LevelRules []level.Rule `yaml:"level_rules"`
+	// Profile weights keywords in titles and descriptions; when set, the
+	// digest sorts new jobs by their score and shows it.
+	Profile score.Profile `yaml:"profile"`
+	// Tags tags jobs with the technologies their descriptions mention.
+	Tags tags.Config `yaml:"tags"`
+	// Resume rates each job by how well it fits a resume.
+	Resume fit.Config `yaml:"resume"`
+	// Learn ranks the digest by the thumbs-up and -down feedback given to
+	// earlier jobs.
+	Learn bayes.Config `yaml:"learn"`
+	// Summary has a language model summarize each new job for the digest.
+	Summary summary.Config `yaml:"summary"`
+	// Dedup collapses the postings of one opening in several locations,
+	// matched by title and requisition ID, into one digest entry listing
+	// every location.
+	Dedup bool `yaml:"dedup"`
+	// Digest holds back digests with too few new jobs.
+	Digest Digest `yaml:"digest"`
+
+	// Notifiers lists the channels new jobs are sent to: "email", "slack",
+	// "discord", "teams", "matrix", "telegram", "ntfy", "pushover",
+	// "webhook", "sheets", "notion", "github", "trello" and/or "jira".
+	Notifiers []string `yaml:"notifiers"`
+	// ChannelFilters narrows what individual channels receive, keyed by
+	// channel name. They apply on top of Filter.
+	ChannelFilters map[string]filter.Config `yaml:"channel_filters"`
+
+	// Routes send different subsets of the jobs to different channels.
+	Routes []Route `yaml:"routes"`
+
+	// Subscriptions send separate digests, each with its own filter and
+	// channels, to people sharing this deployment.
+	Subscriptions []Subscription `yaml:"subscriptions"`
+
+	Email    notify.EmailNotifier    `yaml:"email"`
+	Slack    notify.SlackNotifier    `yaml:"slack"`
+	Discord  notify.DiscordNotifier  `yaml:"discord"`
+	Teams    notify.TeamsNotifier    `yaml:"teams"`
+	Matrix   notify.MatrixNotifier   `yaml:"matrix"`
+	Telegram notify.TelegramNotifier `yaml:"telegram"`
+	Ntfy     notify.NtfyNotifier     `yaml:"ntfy"`
+	Pushover notify.PushoverNotifier `yaml:"pushover"`
+	Webhook  notify.WebhookNotifier  `yaml:"webhook"`
+	Sheets   notify.SheetsNotifier   `yaml:"sheets"`
+	Notion   notify.NotionNotifier   `yaml:"notion"`
+	GitHub   notify.GitHubNotifier   `yaml:"github"`
+	Trello   notify.TrelloNotifier   `yaml:"trello"`
+	Jira     notify.JiraNotifier     `yaml:"jira"`
+
+	// Actions puts signed links under each job in the email that mark it
+	// through the dashboard.
+	Actions actions.Config `yaml:"actions"`
+	// Inbox reads replies to the digest over IMAP and carries out the
+	// commands in them, e.g. "mute 12345".
+	Inbox inbox.Config `yaml:"inbox"`
+
+	// Alerts sends the operational alerts, such as a source that broke, to
+	// channels of their own.
+	Alerts Alerts `yaml:"alerts"`
+	// Push sends standout jobs to some channels as soon as a scrape finds
+	// them, without waiting for the digest.
+	Push Push `yaml:"push"`
+	// Outbox retries emails that couldn't be delivered on later runs.
+	Outbox Outbox `yaml:"outbox"`
+	// Reminders say when an application is due a follow-up.
+	Reminders Reminders `yaml:"reminders"`
+	// Calendar puts the interviews and application deadlines set on jobs
+	// in a Google Calendar.
+	Calendar gcal.Config `yaml:"calendar"`
+	// CircuitBreaker skips sources that keep failing for a while.
+	CircuitBreaker Breaker `yaml:"circuit_breaker"`
+	// Anomalies reports runs whose job count looks wrong.
+	Anomalies Anomalies `yaml:"anomalies"`
+	// Plugins are executables that add sources and filters.
+	Plugins plugin.Config `yaml:"plugins"`
+	// Heartbeat, if its URL is set, is pinged after every run, so a
+	// dead man's switch notices when the runs stop.
+	Heartbeat heartbeat.Pinger `yaml:"heartbeat"`
+	// Sentry, if its DSN is set, collects the errors and panics of runs
+	// with their stack traces.
+	Sentry errreport.Sentry `yaml:"sentry"`
+	// Tracing exports spans timing each stage of a run to an OpenTelemetry
+	// collector.
+	Tracing trace.Config `yaml:"tracing"`
+	// Debug serves profiles and runtime variables for diagnosing
+	// "jobwatch serve".
+	Debug Debug `yaml:"debug"`
+}
+
+// Timeouts bound how long the network may hold up a run. Zero means no
+// limit.
+type Timeouts struct {
+	// Request bounds each HTTP request, reading the response included, and
+	// each email delivery.
+	Request time.Duration `yaml:"request"`
+	// Run bounds a whole run, report or send: scraping every source and
+	// sending the digest. Whatever is left when it expires is abandoned and
+	// picked up by the next run.
+	Run time.Duration `yaml:"run"`
+	// Lock is how long the lock a run holds on the database may go
+	// untouched before another run assumes its holder died and takes it
+	// over. A running holder touches it every minute.
+	Lock time.Duration `yaml:"lock"`
+}
+
+// Default returns the settings used when no config file exists. Email
+// settings come from FROM_EMAIL, TO_EMAIL and GOOGLE_APP_PASSWORD in the
+// environment, or once the email channel is built, the OS keyring.
+func Default() *Config {
+	return &Config{
+		Database: "jobs.db",
+		Schedule: "0 9 * * *",
+		Sources: []scraper.SourceConfig{{
+			Type:        "airbnb",
+			Departments: []string{"engineering"},
+			Offices:     []string{"united-states"},
+		}},
+		Retry:          scraper.DefaultRetryPolicy(),
+		DetailWorkers:  4,
+		Politeness:     scraper.DefaultPoliteness(),
+		Timeouts:       Timeouts{Request: 30 * time.Second, Run: 30 * time.Minute, Lock: time.Hour},
+		Filter:         defaultFilter(),
+		Notifiers:      []string{"email"},
+		Email:          *notify.NewEmailNotifierFromEnv(),
+		Outbox:         Outbox{Backoff: 15 * time.Minute, AlertAfter: 3},
+		CircuitBreaker: Breaker{Cooldown: 24 * time.Hour},
+		Anomalies:      Anomalies{Change: 50, Window: 7},
+		Plugins:        plugin.Config{Timeout: 10 * time.Minute},
+		Learn:          bayes.Config{MinVotes: 5, Threshold: 70},
+		Summary:        summary.Config{URL: summary.DefaultURL, Interval: time.Second, Max: 20},
+		Calendar:       gcal.Config{InterviewLength: time.Hour},
+	}
+}
+
+func defaultFilter() filter.Config {
+	return filter.MidLevelSoftwareEngineer()
+}
+
+// Load reads the config file at path on top of Default. A missing file is
+// not an error. ${VAR} in a value is expanded from the environment, and
+// ${keyring:NAME}, ${vault:PATH#FIELD} and ${ssm:NAME} are fetched from
+// those secret stores, so secrets don't have to be written into it; $$ is
+// a literal $, and any other $ is left alone. Values encrypted with age
+// are decrypted with the key in secrets.KeyFile. A whole file encrypted
+// with SOPS is decrypted as sops decrypts it, and its values are then
+// taken as they are, without expanding references.
+func Load(path string) (*Config, error) {
+	cfg := Default()
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Lists replace the defaults rather than merging with them.
+	cfg.Sources = nil
+	cfg.Filter = filter.Config{}
+	cfg.Notifiers = nil
+	cfg.Email.To = nil
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	if secrets.IsSOPS(&doc) {
+		// The MAC covers the values as they are written, so nothing may
+		// change them before they are decrypted.
+		plain, err := secrets.DecryptSOPS(data)
+		if err != nil {
+			return nil, fmt.Errorf("config: decrypting %s: %w", path, err)
+		}
+		doc = yaml.Node{}
+		if err := yaml.Unmarshal(plain, &doc); err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", path, err)
+		}
+	} else {
+		// References are expanded before decrypting, so that a
+		// decrypted value is taken as it is.
+		if err := expandValues(&doc); err != nil {
+			return nil, fmt.Errorf("config: %s: %w", path, err)
+		}
+	}
+	if err := secrets.Decrypt(&doc); err != nil {
+		return nil, fmt.Errorf("config: decrypting %s: %w", path, err)
+	}
+	if len(doc.Content) > 0 {
+		if err := doc.Decode(cfg); err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", path, err)
+		}
+	}
+	return cfg, cfg.validate()
+}
+
+// expandValues expands the references in n's scalar values; mapping keys
+// are left as they are.
+func expandValues(n *yaml.Node) error {
+	switch n.Kind {
+	case yaml.ScalarNode:
+		if !strings.Contains(n.Value, "$") {
+			return nil
+		}
+		v, err := expand(n.Value)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", n.Line, err)
+		}
+		n.Value = v
+		if n.Style&(yaml.SingleQuotedStyle|yaml.DoubleQuotedStyle|yaml.LiteralStyle|yaml.FoldedStyle) == 0 {
+			// Let an unquoted value be a number or bool again.
+			n.Tag = ""
+		}
+	case yaml.MappingNode:
+		for i := 1; i < len(n.Content); i += 2 {
+			if err := expandValues(n.Content[i]); err != nil {
+				return err
+			}
+		}
+	default:
+		for _, c := range n.Content {
+			if err := expandValues(c); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// expand replaces the ${...} references in s with the environment
+// variables or secrets they name, and $$ with $. A variable that isn't set
+// is an error rather than an empty value.
+func expand(s string) (string, error) {
+	var b strings.Builder
+	for {
+		i := strings.IndexByte(s, '$')
+		if i < 0 || i == len(s)-1 {
+			b.WriteString(s)
+			return b.String(), nil
+		}
+		b.WriteString(s[:i])
+		switch s[i+1] {
+		case '$':
+			b.WriteByte('$')
+			s = s[i+2:]
+			continue
+		case '{':
+		default:
+			b.WriteByte('$')
+			s = s[i+1:]
+			continue
+		}
+		ref, rest, ok := strings.Cut(s[i+2:], "}")
+		if !ok {
+			return "", fmt.Errorf("unterminated ${ (write $$ for a literal $)")
+		}
+		if secrets.IsRef(ref) {
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			v, err := secrets.Resolve(ctx, ref, nil)
+			cancel()
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(v)
+		} else if v, set := os.LookupEnv(ref); set {
+			b.WriteString(v)
+		} else {
+			return "", fmt.Errorf("${%s}: %s isn't set (write $$ for a literal $)", ref, ref)
+		}
+		s = rest
+	}
+}
+
+func (c *Config) validate() error {
+	if c.Database == "" {
+		return errors.New("config: database must be set")
+	}
+	if _, err := c.Filter.Build(); err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+	if err := c.Tags.Validate(); err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+	if err := c.Resume.Validate(); err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+	if err := c.Actions.Validate(); err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+	// The keyring isn't read for this, only when the inbox is polled.
+	if err := c.replyInbox(&c.Email).Validate(); err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+	if err := c.Tracing.Validate(); err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+	if err := c.Sentry.Validate(); err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+	if err := c.Learn.Validate(); err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+	if err := c.Summary.Validate(); err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+	if err := c.Calendar.Validate(); err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+	if _, err := schedule.Parse(c.Schedule); err != nil {
+		return fmt.Errorf("config: schedule: %w", err)
+	}
+	if c.ReportSchedule != "" {
+		if _, err := schedule.Parse(c.ReportSchedule); err != nil {
+			return fmt.Errorf("config: report_schedule: %w", err)
+		}
+	}
+	if _, err := c.Log.Handler(io.Discard); err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+	if len(c.Sources) == 0 {
+		return errors.New("config: at least one source must be listed")
+	}
+	if _, err := c.NewSources(nil); err != nil {
+		return err
+	}
+	if c.Retry.Attempts < 1 {
+		return errors.New("config: retry.attempts must be at least 1")
+	}
+	if c.Timeouts.Request < 0 || c.Timeouts.Run < 0 || c.Timeouts.Lock < 0 {
+		return errors.New("config: timeouts must not be negative")
+	}
+	if c.Digest.MinNew < 0 || c.Digest.AliveEvery < 0 {
+		return errors.New("config: digest.min_new and digest.alive_every must not be negative")
+	}
+	if c.Reminders.FollowUp < 0 {
+		return errors.New("config: reminders.follow_up must not be negative")
+	}
+	if c.Reminders.FollowUp > 0 {
+		if _, err := c.RemindersNotifier(); err != nil {
+			return err
+		}
+	}
+	if len(c.Notifiers) == 0 && len(c.Subscriptions) == 0 && len(c.Routes) == 0 {
+		return errors.New("config: at least one notifier, route or subscription must be listed")
+	}
+	if _, err := c.Notifier(); err != nil {
+		return err
+	}
+	if _, err := c.AlertNotifier(); err != nil {
+		return err
+	}
+	if _, _, err := c.PushNotifier(); err != nil {
+		return err
+	}
+	if c.Outbox.Enabled && (c.Outbox.Backoff <= 0 || c.Outbox.AlertAfter < 1) {
+		return errors.New("config: outbox.backoff must be positive and outbox.alert_after at least 1")
+	}
+	if _, err := c.OutboxNotifier(); err != nil {
+		return err
+	}
+	if len(c.Anomalies.Notifiers) > 0 && (c.Anomalies.Change <= 0 || c.Anomalies.Window < 1) {
+		return errors.New("config: anomalies.change must be positive and anomalies.window at least 1")
+	}
+	_, err := c.AnomalyNotifier()
+	return err
+}
+
+// Notifier builds the configured notification channels, followed by one
+// notifier per route that goes with the digest and one per subscription.
+func (c *Config) Notifier() (notify.Notifier, error) {
+	var m notify.Multi
+	for _, name := range c.Notifiers {
+		n, err := c.Channel(name)
+		if err != nil {
+			return nil, err
+		}
+		m = append(m, n)
+	}
+	for name := range c.ChannelFilters {
+		if !slices.Contains(c.Notifiers, name) {
+			return nil, fmt.Errorf("config: channel_filters.%s: %q is not in notifiers", name, name)
+		}
+	}
+
+	routes, err := c.routes(false)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range routes {
+		m = append(m, r)
+	}
+
+	for i, sub := range c.Subscriptions {
+		n, err := c.subscriptionNotifier(&sub)
+		if err != nil {
+			return nil, fmt.Errorf("config: subscriptions[%d] (%s): %w", i, sub.Name, err)
+		}
+		m = append(m, n)
+	}
+	return m, nil
+}
+
+// Channel builds the named notification channel, behind its channel filter
+// if it has one.
+func (c *Config) Channel(name string) (notify.Notifier, error) {
+	n, err := c.channel(name, nil)
+	if err != nil {
+		return nil, fmt.Errorf("config: %w", err)
+	}
+	if fc, ok := c.ChannelFilters[name]; ok {
+		f, err := fc.Build()
+		if err != nil {
+			return nil, fmt.Errorf("config: channel_filters.%s: %w", name, err)
+		}
+		n = notify.Filtered{Notifier: n, Filter: f}
+	}
+	return n, nil
+}
+
+// NamedChannel is a channel built by Channels, or the error building it.
+type NamedChannel struct {
+	// Name is the channel's name, prefixed with the subscription's for a
+	// subscription's own, e.g. "alice/email".
+	Name     string
+	Notifier notify.Notifier
+	Err      error
+}
+
+// Channels builds every channel that something sends to, once each: the
+// notifiers, the routes' and the alerts', then each subscription's with
+// its own recipients. They are built without their filters, so that
+// whatever is sent through them arrives.
+func (c *Config) Channels() []NamedChannel {
+	var out []NamedChannel
+	seen := map[string]bool{}
+	var names []string
+	names = append(names, c.Notifiers...)
+	for _, r := range c.Routes {
+		names = append(names, r.Notifiers...)
+	}
+	names = append(names, c.Alerts.Notifiers...)
+	for _, name := range names {
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		n, err := c.channel(name, nil)
+		out = append(out, NamedChannel{Name: name, Notifier: n, Err: err})
+	}
+	for _, sub := range c.Subscriptions {
+		for _, name := range sub.Notifiers {
+			n, err := c.channel(name, &sub)
+			out = append(out, NamedChannel{Name: sub.Name + "/" + name, Notifier: n, Err: err})
+		}
+	}
+	return out
+}
+
+// channel builds the named channel. sub, if not nil, overrides the
+// channel's recipients.
+func (c *Config) channel(name string, sub *Subscription) (notify.Notifier, error) {
+	switch name {
+	case "email":
+		email, err := c.email(sub)
+		if err != nil {
+			return nil, err
+		}
+		return email, nil
+	case "slack":
+		n := c.Slack
+		if sub != nil && sub.Slack != nil {
+			n = *sub.Slack
+		}
+		n.Client = c.client(n.Client)
+		return &n, nil
+	case "discord":
+		n := c.Discord
+		if sub != nil && sub.Discord != nil {
+			n = *sub.Discord
+		}
+		n.Client = c.client(n.Client)
+		return &n, nil
+	case "teams":
+		n := c.Teams
+		if sub != nil && sub.Teams != nil {
+			n = *sub.Teams
+		}
+		n.Client = c.client(n.Client)
+		return &n, nil
+	case "matrix":
+		n := c.Matrix
+		if sub != nil && sub.Matrix != nil {
+			n = *sub.Matrix
+		}
+		n.Client = c.client(n.Client)
+		return &n, nil
+	case "telegram":
+		n := c.Telegram
+		if sub != nil && sub.Telegram != nil {
+			n = *sub.Telegram
+		}
+		n.Client = c.client(n.Client)
+		return &n, nil
+	case "ntfy":
+		n := c.Ntfy
+		if sub != nil && sub.Ntfy != nil {
+			n = *sub.Ntfy
+		}
+		n.Client = c.client(n.Client)
+		return &n, nil
+	case "pushover":
+		n := c.Pushover
+		if sub != nil && sub.Pushover != nil {
+			n = *sub.Pushover
+		}
+		n.Client = c.client(n.Client)
+		return &n, nil
+	case "webhook":
+		n := c.Webhook
+		if sub != nil && sub.Webhook != nil {
+			n = *sub.Webhook
+		}
+		n.Client = c.client(n.Client)
+		return &n, nil
+	case "sheets":
+		n := c.Sheets
+		if sub != nil && sub.Sheets != nil {
+			n = *sub.Sheets
+		}
+		n.Client = c.client(n.Client)
+		return &n, nil
+	case "notion":
+		n := c.Notion
+		if sub != nil && sub.Notion != nil {
+			n = *sub.Notion
+		}
+		n.Client = c.client(n.Client)
+		return &n, nil
+	case "github":
+		n := c.GitHub
+		if sub != nil && sub.GitHub != nil {
+			n = *sub.GitHub
+		}
+		n.Client = c.client(n.Client)
+		return &n, nil
+	case "trello":
+		n := c.Trello
+		if sub != nil && sub.Trello != nil {
+			n = *sub.Trello
+		}
+		n.Client = c.client(n.Client)
+		return &n, nil
+	case "jira":
+		n := c.Jira
+		if sub != nil && sub.Jira != nil {
+			n = *sub.Jira
+		}
+		n.Client = c.client(n.Client)
+		return &n, nil
+	default:
+		return nil, fmt.Errorf("unknown notifier %q", name)
+	}
+}
+
+// EmailNotifier builds the email channel for the top-level recipients.
+func (c *Config) EmailNotifier() (*notify.EmailNotifier, error) {
+	return c.email(nil)
+}
+
+func (c *Config) email(sub *Subscription) (*notify.EmailNotifier, error) {
+	email := c.Email
+	if sub != nil && len(sub.To)+len(sub.Cc)+len(sub.Bcc) > 0 {
+		email.To, email.Cc, email.Bcc = sub.To, sub.Cc, sub.Bcc
+	}
+	email.Timeout = c.Timeouts.Request
+	if c.Outbox.Enabled {
+		email.Outbox = c.Outbox.Queue
+	}
+	if err := email.Validate(); err != nil {
+		return nil, err
+	}
+	email.Links = c.Links()
+	email.Actions = c.Actions.Signer()
+	return &email, nil
+}
+
+// Links returns the careers pages of the sources that have one, listed at
+// the bottom of the email.
+func (c *Config) Links() []notify.Link {
+	var links []notify.Link
+	for _, src := range c.Sources {
+		if name, url := src.Link(); url != "" {
+			links = append(links, notify.Link{Name: name, URL: url})
+		}
+	}
+	return links
+}
+
+// client returns the HTTP client a notifier should use: its own if it has
+// one, otherwise one bounded by Timeouts.Request.
+func (c *Config) client(own *http.Client) *http.Client {
+	if own != nil || c.Timeouts.Request <= 0 {
+		return own
+	}
+	return &http.Client{Timeout: c.Timeouts.Request}
+}
+
+// Pinger returns the heartbeat to ping after each run, or nil if none is
+// configured.
+func (c *Config) Pinger() *heartbeat.Pinger {
+	if c.Heartbeat.URL == "" {
+		return nil
+	}
+	p := c.Heartbeat
+	p.Client = c.client(p.Client)
+	return &p
+}
+
+// Reporter returns the error reporter for Sentry, or nil if it has no DSN.
+func (c *Config) Reporter() errreport.Reporter {
+	if c.Sentry.DSN == "" {
+		return nil
+	}
+	s := c.Sentry
+	s.Client = c.client(s.Client)
+	r, err := s.Reporter()
+	if err != nil {
+		// The DSN was checked on loading.
+		return nil
+	}
+	return r
+}
+
+// Tracer returns the tracer for Tracing, or nil if it has no endpoint.
+func (c *Config) Tracer() *trace.Tracer {
+	tc := c.Tracing
+	tc.Client = c.client(tc.Client)
+	return tc.Tracer()
+}
+
+// GoogleCalendar returns the Calendar settings with the request timeout
+// applied, or nil if no calendar is set.
+func (c *Config) GoogleCalendar() *gcal.Config {
+	if c.Calendar.CalendarID == "" {
+		return nil
+	}
+	gc := c.Calendar
+	gc.Client = c.client(gc.Client)
+	return &gc
+}
+
+// Classifier returns the seniority classifier for LevelRules.
+func (c *Config) Classifier() *level.Classifier {
+	if len(c.LevelRules) == 0 {
+		return level.Default()
+	}
+	return level.NewClassifier(c.LevelRules)
+}
+
+// Scorer returns the scorer for Profile, or nil if no profile is set.
+func (c *Config) Scorer() *score.Scorer {
+	if len(c.Profile) == 0 {
+		return nil
+	}
+	return score.New(c.Profile)
+}
+
+// Fitter returns the fitter for the Resume, caching embeddings in cache, or
+// nil if no resume is set.
+func (c *Config) Fitter(cache fit.Cache) (*fit.Fitter, error) {
+	rc := c.Resume
+	rc.Client = c.client(rc.Client)
+	f, err := rc.New(cache)
+	if err != nil {
+		return nil, fmt.Errorf("config: %w", err)
+	}
+	return f, nil
+}
+
+// ReplyInbox returns Inbox with the login and senders it leaves out taken
+// from the email section, or from the OS keyring where that leaves them
+// out too. The keyring is read, so call it only to poll the inbox.
+func (c *Config) ReplyInbox() inbox.Config {
+	if c.Inbox.Host == "" {
+		return c.replyInbox(&c.Email)
+	}
+	return c.replyInbox(c.Email.WithKeyring())
+}
+
+// replyInbox is ReplyInbox with the email section email.
+func (c *Config) replyInbox(email *notify.EmailNotifier) inbox.Config {
+	ic := c.Inbox
+	if ic.Username == "" {
+		ic.Username = email.Username
+	}
+	if ic.Username == "" {
+		ic.Username = email.From
+	}
+	if ic.Password == "" {
+		ic.Password = email.Password
+	}
+	if len(ic.From) == 0 {
+		ic.From = email.To
+	}
+	ic.Subject = notify.DigestSubject
+	return ic
+}
+
+// Summarizer returns the summarizer for Summary, caching in cache, or nil
+// if no model is set.
+func (c *Config) Summarizer(cache summary.Cache) *summary.Summarizer {
+	if c.Summary.Model == "" {
+		return nil
+	}
+	sc := c.Summary
+	sc.Client = c.client(sc.Client)
+	return summary.New(sc, cache)
+}
+
+// NewSources builds the configured job sources. If stats is non-nil, it
+// counts the pages they fetch.
+func (c *Config) NewSources(stats *scraper.Stats) ([]scraper.Source, error) {
+	opts := scraper.Options{
+		Retry:         c.Retry,
+		DetailWorkers: c.DetailWorkers,
+		UserAgent:     c.UserAgent,
+		Proxy:         c.Proxy,
+		Timeout:       c.Timeouts.Request,
+	}
+	if c.Cache != "" {
+		opts.Cache = &scraper.Cache{Dir: c.Cache}
+	}
+	if c.Archive != "" {
+		opts.Archive = scraper.NewArchive(c.Archive, time.Now())
+	}
+	opts.Replay = c.Replay
+	opts.Stats = stats
+	// Shared, so sources on the same host share its robots.txt and request
+	// spacing.
+	opts.Hosts = scraper.NewHosts(c.Politeness)
+	opts.Factories = map[string]scraper.Factory{"plugin": c.Plugins.SourceFactory()}
+	var sources []scraper.Source
+	for _, sc := range c.Sources {
+		src, err := scraper.NewSource(sc, opts)
+		if err != nil {
+			return nil, fmt.Errorf("config: %w", err)
+		}
+		sources = append(sources, src)
+	}
+	return sources, nil
+}
@@ -0,0 +1,11 @@
+package config
+
+// Debug exposes the Go runtime's profiles (/debug/pprof) and variables
+// (/debug/vars) on the dashboard "jobwatch serve -listen" hosts.
+type Debug struct {
+	Enabled bool `yaml:"enabled"`
+	// Token, if set, must be sent as a bearer token or a token query
+	// parameter. Without one only requests from the machine itself are
+	// answered.
+	Token string `yaml:"token"`
+}
@@ -0,0 +1,25 @@
+package config
+
+import "time"
+
+// Digest decides when a digest is worth sending.
+type Digest struct {
+	// MinNew holds the digest back until at least this many new jobs are
+	// waiting, counted before channel and subscription filters; 1 skips
+	// digests with nothing new. Held jobs, closings included, go out with
+	// the next digest that is sent.
+	MinNew int `yaml:"min_new"`
+	// AliveEvery sends a held digest anyway once none has gone out for this
+	// long, so a quiet inbox still shows the scraper is running. Zero holds
+	// digests for as long as it takes.
+	AliveEvery time.Duration `yaml:"alive_every"`
+}
+
+// Hold reports whether a digest with newJobs new jobs should wait, the
+// last one having been sent at last (zero if none ever was).
+func (d Digest) Hold(newJobs int, last, now time.Time) bool {
+	if newJobs >= d.MinNew {
+		return false
+	}
+	return d.AliveEvery <= 0 || now.Sub(last) < d.AliveEvery
+}
@@ -0,0 +1,61 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/hunterheston/airbnb/pkg/notify"
+)
+
+// maxOutboxBackoff caps the wait between two deliveries of a queued email.
+const maxOutboxBackoff = 24 * time.Hour
+
+// Outbox keeps the emails that couldn't be delivered, digests and reports
+// alike, and retries them on later runs until they go through.
+type Outbox struct {
+	Enabled bool `yaml:"enabled"`
+	// Backoff is how long the first retry waits after a failure. The wait
+	// doubles with every failure, up to a day.
+	Backoff time.Duration `yaml:"backoff"`
+	// AlertAfter is how many failed deliveries of one email it takes for
+	// Notifiers to be told about it.
+	AlertAfter int `yaml:"alert_after"`
+	// Notifiers are the channels told about stuck emails, e.g. "ntfy".
+	Notifiers []string `yaml:"notifiers"`
+
+	// Queue stores the undelivered emails. The program that owns the
+	// database sets it.
+	Queue notify.Outbox `yaml:"-"`
+}
+
+// Delay returns how long to wait before retrying an email that has failed
+// attempts times.
+func (o Outbox) Delay(attempts int) time.Duration {
+	d := o.Backoff
+	for i := 1; i < attempts && d < maxOutboxBackoff; i++ {
+		d *= 2
+	}
+	return min(d, maxOutboxBackoff)
+}
+
+// OutboxNotifier returns the notifier told about stuck emails, or nil if no
+// channels are listed.
+func (c *Config) OutboxNotifier() (notify.Notifier, error) {
+	if len(c.Outbox.Notifiers) == 0 {
+		return nil, nil
+	}
+	if slices.Contains(c.Outbox.Notifiers, "email") {
+		return nil, errors.New("config: outbox: email can't report its own failures")
+	}
+	var m notify.Multi
+	for _, name := range c.Outbox.Notifiers {
+		n, err := c.channel(name, nil)
+		if err != nil {
+			return nil, fmt.Errorf("config: outbox: %w", err)
+		}
+		m = append(m, n)
+	}
+	return m, nil
+}
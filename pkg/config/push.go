@@ -0,0 +1,75 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hunterheston/airbnb/pkg/filter"
+	"github.com/hunterheston/airbnb/pkg/notify"
+	"github.com/hunterheston/airbnb/pkg/scraper"
+)
+
+// Push picks the new jobs worth an immediate notification. A job qualifies
+// if it scores at least MinScore against the profile or its title contains
+// one of Keywords, ignoring case. Every job still goes into the digest.
+type Push struct {
+	// Notifiers are the channels pushed to, typically "ntfy" or
+	// "pushover", configured by their top-level sections.
+	Notifiers []string `yaml:"notifiers"`
+	// MinScore is ignored when 0.
+	MinScore int      `yaml:"min_score"`
+	Keywords []string `yaml:"keywords"`
+}
+
+// Match implements filter.Filter. Jobs must have been scored by then.
+func (p Push) Match(job scraper.JobPosting) bool {
+	if p.MinScore != 0 && job.Score >= p.MinScore {
+		return true
+	}
+	title := strings.ToLower(job.Title)
+	for _, kw := range p.Keywords {
+		if strings.Contains(title, strings.ToLower(kw)) {
+			return true
+		}
+	}
+	return false
+}
+
+// PushNotifier returns the notifier for Push and the routes that push, and
+// the filter of the jobs it pushes, or nils if there are none.
+func (c *Config) PushNotifier() (notify.Notifier, filter.Filter, error) {
+	var m notify.Multi
+	var pushed filter.Any
+	if len(c.Push.Notifiers) > 0 {
+		if c.Push.MinScore == 0 && len(c.Push.Keywords) == 0 {
+			return nil, nil, errors.New("config: push needs min_score or keywords")
+		}
+		if c.Push.MinScore != 0 && len(c.Profile) == 0 {
+			return nil, nil, errors.New("config: push.min_score needs a profile to score jobs against")
+		}
+		var channels notify.Multi
+		for _, name := range c.Push.Notifiers {
+			n, err := c.channel(name, nil)
+			if err != nil {
+				return nil, nil, fmt.Errorf("config: push: %w", err)
+			}
+			channels = append(channels, n)
+		}
+		m = append(m, notify.Filtered{Notifier: channels, Filter: c.Push})
+		pushed = append(pushed, c.Push)
+	}
+
+	routes, err := c.routes(true)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, r := range routes {
+		m = append(m, r)
+		pushed = append(pushed, r.Filter)
+	}
+	if len(m) == 0 {
+		return nil, nil, nil
+	}
+	return m, pushed, nil
+}
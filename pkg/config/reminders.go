@@ -0,0 +1,36 @@
+package config
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hunterheston/airbnb/pkg/notify"
+)
+
+// Reminders nudge you to follow up on applications that have gone quiet.
+type Reminders struct {
+	// FollowUp is how long an application may wait on the employer,
+	// applied or after a phone screen or onsite, before you are reminded to
+	// follow up, once per stage. Zero turns reminders off.
+	FollowUp time.Duration `yaml:"follow_up"`
+	// Notifiers receive the reminders. They default to the top-level
+	// notifiers.
+	Notifiers []string `yaml:"notifiers"`
+}
+
+// RemindersNotifier returns the notifier that reminders go to.
+func (c *Config) RemindersNotifier() (notify.Notifier, error) {
+	names := c.Reminders.Notifiers
+	if len(names) == 0 {
+		names = c.Notifiers
+	}
+	var m notify.Multi
+	for _, name := range names {
+		n, err := c.channel(name, nil)
+		if err != nil {
+			return nil, fmt.Errorf("config: reminders: %w", err)
+		}
+		m = append(m, n)
+	}
+	return m, nil
+}
@@ -0,0 +1,66 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/hunterheston/airbnb/pkg/filter"
+	"github.com/hunterheston/airbnb/pkg/notify"
+)
+
+// Route sends the jobs its filter matches to some channels, on top of the
+// main filter: with the digest, or with Push as soon as a scrape finds
+// them. A job takes every route that matches it, so one route can send the
+// standouts to ntfy while another sends everything to a Google Sheet.
+type Route struct {
+	// Name identifies the route in errors.
+	Name   string        `yaml:"name"`
+	Filter filter.Config `yaml:"filter"`
+	// Notifiers are the channels the jobs go to, configured by their
+	// top-level sections.
+	Notifiers []string `yaml:"notifiers"`
+	// Push sends the jobs the moment a scrape finds them rather than with
+	// the digest. They still go into the digest of the other routes.
+	Push bool `yaml:"push"`
+}
+
+// routeNotifier sends the jobs matching r's filter to r's channels.
+func (c *Config) routeNotifier(r *Route) (notify.Filtered, error) {
+	if len(r.Notifiers) == 0 {
+		return notify.Filtered{}, errors.New("no notifiers listed")
+	}
+	if r.Filter.MinScore > 0 && len(c.Profile) == 0 {
+		return notify.Filtered{}, errors.New("filter.min_score needs a profile to score jobs against")
+	}
+	f, err := r.Filter.Build()
+	if err != nil {
+		return notify.Filtered{}, fmt.Errorf("filter: %w", err)
+	}
+
+	var m notify.Multi
+	for _, name := range r.Notifiers {
+		n, err := c.channel(name, nil)
+		if err != nil {
+			return notify.Filtered{}, err
+		}
+		m = append(m, n)
+	}
+	return notify.Filtered{Notifier: m, Filter: f}, nil
+}
+
+// routes builds the notifiers of the routes that push, if push is set, or
+// else of the ones that go with the digest.
+func (c *Config) routes(push bool) ([]notify.Filtered, error) {
+	var routes []notify.Filtered
+	for i, r := range c.Routes {
+		if r.Push != push {
+			continue
+		}
+		n, err := c.routeNotifier(&r)
+		if err != nil {
+			return nil, fmt.Errorf("config: routes[%d] (%s): %w", i, r.Name, err)
+		}
+		routes = append(routes, n)
+	}
+	return routes, nil
+}
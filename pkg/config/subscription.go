@@ -0,0 +1,65 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/hunterheston/airbnb/pkg/filter"
+	"github.com/hunterheston/airbnb/pkg/notify"
+)
+
+// Subscription is one person's digest. Its filter applies on top of the
+// main one, which decides what gets stored at all, so with subscriptions
+// the main filter usually stays broad.
+type Subscription struct {
+	// Name identifies the subscription in errors.
+	Name   string        `yaml:"name"`
+	Filter filter.Config `yaml:"filter"`
+	// Notifiers are the channels this subscription is sent to, configured
+	// by the top-level sections unless overridden below.
+	Notifiers []string `yaml:"notifiers"`
+
+	// To, Cc and Bcc replace email's recipients when any of them is set,
+	// keeping the rest of the email settings.
+	To  []string `yaml:"to"`
+	Cc  []string `yaml:"cc"`
+	Bcc []string `yaml:"bcc"`
+	// Slack, Discord, Teams, Matrix, Telegram, Ntfy, Pushover, Webhook,
+	// Sheets, Notion, GitHub, Trello and Jira replace the top-level
+	// sections.
+	Slack    *notify.SlackNotifier    `yaml:"slack"`
+	Discord  *notify.DiscordNotifier  `yaml:"discord"`
+	Teams    *notify.TeamsNotifier    `yaml:"teams"`
+	Matrix   *notify.MatrixNotifier   `yaml:"matrix"`
+	Telegram *notify.TelegramNotifier `yaml:"telegram"`
+	Ntfy     *notify.NtfyNotifier     `yaml:"ntfy"`
+	Pushover *notify.PushoverNotifier `yaml:"pushover"`
+	Webhook  *notify.WebhookNotifier  `yaml:"webhook"`
+	Sheets   *notify.SheetsNotifier   `yaml:"sheets"`
+	Notion   *notify.NotionNotifier   `yaml:"notion"`
+	GitHub   *notify.GitHubNotifier   `yaml:"github"`
+	Trello   *notify.TrelloNotifier   `yaml:"trello"`
+	Jira     *notify.JiraNotifier     `yaml:"jira"`
+}
+
+// subscriptionNotifier sends the jobs matching sub's filter to sub's
+// channels.
+func (c *Config) subscriptionNotifier(sub *Subscription) (notify.Notifier, error) {
+	if len(sub.Notifiers) == 0 {
+		return nil, errors.New("no notifiers listed")
+	}
+	f, err := sub.Filter.Build()
+	if err != nil {
+		return nil, fmt.Errorf("filter: %w", err)
+	}
+
+	var m notify.Multi
+	for _, name := range sub.Notifiers {
+		n, err := c.channel(name, sub)
+		if err != nil {
+			return nil, err
+		}
+		m = append(m, n)
+	}
+	return notify.Filtered{Notifier: m, Filter: f}, nil
+}
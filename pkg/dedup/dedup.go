@@ -0,0 +1,111 @@
+// Package dedup collapses the postings of one opening listed in several
+// locations, as Airbnb does, into a single digest entry.
+package dedup
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/hunterheston/airbnb/pkg/scraper"
+)
+
+// dashes are the ways a title separates its parts.
+var dashes = strings.NewReplacer("–", "-", "—", "-", "‐", "-")
+
+// suffix matches the last part of a title, e.g. the location in
+// "Software Engineer - San Francisco" or "Software Engineer (Remote)".
+var suffix = regexp.MustCompile(`\s*(?:\(([^()]*)\)|[-,|]\s*([^-,|]*))$`)
+
+// NormalizeTitle returns the comparable form of a job's title: lower case,
+// with single spaces, and without a trailing part that only names the
+// location, since some sites repeat it in the title.
+func NormalizeTitle(title, location string) string {
+	t := strings.ToLower(strings.Join(strings.Fields(dashes.Replace(title)), " "))
+	loc := strings.ToLower(location)
+	for {
+		m := suffix.FindStringSubmatchIndex(t)
+		if m == nil || m[0] == 0 {
+			return t
+		}
+		part := ""
+		if m[2] >= 0 {
+			part = t[m[2]:m[3]]
+		} else {
+			part = t[m[4]:m[5]]
+		}
+		part = strings.TrimSpace(part)
+		if part == "" || !(part == "remote" || part == "hybrid" || strings.Contains(loc, part)) {
+			return t
+		}
+		t = t[:m[0]]
+	}
+}
+
+// Key is what jobs that are one opening have in common: the company, the
+// normalized title and the requisition ID. Without a requisition ID the
+// team stands in for it, so two openings with the same title in different
+// teams stay apart.
+func Key(job scraper.JobPosting) string {
+	id := job.Requisition
+	if id == "" {
+		id = "team:" + strings.ToLower(job.Team)
+	}
+	return job.Company + "\x00" + NormalizeTitle(job.Title, job.Location) + "\x00" + id
+}
+
+// Collapse merges the jobs that share a Key into the first of them, whose
+// location becomes every location of the group joined with " / ". The
+// result keeps the order of first appearance; jobs isn't modified.
+func Collapse(jobs []scraper.JobPosting) []scraper.JobPosting {
+	var out []scraper.JobPosting
+	index := map[string]int{}
+	for _, job := range jobs {
+		key := Key(job)
+		i, ok := index[key]
+		if !ok {
+			index[key] = len(out)
+			out = append(out, job)
+			continue
+		}
+		out[i].Location = joinLocations(out[i].Location, job.Location)
+		out[i].Score = max(out[i].Score, job.Score)
+		out[i].Fit = max(out[i].Fit, job.Fit)
+		out[i].Interest = max(out[i].Interest, job.Interest)
+		out[i].Tags = joinTags(out[i].Tags, job.Tags)
+	}
+	return out
+}
+
+// joinTags adds the tags of more missing from tags, keeping them sorted.
+func joinTags(tags, more []string) []string {
+	var added []string
+	for _, tag := range more {
+		if !slices.Contains(tags, tag) {
+			added = append(added, tag)
+		}
+	}
+	if len(added) == 0 {
+		return tags
+	}
+	tags = append(slices.Clone(tags), added...)
+	slices.Sort(tags)
+	return tags
+}
+
+// joinLocations adds loc to the " / "-separated list in locs unless it is
+// already there.
+func joinLocations(locs, loc string) string {
+	if loc == "" {
+		return locs
+	}
+	if locs == "" {
+		return loc
+	}
+	for _, l := range strings.Split(locs, " / ") {
+		if strings.EqualFold(l, loc) {
+			return locs
+		}
+	}
+	return locs + " / " + loc
+}
@@ -0,0 +1,67 @@
+// Package errreport sends errors and panics, with the stack they were
+// caught at and what the program was doing, to an error tracker such as
+// Sentry, where they are grouped and kept rather than lost in cron mail.
+package errreport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+)
+
+// Reporter sends events to an error tracker.
+type Reporter interface {
+	Report(ctx context.Context, e Event) error
+}
+
+// Event is an error or panic to report.
+type Event struct {
+	Err error
+	// Panic marks an error recovered from a panic, which ended what it
+	// interrupted.
+	Panic bool
+	// Stack is where it happened, as runtime.Callers returns it.
+	Stack []uintptr
+	// Tags are short values to search and group events by, e.g. the
+	// command and source.
+	Tags map[string]string
+	// Extra is further context, e.g. a run's counts.
+	Extra map[string]any
+}
+
+// New returns the event for err, with the stack from skip frames above the
+// function calling New.
+func New(err error, skip int) Event {
+	return Event{Err: err, Stack: callers(skip + 1)}
+}
+
+// Recovered returns the event for the value v a deferred function recovered
+// from a panic, with the stack of the panic. skip counts frames up from the
+// deferred function, as for New.
+func Recovered(v any, skip int) Event {
+	err, ok := v.(error)
+	if !ok {
+		err = fmt.Errorf("%v", v)
+	}
+	return Event{Err: err, Panic: true, Stack: callers(skip + 1)}
+}
+
+// callers returns the stack from skip frames above its caller.
+func callers(skip int) []uintptr {
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(skip+2, pcs)
+	return pcs[:n]
+}
+
+// cause returns the innermost error err wraps, whose type best tells
+// errors apart.
+func cause(err error) error {
+	for {
+		next := errors.Unwrap(err)
+		if next == nil {
+			return err
+		}
+		err = next
+	}
+}
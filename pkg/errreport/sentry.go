@@ -0,0 +1,108 @@
+package errreport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime"
+	"slices"
+
+	"github.com/getsentry/sentry-go"
+)
+
+// Sentry is the configuration of a Sentry project events are reported to;
+// self-hosted Sentry and compatible trackers such as GlitchTip work too.
+type Sentry struct {
+	// DSN is the project's client key, e.g.
+	// https://<key>@o123.ingest.sentry.io/456. Without one nothing is
+	// reported.
+	DSN string `yaml:"dsn"`
+	// Environment tells deployments apart, e.g. "production".
+	Environment string `yaml:"environment"`
+
+	Client *http.Client `yaml:"-"`
+}
+
+// Validate checks s.
+func (s *Sentry) Validate() error {
+	if s.DSN == "" {
+		return nil
+	}
+	if _, err := sentry.NewDsn(s.DSN); err != nil {
+		return fmt.Errorf("sentry: dsn must look like https://<key>@<host>/<project>: %w", err)
+	}
+	return nil
+}
+
+// Reporter returns the Reporter that sends events to s's project. Each
+// event is sent before Report returns, bounded by the Client's timeout.
+func (s *Sentry) Reporter() (Reporter, error) {
+	client, err := sentry.NewClient(sentry.ClientOptions{
+		Dsn:         s.DSN,
+		Environment: s.Environment,
+		HTTPClient:  s.Client,
+		Transport:   sentry.NewHTTPSyncTransport(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("sentry: %w", err)
+	}
+	return &sentryReporter{client}, nil
+}
+
+type sentryReporter struct {
+	client *sentry.Client
+}
+
+// Report implements Reporter.
+func (r *sentryReporter) Report(ctx context.Context, e Event) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if r.client.CaptureEvent(sentryEvent(e), nil, nil) == nil {
+		return errors.New("sentry: the event was dropped")
+	}
+	return nil
+}
+
+// sentryEvent converts e to the SDK's event.
+func sentryEvent(e Event) *sentry.Event {
+	ev := sentry.NewEvent()
+	ev.Level = sentry.LevelError
+	ev.Logger = "jobwatch"
+	ev.Tags = e.Tags
+	ev.Extra = e.Extra
+
+	ex := sentry.Exception{
+		Type:       fmt.Sprintf("%T", cause(e.Err)),
+		Value:      e.Err.Error(),
+		Stacktrace: stacktrace(e.Stack),
+		Mechanism:  &sentry.Mechanism{Type: "generic", Handled: sentry.Pointer(true)},
+	}
+	if e.Panic {
+		ev.Level = sentry.LevelFatal
+		ex.Type = "panic"
+		ex.Mechanism.Type = "panic"
+		ex.Mechanism.SetUnhandled()
+	}
+	ev.Exception = []sentry.Exception{ex}
+	return ev
+}
+
+// stacktrace converts a stack from runtime.Callers to Sentry's, which
+// lists frames oldest first.
+func stacktrace(pcs []uintptr) *sentry.Stacktrace {
+	var frames []sentry.Frame
+	callers := runtime.CallersFrames(pcs)
+	for {
+		f, more := callers.Next()
+		if f.Function != "" {
+			frames = append(frames, sentry.NewFrame(f))
+		}
+		if !more {
+			break
+		}
+	}
+	slices.Reverse(frames)
+	return &sentry.Stacktrace{Frames: frames}
+}
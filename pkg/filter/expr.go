@@ -0,0 +1,292 @@
+package filter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/hunterheston/airbnb/pkg/scraper"
+)
+
+// Expr is a Filter compiled from a boolean expression such as
+//
+//	title ~ "Software Engineer" AND NOT title ~ "(Senior|Staff|Sr\.|Principal)"
+//
+// Comparisons take the form <field> <op> "<string>", where field is one of
+// title, company, location, team, level, description or url, and op is one of
+//
+//	~          matches the regular expression (RE2 syntax; use (?i) to ignore case)
+//	!~         doesn't match the regular expression
+//	==, !=     equals, ignoring case
+//	contains   contains the substring, ignoring case
+//
+// Comparisons combine with AND, OR, NOT and parentheses; NOT binds tightest
+// and AND binds tighter than OR. Keywords are case-insensitive. Inside a
+// string, \" is a literal quote; every other backslash is kept as written so
+// regular expression escapes like \. work unchanged.
+type Expr struct {
+	src  string
+	root node
+}
+
+// ParseExpr compiles an expression.
+func ParseExpr(src string) (*Expr, error) {
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, fmt.Errorf("filter expression: %w", err)
+	}
+	p := &parser{tokens: tokens}
+	root, err := p.parseOr()
+	if err == nil && !p.done() {
+		err = fmt.Errorf("unexpected %s", p.peek())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("filter expression: %w", err)
+	}
+	return &Expr{src: src, root: root}, nil
+}
+
+// String returns the source expression.
+func (e *Expr) String() string {
+	return e.src
+}
+
+// Match implements Filter.
+func (e *Expr) Match(job scraper.JobPosting) bool {
+	return e.root.eval(job)
+}
+
+// fields maps field names to accessors.
+var fields = map[string]func(scraper.JobPosting) string{
+	"title":       func(j scraper.JobPosting) string { return j.Title },
+	"company":     func(j scraper.JobPosting) string { return j.Company },
+	"location":    func(j scraper.JobPosting) string { return j.Location },
+	"team":        func(j scraper.JobPosting) string { return j.Team },
+	"level":       func(j scraper.JobPosting) string { return j.Level },
+	"description": func(j scraper.JobPosting) string { return j.Description },
+	"url":         func(j scraper.JobPosting) string { return j.URL },
+}
+
+type node interface {
+	eval(job scraper.JobPosting) bool
+}
+
+type andNode struct{ left, right node }
+type orNode struct{ left, right node }
+type notNode struct{ operand node }
+
+type cmpNode struct {
+	field func(scraper.JobPosting) string
+	// match reports whether the field value satisfies the comparison.
+	match func(value string) bool
+}
+
+func (n andNode) eval(j scraper.JobPosting) bool { return n.left.eval(j) && n.right.eval(j) }
+func (n orNode) eval(j scraper.JobPosting) bool  { return n.left.eval(j) || n.right.eval(j) }
+func (n notNode) eval(j scraper.JobPosting) bool { return !n.operand.eval(j) }
+func (n cmpNode) eval(j scraper.JobPosting) bool { return n.match(n.field(j)) }
+
+type tokenKind int
+
+const (
+	tokIdent tokenKind = iota
+	tokString
+	tokOp
+	tokLParen
+	tokRParen
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+func (t token) String() string {
+	if t.kind == tokString {
+		return fmt.Sprintf("string %q at offset %d", t.text, t.pos)
+	}
+	return fmt.Sprintf("%q at offset %d", t.text, t.pos)
+}
+
+func lex(src string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(':
+			tokens = append(tokens, token{tokLParen, "(", i})
+			i++
+		case c == ')':
+			tokens = append(tokens, token{tokRParen, ")", i})
+			i++
+		case c == '~':
+			tokens = append(tokens, token{tokOp, "~", i})
+			i++
+		case strings.HasPrefix(src[i:], "!~"), strings.HasPrefix(src[i:], "!="), strings.HasPrefix(src[i:], "=="):
+			tokens = append(tokens, token{tokOp, src[i : i+2], i})
+			i += 2
+		case c == '"':
+			start := i
+			var sb strings.Builder
+			for i++; ; i++ {
+				if i >= len(src) {
+					return nil, fmt.Errorf("unterminated string at offset %d", start)
+				}
+				if src[i] == '\\' && i+1 < len(src) && src[i+1] == '"' {
+					sb.WriteByte('"')
+					i++
+					continue
+				}
+				if src[i] == '"' {
+					i++
+					break
+				}
+				sb.WriteByte(src[i])
+			}
+			tokens = append(tokens, token{tokString, sb.String(), start})
+		case unicode.IsLetter(rune(c)) || c == '_':
+			start := i
+			for i < len(src) && (unicode.IsLetter(rune(src[i])) || src[i] == '_') {
+				i++
+			}
+			tokens = append(tokens, token{tokIdent, src[start:i], start})
+		default:
+			return nil, fmt.Errorf("unexpected %q at offset %d", c, i)
+		}
+	}
+	return tokens, nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) done() bool { return p.pos >= len(p.tokens) }
+
+func (p *parser) peek() token { return p.tokens[p.pos] }
+
+// keyword consumes the next token if it is the given keyword.
+func (p *parser) keyword(kw string) bool {
+	if !p.done() && p.peek().kind == tokIdent && strings.EqualFold(p.peek().text, kw) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("OR") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("AND") {
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = andNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseNot() (node, error) {
+	if p.keyword("NOT") {
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{operand}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	if p.done() {
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+
+	tok := p.peek()
+	if tok.kind == tokLParen {
+		p.pos++
+		n, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.done() || p.peek().kind != tokRParen {
+			return nil, fmt.Errorf("missing ) for ( at offset %d", tok.pos)
+		}
+		p.pos++
+		return n, nil
+	}
+
+	if tok.kind != tokIdent {
+		return nil, fmt.Errorf("expected a field name, got %s", tok)
+	}
+	field, ok := fields[strings.ToLower(tok.text)]
+	if !ok {
+		return nil, fmt.Errorf("unknown field %s", tok)
+	}
+	p.pos++
+
+	if p.done() {
+		return nil, fmt.Errorf("expected an operator after %s", tok)
+	}
+	op := p.peek()
+	if op.kind != tokOp && !(op.kind == tokIdent && strings.EqualFold(op.text, "contains")) {
+		return nil, fmt.Errorf("expected an operator, got %s", op)
+	}
+	p.pos++
+
+	if p.done() || p.peek().kind != tokString {
+		return nil, fmt.Errorf("expected a quoted string after %s", op)
+	}
+	arg := p.peek()
+	p.pos++
+
+	match, err := comparison(strings.ToLower(op.text), arg.text)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", arg, err)
+	}
+	return cmpNode{field: field, match: match}, nil
+}
+
+func comparison(op, arg string) (func(string) bool, error) {
+	switch op {
+	case "~", "!~":
+		re, err := regexp.Compile(arg)
+		if err != nil {
+			return nil, err
+		}
+		if op == "!~" {
+			return func(v string) bool { return !re.MatchString(v) }, nil
+		}
+		return re.MatchString, nil
+	case "==":
+		return func(v string) bool { return strings.EqualFold(v, arg) }, nil
+	case "!=":
+		return func(v string) bool { return !strings.EqualFold(v, arg) }, nil
+	default: // contains
+		lower := strings.ToLower(arg)
+		return func(v string) bool { return strings.Contains(strings.ToLower(v), lower) }, nil
+	}
+}
@@ -0,0 +1,155 @@
+// Package filter decides which job postings are worth reporting.
+package filter
+
+import (
+	"strings"
+	"time"
+
+	"github.com/hunterheston/airbnb/pkg/scraper"
+)
+
+// Filter reports whether a job should be kept.
+type Filter interface {
+	Match(job scraper.JobPosting) bool
+}
+
+// KeywordFilter matches titles that contain every Include keyword and none
+// of the Exclude keywords.
+type KeywordFilter struct {
+	Include []string
+	Exclude []string
+}
+
+// MidLevelSoftwareEngineer matches junior and mid-level Software Engineer
+// positions, leaving out mobile roles.
+func MidLevelSoftwareEngineer() Config {
+	return Config{
+		Include: []string{"Software Engineer"},
+		Exclude: []string{"Android", "iOS"},
+		Levels:  []string{"junior", "mid"},
+	}
+}
+
+// Match implements Filter.
+func (f KeywordFilter) Match(job scraper.JobPosting) bool {
+	for _, kw := range f.Include {
+		if !strings.Contains(job.Title, kw) {
+			return false
+		}
+	}
+	for _, kw := range f.Exclude {
+		if strings.Contains(job.Title, kw) {
+			return false
+		}
+	}
+	return true
+}
+
+// All matches jobs that every filter matches.
+type All []Filter
+
+// Match implements Filter.
+func (a All) Match(job scraper.JobPosting) bool {
+	for _, f := range a {
+		if !f.Match(job) {
+			return false
+		}
+	}
+	return true
+}
+
+// Any matches jobs that at least one filter matches.
+type Any []Filter
+
+// Match implements Filter.
+func (a Any) Match(job scraper.JobPosting) bool {
+	for _, f := range a {
+		if f.Match(job) {
+			return true
+		}
+	}
+	return false
+}
+
+// Config is the filter section of the config file. Jobs must pass the
+// keyword lists, the locations, the levels, the salary, the score, the
+// tags, the posting date, the expression and the script, when set.
+type Config struct {
+	Include []string `yaml:"include"`
+	Exclude []string `yaml:"exclude"`
+	// Locations keeps jobs in any of these places; see LocationFilter.
+	Locations []string `yaml:"locations"`
+	// Levels keeps jobs of these seniority levels, e.g. "mid"; see
+	// LevelFilter.
+	Levels []string `yaml:"levels"`
+	// MinSalary keeps jobs paying at least this much a year; see
+	// SalaryFilter.
+	MinSalary int `yaml:"min_salary"`
+	// MinScore keeps jobs scoring at least this much against the profile;
+	// see ScoreFilter.
+	MinScore int `yaml:"min_score"`
+	// Tags keeps jobs tagged with any of these technologies, and
+	// ExcludeTags drops those tagged with any of those; see TagFilter.
+	Tags        []string `yaml:"tags"`
+	ExcludeTags []string `yaml:"exclude_tags"`
+	// PostedWithin keeps jobs posted this recently, e.g. "7d"; see
+	// PostedFilter.
+	PostedWithin Days `yaml:"posted_within"`
+	// Expression is parsed with ParseExpr.
+	Expression string `yaml:"expression"`
+	// Script is a CEL expression, parsed with ParseScript.
+	Script string `yaml:"script"`
+}
+
+// Build compiles the configured filter.
+func (c Config) Build() (Filter, error) {
+	all := All{KeywordFilter{Include: c.Include, Exclude: c.Exclude}}
+	if len(c.Locations) > 0 {
+		all = append(all, NewLocationFilter(c.Locations))
+	}
+	if len(c.Levels) > 0 {
+		f, err := NewLevelFilter(c.Levels)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, f)
+	}
+	if c.MinSalary > 0 {
+		all = append(all, SalaryFilter{Min: c.MinSalary})
+	}
+	if c.MinScore > 0 {
+		all = append(all, ScoreFilter{Min: c.MinScore})
+	}
+	if len(c.Tags) > 0 || len(c.ExcludeTags) > 0 {
+		all = append(all, TagFilter{Tags: c.Tags, Exclude: c.ExcludeTags})
+	}
+	if c.PostedWithin > 0 {
+		all = append(all, PostedFilter{Within: time.Duration(c.PostedWithin)})
+	}
+	if c.Expression != "" {
+		expr, err := ParseExpr(c.Expression)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, expr)
+	}
+	if c.Script != "" {
+		script, err := ParseScript(c.Script)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, script)
+	}
+	return all, nil
+}
+
+// Apply returns the jobs that match f, preserving order.
+func Apply(f Filter, jobs []scraper.JobPosting) []scraper.JobPosting {
+	var kept []scraper.JobPosting
+	for _, job := range jobs {
+		if f.Match(job) {
+			kept = append(kept, job)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,36 @@
+package filter
+
+import (
+	"slices"
+
+	"github.com/hunterheston/airbnb/pkg/level"
+	"github.com/hunterheston/airbnb/pkg/scraper"
+)
+
+// LevelFilter keeps jobs at one of Levels. Jobs that haven't been
+// classified yet are classified with the default rules.
+type LevelFilter struct {
+	Levels []level.Level
+}
+
+// NewLevelFilter parses level names such as "junior" or "staff+".
+func NewLevelFilter(names []string) (LevelFilter, error) {
+	var f LevelFilter
+	for _, name := range names {
+		l, err := level.Parse(name)
+		if err != nil {
+			return LevelFilter{}, err
+		}
+		f.Levels = append(f.Levels, l)
+	}
+	return f, nil
+}
+
+// Match implements Filter.
+func (f LevelFilter) Match(job scraper.JobPosting) bool {
+	l, err := level.Parse(job.Level)
+	if err != nil {
+		l = level.Default().Classify(job.Title)
+	}
+	return slices.Contains(f.Levels, l)
+}
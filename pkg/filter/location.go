@@ -0,0 +1,38 @@
+package filter
+
+import (
+	"github.com/hunterheston/airbnb/pkg/location"
+	"github.com/hunterheston/airbnb/pkg/scraper"
+)
+
+// LocationFilter keeps jobs offered in at least one of Locations, e.g.
+// "Remote", "San Francisco" or "CA". Jobs whose source doesn't report a
+// location are kept, since nothing says they are elsewhere.
+type LocationFilter struct {
+	Locations []location.Place
+}
+
+// NewLocationFilter parses each of terms as a location.
+func NewLocationFilter(terms []string) LocationFilter {
+	var f LocationFilter
+	for _, term := range terms {
+		f.Locations = append(f.Locations, location.Parse(term)...)
+	}
+	return f
+}
+
+// Match implements Filter.
+func (f LocationFilter) Match(job scraper.JobPosting) bool {
+	places := location.Parse(job.Location)
+	if len(f.Locations) == 0 || len(places) == 0 {
+		return true
+	}
+	for _, want := range f.Locations {
+		for _, p := range places {
+			if want.Contains(p) {
+				return true
+			}
+		}
+	}
+	return false
+}
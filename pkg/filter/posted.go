@@ -0,0 +1,47 @@
+package filter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hunterheston/airbnb/pkg/scraper"
+)
+
+// PostedFilter keeps jobs posted within the last Within. Jobs without a
+// posting date are kept, since nothing says they are old.
+type PostedFilter struct {
+	Within time.Duration
+}
+
+// Match implements Filter.
+func (f PostedFilter) Match(job scraper.JobPosting) bool {
+	return job.PostedAt == nil || time.Since(*job.PostedAt) <= f.Within
+}
+
+// Days is a duration written in days or weeks, as in "7d" or "2w", or as a
+// Go duration such as "36h".
+type Days time.Duration
+
+// UnmarshalText implements encoding.TextUnmarshaler, which the YAML decoder
+// uses for scalars.
+func (d *Days) UnmarshalText(text []byte) error {
+	s := strings.TrimSpace(string(text))
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if n, ok := strings.CutSuffix(s, suffix); ok {
+			days, err := strconv.Atoi(n)
+			if err != nil {
+				return fmt.Errorf("invalid duration %q", s)
+			}
+			*d = Days(time.Duration(days) * unit)
+			return nil
+		}
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return fmt.Errorf("invalid duration %q (want e.g. 7d, 2w or 36h)", s)
+	}
+	*d = Days(v)
+	return nil
+}
@@ -0,0 +1,14 @@
+package filter
+
+import "github.com/hunterheston/airbnb/pkg/scraper"
+
+// SalaryFilter keeps jobs whose pay range reaches Min dollars a year. Jobs
+// that don't state a range are kept, since nothing says they pay less.
+type SalaryFilter struct {
+	Min int
+}
+
+// Match implements Filter.
+func (f SalaryFilter) Match(job scraper.JobPosting) bool {
+	return job.SalaryMax == 0 || job.SalaryMax >= f.Min
+}
@@ -0,0 +1,14 @@
+package filter
+
+import "github.com/hunterheston/airbnb/pkg/scraper"
+
+// ScoreFilter keeps jobs scoring at least Min against the interests
+// profile. Jobs must have been scored by then.
+type ScoreFilter struct {
+	Min int
+}
+
+// Match implements Filter.
+func (f ScoreFilter) Match(job scraper.JobPosting) bool {
+	return job.Score >= f.Min
+}
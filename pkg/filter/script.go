@@ -0,0 +1,101 @@
+package filter
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/ext"
+
+	"github.com/hunterheston/airbnb/pkg/scraper"
+)
+
+// Script is a Filter compiled from a CEL expression (https://cel.dev), for
+// conditions Expr can't state, such as
+//
+//	level == "mid" && (contains(description, "Go") || score > 7) && salary_min >= 150000
+//
+// The job's fields are variables: title, company, location, team, level,
+// description, url and requisition are strings; salary_min, salary_max,
+// score and age_days, the days since the job was posted or -1 if that isn't
+// known, are ints; tags is a list of strings, as in "Go" in tags.
+// contains(s, sub) ignores case. CEL's own string methods, such as
+// s.contains(sub), s.startsWith(prefix) and s.matches(re), are available
+// too, as are the extended ones like s.lowerAscii().
+type Script struct {
+	src string
+	prg cel.Program
+}
+
+// scriptInts are a script's int variables; every other variable is a string.
+var scriptInts = []string{"salary_min", "salary_max", "score", "age_days"}
+
+// ParseScript compiles a script. It must evaluate to a bool.
+func ParseScript(src string) (*Script, error) {
+	opts := []cel.EnvOption{
+		ext.Strings(),
+		cel.CrossTypeNumericComparisons(true),
+		cel.Function("contains", cel.Overload("contains_string_string_fold",
+			[]*cel.Type{cel.StringType, cel.StringType}, cel.BoolType,
+			cel.BinaryBinding(func(s, sub ref.Val) ref.Val {
+				return types.Bool(strings.Contains(strings.ToLower(string(s.(types.String))), strings.ToLower(string(sub.(types.String)))))
+			}))),
+	}
+	for name := range fields {
+		opts = append(opts, cel.Variable(name, cel.StringType))
+	}
+	opts = append(opts, cel.Variable("requisition", cel.StringType))
+	for _, name := range scriptInts {
+		opts = append(opts, cel.Variable(name, cel.IntType))
+	}
+	opts = append(opts, cel.Variable("tags", cel.ListType(cel.StringType)))
+	env, err := cel.NewEnv(opts...)
+	if err != nil {
+		return nil, fmt.Errorf("filter script: %w", err)
+	}
+	ast, iss := env.Compile(src)
+	if iss.Err() != nil {
+		return nil, fmt.Errorf("filter script: %w", iss.Err())
+	}
+	if ast.OutputType() != cel.BoolType {
+		return nil, fmt.Errorf("filter script: evaluates to %s, not bool", ast.OutputType())
+	}
+	prg, err := env.Program(ast)
+	if err != nil {
+		return nil, fmt.Errorf("filter script: %w", err)
+	}
+	return &Script{src: src, prg: prg}, nil
+}
+
+// String returns the source script.
+func (s *Script) String() string {
+	return s.src
+}
+
+// Match implements Filter. A job the script fails on, say with a division
+// by zero, doesn't match.
+func (s *Script) Match(job scraper.JobPosting) bool {
+	vars := map[string]any{
+		"requisition": job.Requisition,
+		"salary_min":  job.SalaryMin,
+		"salary_max":  job.SalaryMax,
+		"score":       job.Score,
+		"age_days":    -1,
+		"tags":        job.Tags,
+	}
+	for name, get := range fields {
+		vars[name] = get(job)
+	}
+	if job.PostedAt != nil {
+		vars["age_days"] = max(int(time.Since(*job.PostedAt).Hours()/24), 0)
+	}
+	out, _, err := s.prg.Eval(vars)
+	if err != nil {
+		return false
+	}
+	match, _ := out.Value().(bool)
+	return match
+}
@@ -0,0 +1,31 @@
+package filter
+
+import (
+	"github.com/hunterheston/airbnb/pkg/scraper"
+	"github.com/hunterheston/airbnb/pkg/tags"
+)
+
+// TagFilter keeps jobs tagged with any of Tags, when set, and with none of
+// Exclude, ignoring case. Jobs must have been tagged by then.
+type TagFilter struct {
+	Tags    []string
+	Exclude []string
+}
+
+// Match implements Filter.
+func (f TagFilter) Match(job scraper.JobPosting) bool {
+	for _, tag := range f.Exclude {
+		if tags.Has(job.Tags, tag) {
+			return false
+		}
+	}
+	if len(f.Tags) == 0 {
+		return true
+	}
+	for _, tag := range f.Tags {
+		if tags.Has(job.Tags, tag) {
+			return true
+		}
+	}
+	return false
+}
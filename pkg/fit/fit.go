@@ -0,0 +1,214 @@
+// Package fit rates how well each job fits a resume: the resume and the
+// job descriptions are embedded as vectors by a Provider, and a job's fit
+// is the cosine similarity of its vector with the resume's, as a
+// percentage.
+//
+// Embeddings are cached by text, so a description is embedded once however
+// many runs list it.
+package fit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hunterheston/airbnb/pkg/scraper"
+)
+
+// maxText caps how much of a description or resume is embedded, in runes,
+// to stay under the models' input limits.
+const maxText = 8000
+
+// Config is the resume section of the config file.
+type Config struct {
+	// File is the resume as plain text. Without one, jobs aren't rated.
+	File string `yaml:"file"`
+	// Provider embeds the texts: "openai", the default, for any
+	// OpenAI-compatible embeddings API, or "words" for word counts
+	// computed locally.
+	Provider string `yaml:"provider"`
+	// Model, URL and APIKey configure the openai provider; see OpenAI.
+	Model  string `yaml:"model"`
+	URL    string `yaml:"url"`
+	APIKey string `yaml:"api_key"`
+
+	Client *http.Client `yaml:"-"`
+}
+
+// Validate checks c.
+func (c Config) Validate() error {
+	switch c.Provider {
+	case "", "openai", "words":
+	default:
+		return fmt.Errorf("resume: unknown provider %q", c.Provider)
+	}
+	if c.File != "" && (c.Provider == "" || c.Provider == "openai") && c.Model == "" {
+		return errors.New("resume: the openai provider needs a model")
+	}
+	return nil
+}
+
+// Provider turns texts into vectors whose cosine similarity says how
+// alike the texts are.
+type Provider interface {
+	// Name identifies the provider and its model, so that the vectors of
+	// different ones aren't mixed up in the cache.
+	Name() string
+	// Embed returns the vector of each text, in order.
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// Cache keeps vectors by text hash.
+type Cache interface {
+	// Embedding returns the vector cached under key, or nil.
+	Embedding(key string) ([]float32, error)
+	SaveEmbedding(key string, vec []float32, now time.Time) error
+}
+
+// Fitter rates jobs against a resume.
+type Fitter struct {
+	Provider Provider
+	Cache    Cache
+	// Resume is the resume's text.
+	Resume string
+}
+
+// New returns a Fitter for the resume in c.File, or nil if c has no file.
+func (c Config) New(cache Cache) (*Fitter, error) {
+	if c.File == "" {
+		return nil, nil
+	}
+	resume, err := os.ReadFile(c.File)
+	if err != nil {
+		return nil, fmt.Errorf("reading resume: %w", err)
+	}
+	var p Provider
+	switch c.Provider {
+	case "words":
+		p = Words{}
+	default:
+		p = &OpenAI{Model: c.Model, URL: c.URL, APIKey: c.APIKey, Client: c.Client}
+	}
+	return &Fitter{Provider: p, Cache: cache, Resume: string(resume)}, nil
+}
+
+// Fit sets the Fit of the jobs with a description. It fails, leaving
+// every job unrated, if the provider does.
+func (f *Fitter) Fit(ctx context.Context, jobs []scraper.JobPosting) error {
+	texts := []string{f.Resume}
+	for _, job := range jobs {
+		texts = append(texts, jobText(job))
+	}
+	vecs, err := f.embed(ctx, texts)
+	if err != nil {
+		return err
+	}
+	for i := range jobs {
+		if jobs[i].Description == "" {
+			continue
+		}
+		jobs[i].Fit = Percent(Cosine(vecs[0], vecs[i+1]))
+	}
+	return nil
+}
+
+// Rank sorts jobs from the best fit down. Jobs that fit equally well keep
+// their order.
+func Rank(jobs []scraper.JobPosting) {
+	sort.SliceStable(jobs, func(a, b int) bool { return jobs[a].Fit > jobs[b].Fit })
+}
+
+// jobText is what a job is embedded as.
+func jobText(job scraper.JobPosting) string {
+	if job.Description == "" {
+		return ""
+	}
+	return job.Title + "\n\n" + job.Description
+}
+
+// embed returns the vectors of texts, embedding those not cached in one
+// call. Empty texts get nil vectors.
+func (f *Fitter) embed(ctx context.Context, texts []string) ([][]float32, error) {
+	vecs := make([][]float32, len(texts))
+	keys := make([]string, len(texts))
+	var missing []int
+	for i, text := range texts {
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		if r := []rune(text); len(r) > maxText {
+			texts[i] = string(r[:maxText])
+		}
+		keys[i] = f.key(texts[i])
+		vec, err := f.Cache.Embedding(keys[i])
+		if err != nil {
+			return nil, fmt.Errorf("loading embedding: %w", err)
+		}
+		if vec != nil {
+			vecs[i] = vec
+			continue
+		}
+		missing = append(missing, i)
+	}
+	if len(missing) == 0 {
+		return vecs, nil
+	}
+
+	batch := make([]string, len(missing))
+	for j, i := range missing {
+		batch[j] = texts[i]
+	}
+	got, err := f.Provider.Embed(ctx, batch)
+	if err != nil {
+		return nil, fmt.Errorf("embedding with %s: %w", f.Provider.Name(), err)
+	}
+	if len(got) != len(batch) {
+		return nil, fmt.Errorf("embedding with %s: got %d vectors for %d texts", f.Provider.Name(), len(got), len(batch))
+	}
+	now := time.Now()
+	for j, i := range missing {
+		vecs[i] = got[j]
+		if err := f.Cache.SaveEmbedding(keys[i], got[j], now); err != nil {
+			return nil, fmt.Errorf("saving embedding: %w", err)
+		}
+	}
+	return vecs, nil
+}
+
+// key is what text's vector is cached under.
+func (f *Fitter) key(text string) string {
+	h := sha256.Sum256([]byte(f.Provider.Name() + "\n" + text))
+	return hex.EncodeToString(h[:])
+}
+
+// Cosine returns the cosine similarity of a and b, or 0 if either is
+// empty or their lengths differ.
+func Cosine(a, b []float32) float64 {
+	if len(a) == 0 || len(a) != len(b) {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / math.Sqrt(na*nb)
+}
+
+// Percent turns a similarity into a fit from 0 to 100. Unlike texts count
+// as no fit rather than a negative one.
+func Percent(similarity float64) int {
+	return int(math.Round(max(similarity, 0) * 100))
+}
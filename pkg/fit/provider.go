@@ -0,0 +1,151 @@
+package fit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math"
+	"net/http"
+	"strings"
+	"unicode"
+)
+
+// DefaultURL is OpenAI's API.
+const DefaultURL = "https://api.openai.com/v1"
+
+// openAIBatch is how many texts one embeddings request carries.
+const openAIBatch = 100
+
+// OpenAI embeds texts with an OpenAI-compatible embeddings API, OpenAI's
+// own or a local one such as Ollama's.
+type OpenAI struct {
+	// Model is e.g. "text-embedding-3-small" or, for Ollama,
+	// "nomic-embed-text".
+	Model string
+	// URL is the base URL of the API, DefaultURL unless set; requests go to
+	// its /embeddings.
+	URL string
+	// APIKey is sent as a bearer token, if set.
+	APIKey string
+	Client *http.Client
+}
+
+// Name implements Provider.
+func (o *OpenAI) Name() string {
+	return "openai:" + o.Model
+}
+
+// Embed implements Provider.
+func (o *OpenAI) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	var vecs [][]float32
+	for start := 0; start < len(texts); start += openAIBatch {
+		batch := texts[start:min(start+openAIBatch, len(texts))]
+		got, err := o.embed(ctx, batch)
+		if err != nil {
+			return nil, err
+		}
+		vecs = append(vecs, got...)
+	}
+	return vecs, nil
+}
+
+// embed embeds texts in one request.
+func (o *OpenAI) embed(ctx context.Context, texts []string) ([][]float32, error) {
+	body, err := json.Marshal(map[string]any{"model": o.Model, "input": texts})
+	if err != nil {
+		return nil, err
+	}
+	base := o.URL
+	if base == "" {
+		base = DefaultURL
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(base, "/")+"/embeddings", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if o.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+o.APIKey)
+	}
+	client := o.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, bytes.TrimSpace(msg))
+	}
+
+	var result struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+	vecs := make([][]float32, len(texts))
+	for _, d := range result.Data {
+		if d.Index < 0 || d.Index >= len(vecs) {
+			return nil, fmt.Errorf("the response has an embedding for text %d of %d", d.Index, len(texts))
+		}
+		vecs[d.Index] = d.Embedding
+	}
+	return vecs, nil
+}
+
+// wordsDims is the length of the vectors Words makes.
+const wordsDims = 512
+
+// stopWords are too common in resumes and postings alike to tell them
+// apart.
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true, "be": true, "by": true,
+	"for": true, "from": true, "in": true, "is": true, "it": true, "of": true, "on": true, "or": true,
+	"our": true, "that": true, "the": true, "this": true, "to": true, "we": true, "will": true,
+	"with": true, "you": true, "your": true,
+}
+
+// Words embeds texts locally as counts of their words, hashed into a fixed
+// number of dimensions. It needs no API and sends nothing anywhere, but
+// only sees shared words, not shared meaning.
+type Words struct{}
+
+// Name implements Provider.
+func (Words) Name() string {
+	return "words"
+}
+
+// Embed implements Provider.
+func (Words) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	vecs := make([][]float32, len(texts))
+	for i, text := range texts {
+		counts := map[string]int{}
+		for _, w := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '+' && r != '#'
+		}) {
+			if len(w) > 1 && !stopWords[w] {
+				counts[w]++
+			}
+		}
+		vec := make([]float32, wordsDims)
+		for w, n := range counts {
+			h := fnv.New32a()
+			h.Write([]byte(w))
+			// Dampened, so that a word repeated throughout a posting
+			// doesn't outweigh the rest.
+			vec[h.Sum32()%wordsDims] += float32(1 + math.Log(float64(n)))
+		}
+		vecs[i] = vec
+	}
+	return vecs, nil
+}
@@ -0,0 +1,213 @@
+// Package gcal writes events to a Google Calendar through its REST API,
+// authenticating as a service account, which must be given "Make changes
+// to events" access to the calendar.
+//
+// Events are given IDs of the caller's choosing, so that writing one again
+// updates it rather than adding a duplicate, and nothing has to remember
+// which calendar event belongs to what.
+package gcal
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/hunterheston/airbnb/pkg/googleauth"
+)
+
+const (
+	api   = "https://www.googleapis.com/calendar/v3/calendars/"
+	scope = "https://www.googleapis.com/auth/calendar.events"
+)
+
+// Config is the calendar section of the config file.
+type Config struct {
+	// CalendarID is the calendar events go to: for someone's main calendar
+	// their Google address, otherwise the ID in the calendar's settings.
+	// Without one nothing is written.
+	CalendarID string `yaml:"calendar_id"`
+	// CredentialsFile is the service account's JSON key.
+	CredentialsFile string `yaml:"credentials_file"`
+	// InterviewLength is how long an interview's event lasts.
+	InterviewLength time.Duration `yaml:"interview_length"`
+	// Interval is how often "jobwatch serve" writes the dates changed in
+	// the dashboard. Zero leaves them to the next run.
+	Interval time.Duration `yaml:"interval"`
+
+	Client *http.Client `yaml:"-"`
+}
+
+// Validate checks c.
+func (c Config) Validate() error {
+	if c.CalendarID == "" {
+		return nil
+	}
+	if c.CredentialsFile == "" {
+		return errors.New("calendar: credentials_file must be set")
+	}
+	if c.InterviewLength <= 0 {
+		return errors.New("calendar: interview_length must be positive")
+	}
+	if c.Interval < 0 {
+		return errors.New("calendar: interval must not be negative")
+	}
+	return nil
+}
+
+// Event is a calendar event.
+type Event struct {
+	// ID is what identifies the event; see EventID.
+	ID          string
+	Summary     string
+	Description string
+	// Start and End bound the event. An all-day event lasts from the day
+	// of Start to the day of End, inclusive.
+	Start, End time.Time
+	AllDay     bool
+}
+
+// EventID returns an event ID made from parts, e.g. what the event is and
+// whose it is. The API only takes IDs of the characters 0-9 and a-v.
+func EventID(parts ...string) string {
+	h := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(h[:16])
+}
+
+// Calendar is a calendar events are written to.
+type Calendar struct {
+	Config
+	header http.Header
+}
+
+// Open returns the calendar c configures, signed in.
+func Open(ctx context.Context, c Config) (*Calendar, error) {
+	if c.CalendarID == "" || c.CredentialsFile == "" {
+		return nil, errors.New("calendar: calendar_id and credentials_file must be configured")
+	}
+	sa, err := googleauth.Load(c.CredentialsFile)
+	if err != nil {
+		return nil, fmt.Errorf("calendar: %w", err)
+	}
+	token, err := sa.AccessToken(ctx, c.Client, scope)
+	if err != nil {
+		return nil, fmt.Errorf("calendar: %w", err)
+	}
+	return &Calendar{Config: c, header: http.Header{"Authorization": {"Bearer " + token}}}, nil
+}
+
+// Put adds e to the calendar, or updates it if an event with its ID is
+// there already. An event that was deleted is brought back.
+func (c *Calendar) Put(ctx context.Context, e Event) error {
+	body := eventBody(e)
+	err := c.do(ctx, http.MethodPut, c.eventsURL(e.ID), body)
+	var status *statusError
+	if errors.As(err, &status) && status.code == http.StatusNotFound {
+		err = c.do(ctx, http.MethodPost, c.eventsURL(""), body)
+	}
+	if err != nil {
+		return fmt.Errorf("calendar: writing %q: %w", e.Summary, err)
+	}
+	return nil
+}
+
+// Delete removes the event with the given ID from the calendar, if it is
+// there.
+func (c *Calendar) Delete(ctx context.Context, id string) error {
+	err := c.do(ctx, http.MethodDelete, c.eventsURL(id), nil)
+	var status *statusError
+	if errors.As(err, &status) && (status.code == http.StatusNotFound || status.code == http.StatusGone) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("calendar: deleting event %s: %w", id, err)
+	}
+	return nil
+}
+
+func (c *Calendar) eventsURL(id string) string {
+	u := api + url.PathEscape(c.CalendarID) + "/events"
+	if id != "" {
+		u += "/" + url.PathEscape(id)
+	}
+	return u
+}
+
+// eventTime is the start or end of an event as the API takes it: a date
+// for all-day events, a time otherwise.
+type eventTime struct {
+	Date     string `json:"date,omitempty"`
+	DateTime string `json:"dateTime,omitempty"`
+}
+
+func eventBody(e Event) map[string]any {
+	start := eventTime{DateTime: e.Start.Format(time.RFC3339)}
+	end := eventTime{DateTime: e.End.Format(time.RFC3339)}
+	if e.AllDay {
+		// The API's end date is exclusive.
+		start = eventTime{Date: e.Start.Format(time.DateOnly)}
+		end = eventTime{Date: e.End.AddDate(0, 0, 1).Format(time.DateOnly)}
+	}
+	return map[string]any{
+		"id":          e.ID,
+		"summary":     e.Summary,
+		"description": e.Description,
+		"start":       start,
+		"end":         end,
+		"status":      "confirmed",
+	}
+}
+
+// statusError is an error response from the API.
+type statusError struct {
+	code int
+	body string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("status %d: %s", e.code, e.body)
+}
+
+func (c *Calendar) do(ctx context.Context, method, endpoint string, body any) error {
+	var r io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		r = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, r)
+	if err != nil {
+		return err
+	}
+	for k, v := range c.header {
+		req.Header[k] = v
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	client := c.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return &statusError{resp.StatusCode, strings.TrimSpace(string(msg))}
+	}
+	io.Copy(io.Discard, resp.Body)
+	return nil
+}
@@ -0,0 +1,154 @@
+// Package googleauth gets OAuth2 access tokens for Google APIs from a
+// service account key, enough for the few Google APIs this project calls
+// without pulling in the Google SDK.
+package googleauth
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+const defaultTokenURL = "https://oauth2.googleapis.com/token"
+
+// ServiceAccount is the part of a service account's JSON key file needed to
+// sign token requests.
+type ServiceAccount struct {
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+	TokenURI    string `json:"token_uri"`
+}
+
+// Load reads the JSON key file at path. Like any other secret, the file must
+// not be readable by group or others.
+func Load(path string) (*ServiceAccount, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("googleauth: %w", err)
+	}
+	if info.Mode().Perm()&0o077 != 0 {
+		return nil, fmt.Errorf("googleauth: %s is accessible by other users; chmod 600 it", path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("googleauth: %w", err)
+	}
+	var sa ServiceAccount
+	if err := json.Unmarshal(data, &sa); err != nil {
+		return nil, fmt.Errorf("googleauth: parsing %s: %w", path, err)
+	}
+	if sa.ClientEmail == "" || sa.PrivateKey == "" {
+		return nil, fmt.Errorf("googleauth: %s has no client_email or private_key", path)
+	}
+	return &sa, nil
+}
+
+// AccessToken exchanges a signed assertion for an access token valid for
+// scopes, as described in "Using OAuth 2.0 for Server to Server
+// Applications". A nil client means http.DefaultClient.
+func (sa *ServiceAccount) AccessToken(ctx context.Context, client *http.Client, scopes ...string) (string, error) {
+	tokenURL := sa.TokenURI
+	if tokenURL == "" {
+		tokenURL = defaultTokenURL
+	}
+	assertion, err := sa.assertion(tokenURL, scopes, time.Now())
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("googleauth: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("googleauth: requesting token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		AccessToken      string `json:"access_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("googleauth: requesting token: HTTP %d: %w", resp.StatusCode, err)
+	}
+	if body.Error != "" {
+		return "", fmt.Errorf("googleauth: requesting token: %s: %s", body.Error, body.ErrorDescription)
+	}
+	if body.AccessToken == "" {
+		return "", fmt.Errorf("googleauth: requesting token: HTTP %d with no access_token", resp.StatusCode)
+	}
+	return body.AccessToken, nil
+}
+
+// assertion returns the RS256-signed JWT asking tokenURL for scopes, valid
+// for an hour from now.
+func (sa *ServiceAccount) assertion(tokenURL string, scopes []string, now time.Time) (string, error) {
+	key, err := sa.key()
+	if err != nil {
+		return "", err
+	}
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	claims, err := json.Marshal(map[string]any{
+		"iss":   sa.ClientEmail,
+		"scope": strings.Join(scopes, " "),
+		"aud":   tokenURL,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	enc := base64.RawURLEncoding
+	unsigned := enc.EncodeToString(header) + "." + enc.EncodeToString(claims)
+	sum := sha256.Sum256([]byte(unsigned))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, sum[:])
+	if err != nil {
+		return "", fmt.Errorf("googleauth: signing: %w", err)
+	}
+	return unsigned + "." + enc.EncodeToString(sig), nil
+}
+
+// key parses the PEM private key, which Google issues in PKCS #8.
+func (sa *ServiceAccount) key() (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(sa.PrivateKey))
+	if block == nil {
+		return nil, errors.New("googleauth: private_key is not PEM")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("googleauth: private_key: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("googleauth: private_key is not an RSA key")
+	}
+	return key, nil
+}
@@ -0,0 +1,71 @@
+// Package heartbeat pings a dead man's switch, such as a healthchecks.io
+// check, after every run. The service raises the alarm when the pings stop,
+// which catches what no notification can: the cron job itself no longer
+// running.
+package heartbeat
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// maxMessage caps the error message sent with a failure ping;
+// healthchecks.io keeps the first 100 KB of a ping's body.
+const maxMessage = 10 << 10
+
+// Pinger reports the outcome of each run to a heartbeat URL.
+type Pinger struct {
+	// URL is pinged after every successful run, e.g.
+	// https://hc-ping.com/<uuid>.
+	URL string `yaml:"url"`
+	// FailURL is pinged with the error message after a failed run. It
+	// defaults to URL with "/fail" appended, as healthchecks.io expects.
+	FailURL string `yaml:"fail_url"`
+
+	Client *http.Client `yaml:"-"`
+}
+
+// Success reports a successful run.
+func (p *Pinger) Success(ctx context.Context) error {
+	return p.ping(ctx, p.URL, "")
+}
+
+// Fail reports a run that failed with err.
+func (p *Pinger) Fail(ctx context.Context, err error) error {
+	url := p.FailURL
+	if url == "" {
+		url = strings.TrimSuffix(p.URL, "/") + "/fail"
+	}
+	msg := err.Error()
+	if len(msg) > maxMessage {
+		msg = msg[:maxMessage]
+	}
+	return p.ping(ctx, url, msg)
+}
+
+// ping POSTs msg to url, which healthchecks.io shows alongside the ping.
+func (p *Pinger) ping(ctx context.Context, url, msg string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, strings.NewReader(msg))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	client := p.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("pinging heartbeat: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("pinging heartbeat: HTTP %d: %s", resp.StatusCode, bytes.TrimSpace(body))
+	}
+	return nil
+}
@@ -0,0 +1,218 @@
+package inbox
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// client speaks just enough IMAP4rev1 (RFC 3501) over TLS to find unread
+// messages, read them and mark them read.
+type client struct {
+	conn net.Conn
+	r    *bufio.Reader
+	tag  int
+}
+
+// response is one line the server sent, with the literals it carried,
+// e.g. a message's text, in the order they came.
+type response struct {
+	line     string
+	literals [][]byte
+}
+
+// maxLiteral caps a literal, so that a huge message can't exhaust memory.
+const maxLiteral = 10 << 20
+
+// newClient reads the server's greeting on conn.
+func newClient(conn net.Conn) (*client, error) {
+	c := &client{conn: conn, r: bufio.NewReader(conn)}
+	greeting, err := c.read()
+	if err != nil {
+		return nil, fmt.Errorf("reading greeting: %w", err)
+	}
+	if !strings.HasPrefix(greeting.line, "* OK") && !strings.HasPrefix(greeting.line, "* PREAUTH") {
+		return nil, fmt.Errorf("unexpected greeting %q", greeting.line)
+	}
+	return c, nil
+}
+
+// login authenticates with LOGIN.
+func (c *client) login(user, pass string) error {
+	u, err := quote(user)
+	if err != nil {
+		return fmt.Errorf("username: %w", err)
+	}
+	p, err := quote(pass)
+	if err != nil {
+		return fmt.Errorf("password: %w", err)
+	}
+	_, err = c.cmd("LOGIN " + u + " " + p)
+	return err
+}
+
+// selectMailbox opens mailbox for reading and writing.
+func (c *client) selectMailbox(mailbox string) error {
+	m, err := quote(mailbox)
+	if err != nil {
+		return fmt.Errorf("mailbox: %w", err)
+	}
+	_, err = c.cmd("SELECT " + m)
+	return err
+}
+
+// search returns the UIDs of the messages matching criteria, e.g.
+// `UNSEEN FROM "me@example.com"`.
+func (c *client) search(criteria string) ([]uint32, error) {
+	resps, err := c.cmd("UID SEARCH " + criteria)
+	if err != nil {
+		return nil, err
+	}
+	var uids []uint32
+	for _, r := range resps {
+		rest, ok := strings.CutPrefix(r.line, "* SEARCH")
+		if !ok {
+			continue
+		}
+		for _, f := range strings.Fields(rest) {
+			uid, err := strconv.ParseUint(f, 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("bad UID %q in search results", f)
+			}
+			uids = append(uids, uint32(uid))
+		}
+	}
+	return uids, nil
+}
+
+// fetch returns the whole message with the given UID, headers and all,
+// without marking it read.
+func (c *client) fetch(uid uint32) ([]byte, error) {
+	resps, err := c.cmd(fmt.Sprintf("UID FETCH %d BODY.PEEK[]", uid))
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range resps {
+		if strings.Contains(r.line, " FETCH ") && len(r.literals) > 0 {
+			return r.literals[0], nil
+		}
+	}
+	return nil, fmt.Errorf("message %d not found", uid)
+}
+
+// markSeen marks the message with the given UID as read.
+func (c *client) markSeen(uid uint32) error {
+	_, err := c.cmd(fmt.Sprintf(`UID STORE %d +FLAGS.SILENT (\Seen)`, uid))
+	return err
+}
+
+// logout ends the session and closes the connection.
+func (c *client) logout() error {
+	_, err := c.cmd("LOGOUT")
+	if cerr := c.conn.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// cmd sends a command and returns the untagged responses that came before
+// its completion, or an error if it didn't complete with OK.
+func (c *client) cmd(command string) ([]response, error) {
+	c.tag++
+	tag := "a" + strconv.Itoa(c.tag)
+	if _, err := io.WriteString(c.conn, tag+" "+command+"\r\n"); err != nil {
+		return nil, err
+	}
+	var resps []response
+	for {
+		r, err := c.read()
+		if err != nil {
+			return nil, err
+		}
+		status, ok := strings.CutPrefix(r.line, tag+" ")
+		if !ok {
+			resps = append(resps, r)
+			continue
+		}
+		if !strings.HasPrefix(status, "OK") {
+			verb, _, _ := strings.Cut(command, " ")
+			return nil, fmt.Errorf("%s: %s", verb, status)
+		}
+		return resps, nil
+	}
+}
+
+// read reads one response, following the literals ("{n}" at the end of a
+// line, then n bytes) it spans.
+func (c *client) read() (response, error) {
+	var r response
+	var line strings.Builder
+	for {
+		s, err := c.r.ReadString('\n')
+		if err != nil {
+			return r, err
+		}
+		s = strings.TrimRight(s, "\r\n")
+		n, ok := literalSize(s)
+		if !ok {
+			line.WriteString(s)
+			r.line = line.String()
+			return r, nil
+		}
+		if n > maxLiteral {
+			return r, fmt.Errorf("literal of %d bytes is too large", n)
+		}
+		line.WriteString(s)
+		lit := make([]byte, n)
+		if _, err := io.ReadFull(c.r, lit); err != nil {
+			return r, err
+		}
+		r.literals = append(r.literals, lit)
+	}
+}
+
+// literalSize returns n if s ends with "{n}".
+func literalSize(s string) (int, bool) {
+	if !strings.HasSuffix(s, "}") {
+		return 0, false
+	}
+	open := strings.LastIndexByte(s, '{')
+	if open < 0 {
+		return 0, false
+	}
+	n, err := strconv.Atoi(s[open+1 : len(s)-1])
+	if err != nil || n < 0 {
+		return 0, false
+	}
+	return n, true
+}
+
+// quote makes s an IMAP quoted string, which can't hold line breaks: one
+// would end the command and start another.
+func quote(s string) (string, error) {
+	if strings.ContainsAny(s, "\r\n") {
+		return "", fmt.Errorf("%q contains a line break", s)
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`, nil
+}
+
+// dial connects to addr over TLS. Cancelling ctx closes the connection,
+// abandoning whatever command is in progress.
+func dial(ctx context.Context, addr string, cfg *tls.Config) (*client, error) {
+	conn, err := (&tls.Dialer{Config: cfg}).DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	context.AfterFunc(ctx, func() { conn.Close() })
+	c, err := newClient(conn)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return c, nil
+}
@@ -0,0 +1,307 @@
+// Package inbox reads replies to the digest from an IMAP mailbox and finds
+// the commands in them, such as "mute 12345" or "applied 67890", so that
+// jobs can be marked without leaving the email.
+//
+// Only unread replies from the configured senders are read, and each is
+// marked read once its commands have been handled. The From header is
+// easily forged, so anyone who knows the address could send commands; the
+// most they can do is change how jobs are marked.
+package inbox
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// The commands a reply can give, each followed by one or more jobs.
+const (
+	Mute        = "mute"
+	Unmute      = "unmute"
+	Interested  = "interested"
+	Applied     = "applied"
+	PhoneScreen = "phone_screen"
+	Onsite      = "onsite"
+	Offer       = "offer"
+	Rejected    = "rejected"
+	Up          = "up"
+	Down        = "down"
+)
+
+// Verbs lists the commands.
+var Verbs = []string{Mute, Unmute, Interested, Applied, PhoneScreen, Onsite, Offer, Rejected, Up, Down}
+
+// Config is the inbox section of the config file.
+type Config struct {
+	// Host is the IMAP server, e.g. imap.gmail.com. Without one replies
+	// aren't read.
+	Host string `yaml:"host"`
+	// Port defaults to 993. The connection is always TLS.
+	Port string `yaml:"port"`
+	// TLSSkipVerify accepts the server's certificate without checking it.
+	TLSSkipVerify bool `yaml:"tls_skip_verify"`
+	// Username and Password default to the email section's.
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	// Mailbox is the folder replies arrive in, INBOX unless set.
+	Mailbox string `yaml:"mailbox"`
+	// From lists the addresses whose replies are obeyed. It defaults to the
+	// email section's to.
+	From []string `yaml:"from"`
+	// Interval is how often "jobwatch serve" checks for replies. Zero
+	// leaves it to "jobwatch inbox", e.g. from cron.
+	Interval time.Duration `yaml:"interval"`
+
+	// Subject is what the subject of a reply contains: the digest's.
+	Subject string `yaml:"-"`
+}
+
+// Validate checks c. An empty From is left for Check to refuse, since the
+// senders may only be looked up when the inbox is polled.
+func (c Config) Validate() error {
+	if c.Host == "" {
+		return nil
+	}
+	if c.Interval < 0 {
+		return errors.New("inbox: interval must not be negative")
+	}
+	for _, addr := range c.From {
+		if _, err := mail.ParseAddress(addr); err != nil {
+			return fmt.Errorf("inbox: from: %q: %w", addr, err)
+		}
+	}
+	return nil
+}
+
+// Reply is a reply found in the mailbox.
+type Reply struct {
+	From     string
+	Subject  string
+	Commands []Command
+}
+
+// Command is a line of a reply giving one of the Verbs.
+type Command struct {
+	Verb string
+	// Jobs are as written in the reply: IDs or URLs.
+	Jobs []string
+}
+
+// Check hands each unread reply from c.From to handle and marks it read.
+// Replies that can't be read, and messages from other senders that the
+// server matched, are left unread.
+func (c Config) Check(ctx context.Context, handle func(Reply)) error {
+	if len(c.From) == 0 {
+		return errors.New("inbox: from must list the addresses replies are accepted from")
+	}
+	port := c.Port
+	if port == "" {
+		port = "993"
+	}
+	addr := net.JoinHostPort(c.Host, port)
+	cl, err := dial(ctx, addr, &tls.Config{ServerName: c.Host, InsecureSkipVerify: c.TLSSkipVerify})
+	if err != nil {
+		return fmt.Errorf("connecting to %s: %w", addr, err)
+	}
+	defer cl.logout()
+
+	if err := cl.login(c.Username, c.Password); err != nil {
+		return err
+	}
+	mailbox := c.Mailbox
+	if mailbox == "" {
+		mailbox = "INBOX"
+	}
+	if err := cl.selectMailbox(mailbox); err != nil {
+		return err
+	}
+
+	subject := ""
+	if c.Subject != "" {
+		q, err := quote(c.Subject)
+		if err != nil {
+			return fmt.Errorf("subject: %w", err)
+		}
+		subject = " SUBJECT " + q
+	}
+	for _, from := range c.From {
+		q, err := quote(from)
+		if err != nil {
+			return fmt.Errorf("from: %w", err)
+		}
+		uids, err := cl.search("UNSEEN FROM " + q + subject)
+		if err != nil {
+			return err
+		}
+		for _, uid := range uids {
+			raw, err := cl.fetch(uid)
+			if err != nil {
+				return err
+			}
+			r, err := c.parse(raw)
+			if err != nil {
+				slog.Warn("skipping unreadable reply", "uid", uid, "err", err)
+				continue
+			}
+			// Messages the server matched loosely are left unread for
+			// their reader.
+			if r == nil {
+				continue
+			}
+			handle(*r)
+			if err := cl.markSeen(uid); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// parse reads a message, returning nil if it isn't from one of c.From;
+// servers match FROM searches loosely.
+func (c Config) parse(raw []byte) (*Reply, error) {
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		return nil, err
+	}
+	from, err := mail.ParseAddress(msg.Header.Get("From"))
+	if err != nil {
+		return nil, fmt.Errorf("from: %w", err)
+	}
+	if !c.accepts(from.Address) {
+		return nil, nil
+	}
+	text, err := textBody(msg.Header.Get("Content-Type"), msg.Header.Get("Content-Transfer-Encoding"), msg.Body)
+	if err != nil {
+		return nil, err
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil {
+		subject = msg.Header.Get("Subject")
+	}
+	return &Reply{From: from.Address, Subject: subject, Commands: Parse(text)}, nil
+}
+
+// accepts reports whether addr is one of c.From.
+func (c Config) accepts(addr string) bool {
+	for _, f := range c.From {
+		if a, err := mail.ParseAddress(f); err == nil && strings.EqualFold(a.Address, addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// textBody returns the text/plain part of a body with the given content
+// type and transfer encoding, decoded.
+func textBody(contentType, encoding string, body io.Reader) (string, error) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if contentType == "" {
+		mediaType, err = "text/plain", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("content type: %w", err)
+	}
+	body = decode(encoding, body)
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		mr := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := mr.NextRawPart()
+			if err == io.EOF {
+				return "", errors.New("no text/plain part")
+			}
+			if err != nil {
+				return "", err
+			}
+			text, err := textBody(part.Header.Get("Content-Type"), part.Header.Get("Content-Transfer-Encoding"), part)
+			if err == nil {
+				return text, nil
+			}
+		}
+	}
+	if mediaType != "text/plain" {
+		return "", fmt.Errorf("%s is not text/plain", mediaType)
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return "", err
+	}
+	// Replies are nearly always UTF-8 or ASCII; other charsets only garble
+	// the quoted text, not the commands.
+	return string(data), nil
+}
+
+// decode undoes a Content-Transfer-Encoding.
+func decode(encoding string, r io.Reader) io.Reader {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "quoted-printable":
+		return quotedprintable.NewReader(r)
+	case "base64":
+		// The decoder skips the line breaks the body is wrapped with.
+		return base64.NewDecoder(base64.StdEncoding, r)
+	default:
+		return r
+	}
+}
+
+// quoteIntro matches the line mail clients put above the quoted message,
+// e.g. "On Mon, Jun 2, 2025 at 9:00 AM Jobwatch <jobs@example.com> wrote:".
+var quoteIntro = regexp.MustCompile(`^On .*wrote:$|^-+ ?Original Message ?-+$`)
+
+// Parse finds the commands in a reply's text: the lines, above the quoted
+// digest and the signature, that start with one of the Verbs followed by
+// jobs, e.g. "mute 12345 67890". Other lines are ignored, so a reply can
+// say more than its commands.
+func Parse(text string) []Command {
+	var cmds []Command
+	sc := bufio.NewScanner(strings.NewReader(text))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if strings.HasPrefix(line, ">") || line == "--" || quoteIntro.MatchString(line) {
+			break
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		verb := strings.ToLower(strings.TrimSuffix(fields[0], ":"))
+		verb = strings.ReplaceAll(verb, "-", "_")
+		if !slices.Contains(Verbs, verb) {
+			continue
+		}
+		cmd := Command{Verb: verb}
+		for _, f := range fields[1:] {
+			job := strings.Trim(f, ".,;<>()")
+			if !jobRef.MatchString(job) {
+				// Prose that happens to start with a verb, e.g.
+				// "Interested in more jobs like these".
+				cmd.Jobs = nil
+				break
+			}
+			cmd.Jobs = append(cmd.Jobs, job)
+		}
+		if len(cmd.Jobs) > 0 {
+			cmds = append(cmds, cmd)
+		}
+	}
+	return cmds
+}
+
+// jobRef matches how a command names a job: by ID or URL.
+var jobRef = regexp.MustCompile(`^(\d+|https?://\S+)$`)
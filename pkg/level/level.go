@@ -0,0 +1,125 @@
+// Package level classifies job titles by seniority.
+package level
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Level is a seniority band.
+type Level int
+
+// The levels, from least to most senior. Manager covers any title naming
+// a manager or director, whatever its seniority.
+const (
+	Intern Level = iota
+	Junior
+	Mid
+	Senior
+	StaffPlus
+	Manager
+)
+
+var names = []string{"intern", "junior", "mid", "senior", "staff+", "manager"}
+
+// String returns the level's config name, e.g. "staff+".
+func (l Level) String() string {
+	if l < 0 || int(l) >= len(names) {
+		return fmt.Sprintf("Level(%d)", int(l))
+	}
+	return names[l]
+}
+
+// Parse returns the level with the given name, ignoring case. "staff" is
+// accepted for "staff+".
+func Parse(s string) (Level, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "staff" {
+		s = "staff+"
+	}
+	for i, name := range names {
+		if name == s {
+			return Level(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown level %q (want one of %s)", s, strings.Join(names, ", "))
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (l Level) MarshalText() ([]byte, error) {
+	return []byte(l.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (l *Level) UnmarshalText(text []byte) error {
+	parsed, err := Parse(string(text))
+	if err != nil {
+		return err
+	}
+	*l = parsed
+	return nil
+}
+
+// Rule assigns Level to titles containing any of Keywords as whole words,
+// ignoring case.
+type Rule struct {
+	Level    Level    `yaml:"level"`
+	Keywords []string `yaml:"keywords"`
+}
+
+// DefaultRules are tried in this order, so "Senior Engineering Manager" is
+// a manager and "Staff Engineer" isn't senior.
+var DefaultRules = []Rule{
+	{Intern, []string{"Intern", "Internship", "Co-op", "Apprentice"}},
+	{Manager, []string{"Manager", "Director", "Head of", "Vice President", "VP"}},
+	{StaffPlus, []string{"Staff", "Principal", "Distinguished", "Fellow"}},
+	{Senior, []string{"Senior", "Sr", "Sr.", "Lead"}},
+	{Junior, []string{"Junior", "Jr", "Jr.", "Entry Level", "Entry-Level", "New Grad", "Graduate", "Associate"}},
+}
+
+// Classifier maps titles to levels. Titles no rule matches are Mid.
+type Classifier struct {
+	rules []compiledRule
+}
+
+type compiledRule struct {
+	level Level
+	re    *regexp.Regexp
+}
+
+// NewClassifier returns a Classifier trying rules in order.
+func NewClassifier(rules []Rule) *Classifier {
+	c := &Classifier{}
+	for _, r := range rules {
+		if len(r.Keywords) == 0 {
+			continue
+		}
+		quoted := make([]string, len(r.Keywords))
+		for i, kw := range r.Keywords {
+			quoted[i] = regexp.QuoteMeta(kw)
+		}
+		// \b doesn't work next to punctuation like the dot in "Sr.", so
+		// word boundaries are spelled out.
+		re := regexp.MustCompile(`(?i)(?:^|[^\pL\pN])(?:` + strings.Join(quoted, "|") + `)(?:$|[^\pL\pN])`)
+		c.rules = append(c.rules, compiledRule{r.Level, re})
+	}
+	return c
+}
+
+var defaultClassifier = NewClassifier(DefaultRules)
+
+// Default returns the classifier using DefaultRules.
+func Default() *Classifier {
+	return defaultClassifier
+}
+
+// Classify returns the level of a job titled title.
+func (c *Classifier) Classify(title string) Level {
+	for _, r := range c.rules {
+		if r.re.MatchString(title) {
+			return r.level
+		}
+	}
+	return Mid
+}
@@ -0,0 +1,173 @@
+// Package location parses the free-form locations careers pages show, such
+// as "San Francisco, California", "Remote - USA" or "Seattle, WA / Remote",
+// into comparable places.
+package location
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Place is one normalized location.
+type Place struct {
+	City string
+	// Region is the state or province, as a two-letter code for US states.
+	Region string
+	// Country is "US" for the United States and as written otherwise.
+	Country string
+	Remote  bool
+}
+
+// String formats p the way Normalize writes it: "San Francisco, CA",
+// "London, United Kingdom", "Remote, US" or "Remote".
+func (p Place) String() string {
+	var parts []string
+	if p.Remote {
+		parts = append(parts, "Remote")
+	}
+	if p.City != "" {
+		parts = append(parts, p.City)
+	}
+	if p.Region != "" {
+		parts = append(parts, p.Region)
+	}
+	// The country is implied by a US state.
+	if _, state := usState(p.Region); p.Country != "" && !(p.Country == "US" && state) {
+		parts = append(parts, p.Country)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Contains reports whether every part of q that is set matches p, so that
+// "Remote" contains "Remote, US" and "CA" contains "San Francisco, CA".
+func (p Place) Contains(o Place) bool {
+	if p.Remote && !o.Remote {
+		return false
+	}
+	return matchPart(p.City, o.City) && matchPart(p.Region, o.Region) && matchPart(p.Country, o.Country)
+}
+
+func matchPart(want, got string) bool {
+	return want == "" || strings.EqualFold(want, got)
+}
+
+// separators split a string holding several locations.
+var separators = regexp.MustCompile(`\s*(?:/|;|\||\bor\b)\s*`)
+
+// remoteWord matches "remote" and the punctuation around it, as in
+// "Remote - US", "US (Remote)" or "Remote: Canada".
+var remoteWord = regexp.MustCompile(`(?i)[\s(\-–:,]*\bremote\b[\s)\-–:,]*`)
+
+// Parse splits s into its places. Unrecognized parts are kept as cities.
+func Parse(s string) []Place {
+	var places []Place
+	for _, part := range separators.Split(s, -1) {
+		if p, ok := parsePlace(part); ok {
+			places = append(places, p)
+		}
+	}
+	return places
+}
+
+func parsePlace(s string) (Place, bool) {
+	var p Place
+	if remoteWord.MatchString(s) {
+		p.Remote = true
+		s = remoteWord.ReplaceAllString(s, " ")
+	}
+	s = strings.Trim(strings.Join(strings.Fields(s), " "), " ,-–()")
+
+	var fields []string
+	for _, f := range strings.Split(s, ",") {
+		if f = strings.TrimSpace(f); f != "" {
+			fields = append(fields, f)
+		}
+	}
+	if len(fields) == 0 {
+		return p, p.Remote
+	}
+
+	// Read from the end: country, then region, then whatever is left is the
+	// city.
+	if c, ok := country(fields[len(fields)-1]); ok {
+		p.Country = c
+		fields = fields[:len(fields)-1]
+	}
+	if len(fields) > 0 {
+		if st, ok := usState(fields[len(fields)-1]); ok && (p.Country == "" || p.Country == "US") {
+			p.Region = st
+			p.Country = "US"
+			fields = fields[:len(fields)-1]
+		} else if len(fields) > 1 {
+			p.Region = fields[len(fields)-1]
+			fields = fields[:len(fields)-1]
+		}
+	}
+	p.City = strings.Join(fields, ", ")
+	return p, true
+}
+
+// Normalize rewrites s in the canonical form of its places, joined with
+// " / ". An empty or unparsable s is returned trimmed.
+func Normalize(s string) string {
+	places := Parse(s)
+	if len(places) == 0 {
+		return strings.TrimSpace(s)
+	}
+	seen := map[string]bool{}
+	var out []string
+	for _, p := range places {
+		if str := p.String(); !seen[str] {
+			seen[str] = true
+			out = append(out, str)
+		}
+	}
+	return strings.Join(out, " / ")
+}
+
+func country(s string) (string, bool) {
+	switch strings.ToLower(strings.ReplaceAll(s, ".", "")) {
+	case "us", "usa", "united states", "united states of america":
+		return "US", true
+	}
+	if knownCountries[strings.ToLower(s)] {
+		return s, true
+	}
+	return "", false
+}
+
+func usState(s string) (string, bool) {
+	lower := strings.ToLower(s)
+	if code, ok := usStates[lower]; ok {
+		return code, true
+	}
+	for _, code := range usStates {
+		if strings.EqualFold(code, s) {
+			return code, true
+		}
+	}
+	return "", false
+}
+
+var knownCountries = map[string]bool{
+	"australia": true, "brazil": true, "canada": true, "china": true,
+	"france": true, "germany": true, "india": true, "ireland": true,
+	"japan": true, "korea": true, "mexico": true, "netherlands": true,
+	"singapore": true, "spain": true, "uk": true, "united kingdom": true,
+}
+
+var usStates = map[string]string{
+	"alabama": "AL", "alaska": "AK", "arizona": "AZ", "arkansas": "AR",
+	"california": "CA", "colorado": "CO", "connecticut": "CT", "delaware": "DE",
+	"district of columbia": "DC", "florida": "FL", "georgia": "GA", "hawaii": "HI",
+	"idaho": "ID", "illinois": "IL", "indiana": "IN", "iowa": "IA",
+	"kansas": "KS", "kentucky": "KY", "louisiana": "LA", "maine": "ME",
+	"maryland": "MD", "massachusetts": "MA", "michigan": "MI", "minnesota": "MN",
+	"mississippi": "MS", "missouri": "MO", "montana": "MT", "nebraska": "NE",
+	"nevada": "NV", "new hampshire": "NH", "new jersey": "NJ", "new mexico": "NM",
+	"new york": "NY", "north carolina": "NC", "north dakota": "ND", "ohio": "OH",
+	"oklahoma": "OK", "oregon": "OR", "pennsylvania": "PA", "rhode island": "RI",
+	"south carolina": "SC", "south dakota": "SD", "tennessee": "TN", "texas": "TX",
+	"utah": "UT", "vermont": "VT", "virginia": "VA", "washington": "WA",
+	"west virginia": "WV", "wisconsin": "WI", "wyoming": "WY",
+}
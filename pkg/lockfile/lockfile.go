@@ -0,0 +1,229 @@
+// Package lockfile keeps two processes from doing the same work at once,
+// such as a slow run and the next cron invocation both sending a digest.
+//
+// A lock is a file created exclusively, holding the owner's process ID and
+// when it was taken. The owner touches it every so often while it holds
+// it, so a process that crashes leaves behind a lock that stops changing,
+// which is why a lock untouched for longer than a stale timeout may be
+// taken over. Only one process at a time may take a lock over, the one
+// that creates a marker file next to it; it renames a new lock file over
+// the old one, so that there is never a moment without one for a third
+// process to create.
+package lockfile
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Lock is a held lock file.
+type Lock struct {
+	path    string
+	content string
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// heartbeat is how often a held lock is touched, at most.
+const heartbeat = time.Minute
+
+// HeldError is returned when another process holds the lock.
+type HeldError struct {
+	Path string
+	// PID is the holder's process ID, or 0 if the file couldn't be read.
+	PID   int
+	Since time.Time
+	// Touched is when the holder last showed it was still running.
+	Touched time.Time
+}
+
+func (e *HeldError) Error() string {
+	return fmt.Sprintf("%s is held by process %d since %s", e.Path, e.PID, e.Since.Format(time.RFC3339))
+}
+
+// Acquire takes the lock at path. A lock untouched for more than stale is
+// assumed to be left over from a process that died and is taken over; a
+// stale of 0 never does that.
+func Acquire(path string, stale time.Duration) (*Lock, error) {
+	every := heartbeat
+	if stale > 0 && stale/4 < every {
+		every = stale / 4
+	}
+	l, err := create(path)
+	if !errors.Is(err, fs.ErrExist) {
+		if err == nil {
+			l.keepAlive(every)
+		}
+		return l, err
+	}
+	held := read(path)
+	if stale <= 0 || time.Since(held.Touched) < stale {
+		return nil, held
+	}
+
+	// Two processes finding the lock stale at once mustn't both take it
+	// over, so only the one that creates the marker does.
+	marker := path + ".takeover"
+	f, err := os.OpenFile(marker, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if errors.Is(err, fs.ErrExist) {
+		// A takeover takes moments; a marker as old as the lock's
+		// timeout was left by a process that died during one.
+		if info, err := os.Stat(marker); err == nil && time.Since(info.ModTime()) >= stale {
+			os.Remove(marker)
+		}
+		return nil, held
+	}
+	if err != nil {
+		return nil, err
+	}
+	f.Close()
+	defer os.Remove(marker)
+	// The lock may have been taken over, and released, since it was read.
+	if held = read(path); time.Since(held.Touched) < stale {
+		return nil, held
+	}
+	slog.Warn("taking over stale lock", "path", path, "pid", held.PID, "since", held.Since)
+	return take(path, every)
+}
+
+// Force takes the lock at path whether or not someone holds it.
+func Force(path string) (*Lock, error) {
+	return take(path, heartbeat)
+}
+
+// take renames a new lock file over the one at path and touches it every
+// so often until it is released.
+func take(path string, every time.Duration) (*Lock, error) {
+	l := newLock(path)
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return nil, err
+	}
+	_, err = f.WriteString(l.content)
+	if err == nil {
+		err = f.Chmod(0o644)
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return nil, err
+	}
+	// A forced takeover at the same time may have renamed its file over
+	// this one.
+	if !l.held() {
+		return nil, read(path)
+	}
+	l.keepAlive(every)
+	return l, nil
+}
+
+// Release removes the lock file, unless another process has taken it over
+// in the meantime.
+func (l *Lock) Release() error {
+	if l.stop != nil {
+		close(l.stop)
+		<-l.done
+		l.stop = nil
+	}
+	data, err := os.ReadFile(l.path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	if string(data) != l.content {
+		return nil
+	}
+	return os.Remove(l.path)
+}
+
+// keepAlive touches the lock file every interval until it is released or
+// taken over, so that it doesn't look stale while it is held.
+func (l *Lock) keepAlive(every time.Duration) {
+	l.stop, l.done = make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(l.done)
+		t := time.NewTicker(every)
+		defer t.Stop()
+		for {
+			select {
+			case <-l.stop:
+				return
+			case <-t.C:
+			}
+			if !l.held() {
+				slog.Warn("lock was taken over", "path", l.path)
+				return
+			}
+			now := time.Now()
+			if err := os.Chtimes(l.path, now, now); err != nil {
+				slog.Warn("touching lock", "path", l.path, "err", err)
+			}
+		}
+	}()
+}
+
+// held reports whether the lock file is still l's.
+func (l *Lock) held() bool {
+	data, err := os.ReadFile(l.path)
+	return err == nil && string(data) == l.content
+}
+
+func create(path string) (*Lock, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	l := newLock(path)
+	_, err = f.WriteString(l.content)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(path)
+		return nil, err
+	}
+	return l, nil
+}
+
+// newLock returns the lock at path that this process is about to take:
+// the file's content is its process ID and the time.
+func newLock(path string) *Lock {
+	return &Lock{
+		path:    path,
+		content: fmt.Sprintf("%d\n%s\n", os.Getpid(), time.Now().UTC().Format(time.RFC3339Nano)),
+	}
+}
+
+// read describes who holds the lock at path. The file's modification time
+// is when it was last touched, and stands in for the time it was taken if
+// the file doesn't say.
+func read(path string) *HeldError {
+	held := &HeldError{Path: path, Since: time.Now(), Touched: time.Now()}
+	if info, err := os.Stat(path); err == nil {
+		held.Since, held.Touched = info.ModTime(), info.ModTime()
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return held
+	}
+	pid, since, _ := strings.Cut(string(data), "\n")
+	held.PID, _ = strconv.Atoi(strings.TrimSpace(pid))
+	if t, err := time.Parse(time.RFC3339Nano, strings.TrimSpace(since)); err == nil {
+		held.Since = t
+	}
+	return held
+}
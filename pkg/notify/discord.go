@@ -0,0 +1,199 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/hunterheston/airbnb/pkg/scraper"
+)
+
+// discordMaxEmbeds is the most embeds Discord accepts in one message, and
+// discordMaxContent the longest content.
+const (
+	discordMaxEmbeds  = 10
+	discordMaxContent = 2000
+)
+
+// DiscordNotifier posts job postings to a Discord webhook as rich embeds.
+type DiscordNotifier struct {
+	WebhookURL string `yaml:"webhook_url"`
+	// Template is an optional path to a text/template that writes the
+	// messages, in Discord's markdown, instead of the built-in embeds. It
+	// receives a TemplateData.
+	Template string `yaml:"template"`
+
+	Client *http.Client `yaml:"-"`
+}
+
+type discordMessage struct {
+	Content string         `json:"content,omitempty"`
+	Embeds  []discordEmbed `json:"embeds,omitempty"`
+}
+
+type discordEmbed struct {
+	Title       string         `json:"title"`
+	URL         string         `json:"url,omitempty"`
+	Description string         `json:"description,omitempty"`
+	Fields      []discordField `json:"fields,omitempty"`
+}
+
+type discordField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline"`
+}
+
+// Notify implements Notifier. New jobs are sent ten to a message, the most
+// Discord allows, followed by a list of the closed ones.
+func (n *DiscordNotifier) Notify(ctx context.Context, d Digest) error {
+	if n.WebhookURL == "" {
+		return errors.New("discord: webhook_url is not configured")
+	}
+	msgs, err := n.messages(d)
+	if err != nil {
+		return fmt.Errorf("discord: rendering: %w", err)
+	}
+	for _, msg := range msgs {
+		if err := n.post(ctx, msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Alert implements Alerter.
+func (n *DiscordNotifier) Alert(ctx context.Context, msg string) error {
+	if n.WebhookURL == "" {
+		return errors.New("discord: webhook_url is not configured")
+	}
+	return n.post(ctx, discordMessage{Content: truncate(":warning: "+msg, discordMaxContent)})
+}
+
+// Render implements Renderer with the messages as Discord receives them,
+// in JSON.
+func (n *DiscordNotifier) Render(d Digest) (string, error) {
+	msgs, err := n.messages(d)
+	if err != nil {
+		return "", err
+	}
+	var out strings.Builder
+	for _, msg := range msgs {
+		b, err := json.MarshalIndent(msg, "", "  ")
+		if err != nil {
+			return "", err
+		}
+		out.Write(b)
+		out.WriteString("\n")
+	}
+	return out.String(), nil
+}
+
+// messages lays d out as the messages to post. A template's text is split
+// at line breaks into messages Discord accepts.
+func (n *DiscordNotifier) messages(d Digest) ([]discordMessage, error) {
+	if n.Template != "" {
+		text, err := renderText("", n.Template, NewTemplateData(d, nil))
+		if err != nil {
+			return nil, err
+		}
+		var msgs []discordMessage
+		for _, chunk := range splitLines(text, discordMaxContent) {
+			msgs = append(msgs, discordMessage{Content: chunk})
+		}
+		return msgs, nil
+	}
+
+	var msgs []discordMessage
+	if len(d.Partial) > 0 {
+		msgs = append(msgs, discordMessage{Content: ":warning: *" + partialNotice + "*"})
+	}
+	if len(d.New) == 0 {
+		msgs = append(msgs, discordMessage{Content: "No new job postings found today."})
+	}
+	for start := 0; start < len(d.New); start += discordMaxEmbeds {
+		batch := d.New[start:min(start+discordMaxEmbeds, len(d.New))]
+		msg := discordMessage{}
+		if start == 0 {
+			msg.Content = fmt.Sprintf("**%d new job postings**", len(d.New))
+		}
+		for _, job := range batch {
+			msg.Embeds = append(msg.Embeds, discordEmbedFor(job))
+		}
+		msgs = append(msgs, msg)
+	}
+
+	if len(d.Closed) > 0 {
+		var content strings.Builder
+		content.WriteString("**Closed since last run:**\n")
+		for _, job := range d.Closed {
+			fmt.Fprintf(&content, "- ~~%s~~ (%s)\n", job.Title, job.Company)
+		}
+		msgs = append(msgs, discordMessage{Content: truncate(content.String(), discordMaxContent)})
+	}
+	return msgs, nil
+}
+
+// splitLines splits s into pieces of at most n runes, breaking between
+// lines where it can. Blank pieces are dropped.
+func splitLines(s string, n int) []string {
+	var chunks []string
+	var chunk strings.Builder
+	flush := func() {
+		if strings.TrimSpace(chunk.String()) != "" {
+			chunks = append(chunks, strings.TrimRight(chunk.String(), "\n"))
+		}
+		chunk.Reset()
+	}
+	for _, line := range strings.SplitAfter(s, "\n") {
+		// Lines too long for a message of their own are cut short.
+		line = truncate(line, n)
+		if utf8.RuneCountInString(chunk.String())+utf8.RuneCountInString(line) > n {
+			flush()
+		}
+		chunk.WriteString(line)
+	}
+	flush()
+	return chunks
+}
+
+func (n *DiscordNotifier) post(ctx context.Context, msg discordMessage) error {
+	if err := postJSON(ctx, n.Client, n.WebhookURL, msg); err != nil {
+		return fmt.Errorf("discord: %w", err)
+	}
+	return nil
+}
+
+func discordEmbedFor(job scraper.JobPosting) discordEmbed {
+	e := discordEmbed{
+		Title:       truncate(job.Title, 256),
+		URL:         job.URL,
+		Description: job.Company,
+	}
+	if job.Location != "" {
+		e.Fields = append(e.Fields, discordField{Name: "Location", Value: truncate(job.Location, 1024), Inline: true})
+	}
+	if job.Team != "" {
+		e.Fields = append(e.Fields, discordField{Name: "Department", Value: truncate(job.Team, 1024), Inline: true})
+	}
+	if s := job.Salary(); s != "" {
+		e.Fields = append(e.Fields, discordField{Name: "Salary", Value: s, Inline: true})
+	}
+	if a := job.Age(); a != "" {
+		e.Fields = append(e.Fields, discordField{Name: "Posted", Value: strings.TrimPrefix(a, "posted "), Inline: true})
+	}
+	return e
+}
+
+// truncate shortens s to at most n runes.
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
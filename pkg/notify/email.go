@@ -0,0 +1,415 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hunterheston/airbnb/pkg/actions"
+	"github.com/hunterheston/airbnb/pkg/dkim"
+	"github.com/hunterheston/airbnb/pkg/output"
+	"github.com/hunterheston/airbnb/pkg/scraper"
+	"github.com/hunterheston/airbnb/pkg/secrets"
+)
+
+// EmailNotifier composes and sends an email with the list of job postings.
+// It defaults to Gmail's SMTP server. Make sure to use an app password or OAuth2 for Gmail.
+type EmailNotifier struct {
+	// Provider picks how mail is delivered: "smtp" (the default) uses the
+	// server settings below, while "sendgrid", "mailgun" and "ses" use that
+	// provider's HTTP API and the matching credentials section.
+	Provider string `yaml:"provider"`
+
+	Host string `yaml:"host"`
+	// Port defaults to 465 with implicit TLS, 25 without TLS and 587
+	// otherwise.
+	Port string `yaml:"port"`
+	// TLS is "starttls" to require STARTTLS, "implicit" for TLS from the
+	// first byte (port 465) or "none" for a plain connection. By default
+	// STARTTLS is used when the server offers it.
+	TLS string `yaml:"tls"`
+	// TLSSkipVerify accepts the server's certificate without checking it,
+	// for self-signed certificates on internal servers.
+	TLSSkipVerify bool `yaml:"tls_skip_verify"`
+	// Auth is how to log in: "plain" (the default), "login", "cram-md5", or
+	// "none" for relays that don't need a login.
+	Auth string `yaml:"auth"`
+	// Username defaults to From.
+	Username string   `yaml:"username"`
+	Password string   `yaml:"password"`
+	From     string   `yaml:"from"`
+	To       []string `yaml:"to"`
+	// Cc are copied in the open and Bcc in secret.
+	Cc  []string `yaml:"cc"`
+	Bcc []string `yaml:"bcc"`
+	// ReplyTo, if set, is where replies go instead of From.
+	ReplyTo string `yaml:"reply_to"`
+	// OAuth2 replaces Password with XOAUTH2 when its client_id is set, for
+	// Gmail accounts that can't use app passwords.
+	OAuth2 OAuth2Config `yaml:"oauth2"`
+	// DKIM signs mail sent over SMTP for the From address's domain, so that
+	// receivers trust it; the providers sign mail themselves.
+	DKIM dkim.Signer `yaml:"dkim"`
+
+	// HTMLTemplate and TextTemplate are optional paths to templates that
+	// replace the built-in layout. The HTML one is parsed with html/template
+	// and the text one with text/template; both receive a TemplateData and
+	// the functions listed there. The HTML one can also call highlight to
+	// mark the Highlight keywords in a title or excerpt.
+	HTMLTemplate string `yaml:"html_template"`
+	TextTemplate string `yaml:"text_template"`
+	// Highlight lists keywords, e.g. "Go" or "Payments", marked in the HTML
+	// email's titles and description excerpts wherever they appear as
+	// whole words.
+	Highlight []string `yaml:"highlight"`
+	// Attach, "csv" or "json", attaches the digest's new jobs in full as a
+	// file, for importing into another tracker.
+	Attach string `yaml:"attach"`
+
+	SendGrid SendGridTransport `yaml:"sendgrid"`
+	Mailgun  MailgunTransport  `yaml:"mailgun"`
+	SES      SESTransport      `yaml:"ses"`
+
+	// Timeout, if set, bounds each delivery: the whole SMTP conversation or
+	// the provider's API request.
+	Timeout time.Duration `yaml:"-"`
+
+	// Links are listed at the bottom of the email for finding more jobs.
+	Links []Link `yaml:"-"`
+	// Actions, if set, signs the links under each job that mark it as
+	// interesting, applied to or muted.
+	Actions *actions.Signer `yaml:"-"`
+	// Transport, if set, is used instead of the one Provider selects.
+	Transport Transport `yaml:"-"`
+	// Outbox, if set, keeps emails that couldn't be delivered, which then
+	// don't count as failures.
+	Outbox Outbox `yaml:"-"`
+}
+
+// DigestSubject is the subject of the digest email.
+const DigestSubject = "Daily Job Postings"
+
+// Outbox keeps undelivered emails so they can be retried later.
+type Outbox interface {
+	// Queue stores e, whose delivery failed with err.
+	Queue(e *Email, err error) error
+}
+
+// Link is a named URL.
+type Link struct {
+	Name string
+	URL  string
+}
+
+// Section is the new jobs of one team.
+type Section struct {
+	// Name is the team, "Other" for jobs without one, or "" when the
+	// digest isn't grouped.
+	Name string
+	Jobs []scraper.JobPosting
+}
+
+// Sections groups jobs by team, keeping their order within each team and
+// ordering the teams by their first job, so a ranked digest still opens
+// with its best match. Jobs without a team come last, under "Other". If no
+// job has a team, there is a single unnamed section.
+func Sections(jobs []scraper.JobPosting) []Section {
+	if len(jobs) == 0 {
+		return nil
+	}
+	index := map[string]int{}
+	var sections []Section
+	var other []scraper.JobPosting
+	for _, job := range jobs {
+		team := strings.TrimSpace(job.Team)
+		if team == "" {
+			other = append(other, job)
+			continue
+		}
+		key := strings.ToLower(team)
+		i, ok := index[key]
+		if !ok {
+			i = len(sections)
+			index[key] = i
+			sections = append(sections, Section{Name: team})
+		}
+		sections[i].Jobs = append(sections[i].Jobs, job)
+	}
+	if len(sections) == 0 {
+		return []Section{{Jobs: other}}
+	}
+	if len(other) > 0 {
+		sections = append(sections, Section{Name: "Other", Jobs: other})
+	}
+	return sections
+}
+
+// NewEmailNotifierFromEnv reads FROM_EMAIL, TO_EMAIL and GOOGLE_APP_PASSWORD
+// from the environment. WithKeyring looks up the ones that aren't set.
+func NewEmailNotifierFromEnv() *EmailNotifier {
+	n := &EmailNotifier{
+		// The port follows from the TLS mode: 587 for STARTTLS.
+		Host:     "smtp.gmail.com",
+		Password: os.Getenv("GOOGLE_APP_PASSWORD"),
+		From:     os.Getenv("FROM_EMAIL"),
+	}
+	if to := os.Getenv("TO_EMAIL"); to != "" {
+		n.To = []string{to}
+	}
+	return n
+}
+
+// WithKeyring returns n with whichever of its sender, password and
+// recipients are empty set from FROM_EMAIL, GOOGLE_APP_PASSWORD and
+// TO_EMAIL in the OS keyring. The keyring is only read the first time
+// this is called, since each lookup may take a while or ask the user to
+// unlock it; n is sent with it, not when it is built or validated.
+func (n *EmailNotifier) WithKeyring() *EmailNotifier {
+	if n.From != "" && n.Password != "" && len(n.To) > 0 {
+		return n
+	}
+	k := keyringEmail()
+	c := *n
+	if c.From == "" {
+		c.From = k.From
+	}
+	if c.Password == "" {
+		c.Password = k.Password
+	}
+	if len(c.To) == 0 {
+		c.To = k.To
+	}
+	return &c
+}
+
+// keyringEmail reads the settings WithKeyring fills in.
+var keyringEmail = sync.OnceValue(func() EmailNotifier {
+	k := EmailNotifier{
+		From:     secrets.Getenv("FROM_EMAIL"),
+		Password: secrets.Getenv("GOOGLE_APP_PASSWORD"),
+	}
+	if to := secrets.Getenv("TO_EMAIL"); to != "" {
+		k.To = []string{to}
+	}
+	return k
+})
+
+// Notify implements Notifier.
+func (n *EmailNotifier) Notify(ctx context.Context, d Digest) error {
+	n = n.WithKeyring()
+	if len(n.To) == 0 {
+		return errors.New("email: no recipients configured")
+	}
+
+	e, err := n.message(d)
+	if err != nil {
+		return fmt.Errorf("email: %w", err)
+	}
+	return n.send(ctx, e)
+}
+
+// Alert implements Alerter with a plain-text email.
+func (n *EmailNotifier) Alert(ctx context.Context, msg string) error {
+	n = n.WithKeyring()
+	if len(n.To) == 0 {
+		return errors.New("email: no recipients configured")
+	}
+
+	return n.send(ctx, n.compose("Job scraper alert", msg+"\r\n", ""))
+}
+
+// Render implements Renderer with the text body.
+func (n *EmailNotifier) Render(d Digest) (string, error) {
+	return n.renderText(n.templateData(d))
+}
+
+// RenderHTML returns the HTML body of the email about d.
+func (n *EmailNotifier) RenderHTML(d Digest) (string, error) {
+	return n.renderHTML(n.templateData(d))
+}
+
+// ReportData is passed to the weekly report templates.
+type ReportData struct {
+	Report
+	Links []Link
+}
+
+// Report implements Reporter with a summary email.
+func (n *EmailNotifier) Report(ctx context.Context, r Report) error {
+	n = n.WithKeyring()
+	if len(n.To) == 0 {
+		return errors.New("email: no recipients configured")
+	}
+
+	data := ReportData{Report: r, Links: n.Links}
+	text, err := renderText("report.txt.tmpl", "", data)
+	if err != nil {
+		return fmt.Errorf("email: rendering text report: %w", err)
+	}
+	html, err := renderHTML("report.html.tmpl", "", data, nil)
+	if err != nil {
+		return fmt.Errorf("email: rendering HTML report: %w", err)
+	}
+	subject := "Weekly Job Report: " + r.Start.Format("Jan 2") + " to " + r.End.Format("Jan 2")
+	return n.send(ctx, n.compose(subject, text, html))
+}
+
+// send delivers e, or queues it in the outbox if that fails.
+func (n *EmailNotifier) send(ctx context.Context, e *Email) error {
+	err := n.Deliver(ctx, e)
+	if err == nil || n.Outbox == nil {
+		return err
+	}
+	if qerr := n.Outbox.Queue(e, err); qerr != nil {
+		return errors.Join(err, fmt.Errorf("email: queueing for retry: %w", qerr))
+	}
+	slog.Warn("email not delivered; queued for retry", "subject", e.Subject, "err", err)
+	return nil
+}
+
+// Deliver sends e once through n's transport, bypassing the outbox.
+func (n *EmailNotifier) Deliver(ctx context.Context, e *Email) error {
+	n = n.WithKeyring()
+	t, err := n.transport()
+	if err != nil {
+		return fmt.Errorf("email: %w", err)
+	}
+	if n.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, n.Timeout)
+		defer cancel()
+	}
+	return t.Send(ctx, e)
+}
+
+// Validate checks that Provider names a known transport and Attach a
+// known format.
+func (n *EmailNotifier) Validate() error {
+	switch output.Format(strings.ToLower(n.Attach)) {
+	case "", output.CSV, output.JSON:
+	default:
+		return fmt.Errorf("email: unknown attach format %q (want csv or json)", n.Attach)
+	}
+	if _, err := n.transport(); err != nil {
+		return fmt.Errorf("email: %w", err)
+	}
+	return nil
+}
+
+// transport returns the transport that delivers n's mail.
+func (n *EmailNotifier) transport() (Transport, error) {
+	if n.Transport != nil {
+		return n.Transport, nil
+	}
+	switch strings.ToLower(n.Provider) {
+	case "", "smtp":
+		username := n.Username
+		if username == "" {
+			username = n.From
+		}
+		t := &SMTPTransport{
+			Host: n.Host, Port: n.Port, Username: username, Password: n.Password,
+			TLS: strings.ToLower(n.TLS), Auth: n.Auth, InsecureSkipVerify: n.TLSSkipVerify,
+		}
+		if n.OAuth2.Enabled() {
+			t.OAuth2 = &n.OAuth2
+		}
+		if n.DKIM.Enabled() {
+			t.DKIM = &n.DKIM
+		}
+		if err := t.Validate(); err != nil {
+			return nil, err
+		}
+		return t, nil
+	case "sendgrid":
+		return &n.SendGrid, nil
+	case "mailgun":
+		return &n.Mailgun, nil
+	case "ses":
+		return &n.SES, nil
+	default:
+		return nil, fmt.Errorf("unknown provider %q (want smtp, sendgrid, mailgun or ses)", n.Provider)
+	}
+}
+
+// message renders the digest as a text and HTML email.
+func (n *EmailNotifier) message(d Digest) (*Email, error) {
+	data := n.templateData(d)
+
+	text, err := n.renderText(data)
+	if err != nil {
+		return nil, fmt.Errorf("rendering text body: %w", err)
+	}
+	html, err := n.renderHTML(data)
+	if err != nil {
+		return nil, fmt.Errorf("rendering HTML body: %w", err)
+	}
+
+	e := n.compose(DigestSubject, text, html)
+	if n.Attach != "" && len(d.New) > 0 {
+		a, err := attachment(output.Format(strings.ToLower(n.Attach)), d.New, e.Date)
+		if err != nil {
+			return nil, fmt.Errorf("attaching the jobs: %w", err)
+		}
+		e.Attachments = []Attachment{a}
+	}
+	return e, nil
+}
+
+// attachment exports jobs as a file named for the day, e.g.
+// jobs-2025-06-01.csv.
+func attachment(f output.Format, jobs []scraper.JobPosting, day time.Time) (Attachment, error) {
+	var buf bytes.Buffer
+	if err := output.Write(&buf, f, jobs); err != nil {
+		return Attachment{}, err
+	}
+	contentType := "text/csv; charset=UTF-8"
+	if f == output.JSON {
+		contentType = "application/json"
+	}
+	return Attachment{
+		Filename:    "jobs-" + day.Format(time.DateOnly) + "." + string(f),
+		ContentType: contentType,
+		Data:        buf.Bytes(),
+	}, nil
+}
+
+// compose addresses an email from n to its recipients, dated now.
+func (n *EmailNotifier) compose(subject, text, html string) *Email {
+	return &Email{
+		From:      n.From,
+		To:        n.To,
+		Cc:        n.Cc,
+		Bcc:       n.Bcc,
+		ReplyTo:   n.ReplyTo,
+		Subject:   subject,
+		Date:      time.Now(),
+		MessageID: NewMessageID(n.From),
+		Text:      text,
+		HTML:      html,
+	}
+}
+
+// templateData is d as the email's templates see it, with n's links and
+// action links.
+func (n *EmailNotifier) templateData(d Digest) TemplateData {
+	data := NewTemplateData(d, n.Links)
+	data.actions = n.Actions
+	return data
+}
+
+func (n *EmailNotifier) renderText(data TemplateData) (string, error) {
+	return renderText("email.txt.tmpl", n.TextTemplate, data)
+}
+
+func (n *EmailNotifier) renderHTML(data TemplateData) (string, error) {
+	funcs := htmltemplate.FuncMap{"highlight": NewHighlighter(n.Highlight).HTML}
+	return renderHTML("email.html.tmpl", n.HTMLTemplate, data, funcs)
+}
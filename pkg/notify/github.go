@@ -0,0 +1,150 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/hunterheston/airbnb/pkg/scraper"
+)
+
+const githubAPI = "https://api.github.com"
+
+// githubLabelMax is the longest label name GitHub accepts.
+const githubLabelMax = 50
+
+// GitHubNotifier opens an issue per new job in a GitHub repository, for
+// tracking the search on a project board. Each issue has the job's title,
+// link and the start of its description, and is labeled with the job's
+// level and location on top of Labels; GitHub creates labels that don't
+// exist yet. Jobs whose link is already in an issue, open or closed, are
+// skipped, and closed jobs aren't touched. Send it only the best matches
+// with a channel filter, e.g. min_score.
+type GitHubNotifier struct {
+	// Token is a personal access token allowed to write the repository's
+	// issues.
+	Token string `yaml:"token"`
+	// Repo is the repository, "owner/name".
+	Repo string `yaml:"repo"`
+	// Labels are added to every issue. When set, only the issues with them
+	// are searched for links already opened.
+	Labels []string `yaml:"labels"`
+	// APIURL is the API's base URL, GitHub.com's unless set, e.g.
+	// https://github.example.com/api/v3 for GitHub Enterprise Server.
+	APIURL string `yaml:"api_url"`
+
+	Client *http.Client `yaml:"-"`
+}
+
+// Notify implements Notifier.
+func (n *GitHubNotifier) Notify(ctx context.Context, d Digest) error {
+	if n.Token == "" || n.Repo == "" {
+		return errors.New("github: token and repo must be configured")
+	}
+	if len(d.New) == 0 {
+		return nil
+	}
+	opened, err := n.opened(ctx)
+	if err != nil {
+		return fmt.Errorf("github: listing issues: %w", err)
+	}
+
+	var errs []error
+	for _, job := range d.New {
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, err)
+			break
+		}
+		if opened[job.URL] {
+			continue
+		}
+		if err := n.open(ctx, job); err != nil {
+			errs = append(errs, fmt.Errorf("github: %s: %w", job.URL, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// opened returns the links already in issues, open or closed: the lines
+// of their bodies, where an issue's link is on a line of its own.
+func (n *GitHubNotifier) opened(ctx context.Context) (map[string]bool, error) {
+	links := map[string]bool{}
+	for page := 1; ; page++ {
+		q := url.Values{"state": {"all"}, "per_page": {"100"}, "page": {fmt.Sprint(page)}}
+		if len(n.Labels) > 0 {
+			q.Set("labels", strings.Join(n.Labels, ","))
+		}
+		var issues []struct {
+			Body string `json:"body"`
+		}
+		if err := exchangeJSON(ctx, n.Client, http.MethodGet, n.repoURL("issues")+"?"+q.Encode(), nil, n.header(), &issues); err != nil {
+			return nil, err
+		}
+		for _, i := range issues {
+			for _, line := range strings.Split(i.Body, "\n") {
+				links[strings.TrimSpace(line)] = true
+			}
+		}
+		if len(issues) < 100 {
+			return links, nil
+		}
+	}
+}
+
+// open creates job's issue.
+func (n *GitHubNotifier) open(ctx context.Context, job scraper.JobPosting) error {
+	labels := append([]string{}, n.Labels...)
+	for _, l := range []string{job.Level, job.Location} {
+		if l = strings.TrimSpace(l); l != "" {
+			if r := []rune(l); len(r) > githubLabelMax {
+				l = string(r[:githubLabelMax])
+			}
+			labels = append(labels, l)
+		}
+	}
+	issue := map[string]any{
+		"title":  fmt.Sprintf("%s at %s", job.Title, job.Company),
+		"body":   githubBody(job),
+		"labels": labels,
+	}
+	return doJSON(ctx, n.Client, http.MethodPost, n.repoURL("issues"), issue, n.header())
+}
+
+// githubBody is an issue's Markdown body.
+func githubBody(job scraper.JobPosting) string {
+	var b strings.Builder
+	facts := []string{"**" + job.Company + "**"}
+	for _, s := range []string{job.Location, job.Team, job.Level, job.Salary()} {
+		if s != "" {
+			facts = append(facts, s)
+		}
+	}
+	fmt.Fprintf(&b, "%s\n\n%s\n", strings.Join(facts, " · "), job.URL)
+	if job.Description != "" {
+		b.WriteString("\n")
+		for _, line := range strings.Split(scraper.Excerpt(job.Description, 1000), "\n") {
+			fmt.Fprintf(&b, "> %s\n", line)
+		}
+	}
+	return b.String()
+}
+
+func (n *GitHubNotifier) repoURL(path string) string {
+	base := n.APIURL
+	if base == "" {
+		base = githubAPI
+	}
+	owner, name, _ := strings.Cut(n.Repo, "/")
+	return strings.TrimSuffix(base, "/") + "/repos/" + url.PathEscape(owner) + "/" + url.PathEscape(name) + "/" + path
+}
+
+func (n *GitHubNotifier) header() http.Header {
+	return http.Header{
+		"Authorization":        {"Bearer " + n.Token},
+		"Accept":               {"application/vnd.github+json"},
+		"X-Github-Api-Version": {"2022-11-28"},
+	}
+}
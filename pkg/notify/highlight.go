@@ -0,0 +1,79 @@
+package notify
+
+import (
+	"cmp"
+	htmltemplate "html/template"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// highlightStyle marks a keyword in the HTML email. Inline, since many mail
+// clients drop style sheets.
+const highlightStyle = "background: #fff3b0; color: inherit; padding: 0 2px; border-radius: 2px;"
+
+// Highlighter marks keywords in text for the HTML email. Keywords match
+// whole words only, ignoring case, so "Go" marks "Go" and "go," but not
+// "Google".
+type Highlighter struct {
+	re *regexp.Regexp
+}
+
+// NewHighlighter returns a Highlighter for keywords; with none, it only
+// escapes.
+func NewHighlighter(keywords []string) *Highlighter {
+	var alts []string
+	for _, kw := range keywords {
+		if kw = strings.TrimSpace(kw); kw != "" {
+			alts = append(alts, regexp.QuoteMeta(kw))
+		}
+	}
+	if len(alts) == 0 {
+		return &Highlighter{}
+	}
+	// Longest first, so "Go" doesn't cut "Golang" short.
+	slices.SortFunc(alts, func(a, b string) int { return cmp.Compare(len(b), len(a)) })
+	return &Highlighter{re: regexp.MustCompile(`(?i)` + strings.Join(alts, "|"))}
+}
+
+// HTML escapes s and wraps each keyword in it in a <mark>.
+func (h *Highlighter) HTML(s string) htmltemplate.HTML {
+	if h.re == nil {
+		return htmltemplate.HTML(htmltemplate.HTMLEscapeString(s))
+	}
+	var b strings.Builder
+	last := 0
+	for _, m := range h.re.FindAllStringIndex(s, -1) {
+		if !wordEdge(s, m[0], m[1]) {
+			continue
+		}
+		b.WriteString(htmltemplate.HTMLEscapeString(s[last:m[0]]))
+		b.WriteString(`<mark style="` + highlightStyle + `">`)
+		b.WriteString(htmltemplate.HTMLEscapeString(s[m[0]:m[1]]))
+		b.WriteString("</mark>")
+		last = m[1]
+	}
+	b.WriteString(htmltemplate.HTMLEscapeString(s[last:]))
+	return htmltemplate.HTML(b.String())
+}
+
+// wordEdge reports whether s[start:end] isn't part of a longer word: a
+// letter or digit at either end of the match mustn't continue into its
+// neighbour.
+func wordEdge(s string, start, end int) bool {
+	first, _ := utf8.DecodeRuneInString(s[start:end])
+	lastRune, _ := utf8.DecodeLastRuneInString(s[start:end])
+	if before, _ := utf8.DecodeLastRuneInString(s[:start]); start > 0 && isWord(first) && isWord(before) {
+		return false
+	}
+	if after, _ := utf8.DecodeRuneInString(s[end:]); end < len(s) && isWord(lastRune) && isWord(after) {
+		return false
+	}
+	return true
+}
+
+func isWord(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,89 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// postJSON sends payload as a JSON POST and treats any non-2xx response as
+// an error.
+func postJSON(ctx context.Context, client *http.Client, url string, payload any) error {
+	return doJSON(ctx, client, http.MethodPost, url, payload, nil)
+}
+
+// doJSON is postJSON with a choice of method and extra request headers.
+func doJSON(ctx context.Context, client *http.Client, method, url string, payload any, header http.Header) error {
+	return exchangeJSON(ctx, client, method, url, payload, header, nil)
+}
+
+// exchangeJSON is doJSON that also decodes the JSON response into result,
+// unless it is nil. A nil payload sends no body.
+func exchangeJSON(ctx context.Context, client *http.Client, method, url string, payload any, header http.Header, result any) error {
+	var body io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return decode(client, req, result)
+}
+
+// do sends req and treats any non-2xx response as an error.
+func do(client *http.Client, req *http.Request) error {
+	return decode(client, req, nil)
+}
+
+// decode is do that also decodes the JSON response into result, unless it
+// is nil.
+func decode(client *http.Client, req *http.Request, result any) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, bytes.TrimSpace(msg))
+	}
+	if result != nil {
+		if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+			return fmt.Errorf("decoding response: %w", err)
+		}
+	}
+	return nil
+}
+
+// sleepContext waits for d, or returns ctx's error if ctx is done first.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,168 @@
+package notify
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/hunterheston/airbnb/pkg/scraper"
+)
+
+// JiraNotifier creates an issue per new job in a Jira Cloud project, for
+// tracking applications there. Each issue is summarized with the job's
+// title, described with its link and the start of its description, and
+// fills in whichever of the custom fields in Fields are set. Every issue
+// is labeled with a jobwatch-... label derived from the job's ID, and a job
+// whose label is already on an issue of the project is skipped, so sending
+// a job twice doesn't create it twice; closed jobs aren't touched.
+type JiraNotifier struct {
+	// URL is the site, e.g. https://example.atlassian.net.
+	URL string `yaml:"url"`
+	// Email and APIToken are an Atlassian account's address and an API
+	// token of theirs, from https://id.atlassian.com/manage-profile/security/api-tokens.
+	Email    string `yaml:"email"`
+	APIToken string `yaml:"api_token"`
+	// Project is the key of the project issues are created in, e.g. "JOBS".
+	Project string `yaml:"project"`
+	// IssueType is the type of the issues, "Task" unless set.
+	IssueType string `yaml:"issue_type"`
+	// Labels are added to every issue.
+	Labels []string `yaml:"labels"`
+	// Fields are the IDs of the custom fields the job's details go in.
+	Fields JiraFields `yaml:"fields"`
+
+	Client *http.Client `yaml:"-"`
+}
+
+// JiraFields are the IDs, e.g. "customfield_10050", of custom fields. The
+// company, level and salary fields must be short text fields and the link
+// a URL field; those left empty aren't filled in.
+type JiraFields struct {
+	Company string `yaml:"company"`
+	Level   string `yaml:"level"`
+	Salary  string `yaml:"salary"`
+	Link    string `yaml:"link"`
+}
+
+// Notify implements Notifier.
+func (n *JiraNotifier) Notify(ctx context.Context, d Digest) error {
+	if n.URL == "" || n.Email == "" || n.APIToken == "" || n.Project == "" {
+		return errors.New("jira: url, email, api_token and project must be configured")
+	}
+	var errs []error
+	for _, job := range d.New {
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, err)
+			break
+		}
+		if err := n.add(ctx, job); err != nil {
+			errs = append(errs, fmt.Errorf("jira: %s: %w", job.URL, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// jiraLabel is the label that identifies job's issue: "jobwatch-" and a
+// hash of its Key, since labels can't hold the spaces and such of a URL.
+func jiraLabel(job scraper.JobPosting) string {
+	h := sha256.Sum256([]byte(job.Key()))
+	return "jobwatch-" + hex.EncodeToString(h[:6])
+}
+
+// add creates job's issue unless the project already has one.
+func (n *JiraNotifier) add(ctx context.Context, job scraper.JobPosting) error {
+	label := jiraLabel(job)
+	var found struct {
+		Issues []struct {
+			Key string `json:"key"`
+		} `json:"issues"`
+	}
+	query := map[string]any{
+		"jql":        fmt.Sprintf("project = %s AND labels = %s", jqlString(n.Project), jqlString(label)),
+		"fields":     []string{"key"},
+		"maxResults": 1,
+	}
+	if err := exchangeJSON(ctx, n.Client, http.MethodPost, n.api("search/jql"), query, n.header(), &found); err != nil {
+		return fmt.Errorf("searching the project: %w", err)
+	}
+	if len(found.Issues) > 0 {
+		return nil
+	}
+
+	issueType := n.IssueType
+	if issueType == "" {
+		issueType = "Task"
+	}
+	fields := map[string]any{
+		"project":     map[string]string{"key": n.Project},
+		"issuetype":   map[string]string{"name": issueType},
+		"summary":     jiraSummary(fmt.Sprintf("%s at %s", job.Title, job.Company)),
+		"description": jiraDescription(job),
+		"labels":      append(append([]string{}, n.Labels...), label),
+	}
+	for id, value := range map[string]string{
+		n.Fields.Company: job.Company,
+		n.Fields.Level:   job.Level,
+		n.Fields.Salary:  job.Salary(),
+		n.Fields.Link:    job.URL,
+	} {
+		if id != "" && value != "" {
+			fields[id] = value
+		}
+	}
+	if err := doJSON(ctx, n.Client, http.MethodPost, n.api("issue"), map[string]any{"fields": fields}, n.header()); err != nil {
+		return fmt.Errorf("creating the issue: %w", err)
+	}
+	return nil
+}
+
+func (n *JiraNotifier) api(path string) string {
+	return strings.TrimSuffix(n.URL, "/") + "/rest/api/3/" + path
+}
+
+func (n *JiraNotifier) header() http.Header {
+	auth := base64.StdEncoding.EncodeToString([]byte(n.Email + ":" + n.APIToken))
+	return http.Header{"Authorization": {"Basic " + auth}, "Accept": {"application/json"}}
+}
+
+// jqlString quotes s as a JQL string.
+func jqlString(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// jiraSummary fits s in a summary, which Jira caps at 255 characters.
+func jiraSummary(s string) string {
+	if r := []rune(s); len(r) > 255 {
+		s = string(r[:254]) + "…"
+	}
+	return s
+}
+
+// jiraDescription is job's description in the Atlassian Document Format
+// the API takes: a line of facts, the link, and the start of the posting's
+// description.
+func jiraDescription(job scraper.JobPosting) map[string]any {
+	text := func(s string) map[string]any { return map[string]any{"type": "text", "text": s} }
+	paragraph := func(content ...map[string]any) map[string]any {
+		return map[string]any{"type": "paragraph", "content": content}
+	}
+
+	facts := []string{job.Company}
+	for _, s := range []string{job.Location, job.Team, job.Level, job.Salary()} {
+		if s != "" {
+			facts = append(facts, s)
+		}
+	}
+	link := text(job.URL)
+	link["marks"] = []map[string]any{{"type": "link", "attrs": map[string]string{"href": job.URL}}}
+	content := []map[string]any{paragraph(text(strings.Join(facts, " · "))), paragraph(link)}
+	if job.Description != "" {
+		content = append(content, paragraph(text(scraper.Excerpt(job.Description, 1000))))
+	}
+	return map[string]any{"type": "doc", "version": 1, "content": content}
+}
@@ -0,0 +1,83 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"strings"
+)
+
+// MailgunTransport sends mail through the Mailgun messages API.
+type MailgunTransport struct {
+	// Domain is the sending domain configured in Mailgun.
+	Domain string `yaml:"domain"`
+	APIKey string `yaml:"api_key"`
+	// Region is "us" (the default) or "eu".
+	Region string `yaml:"region"`
+
+	Client *http.Client `yaml:"-"`
+}
+
+// Send implements Transport.
+func (t *MailgunTransport) Send(ctx context.Context, e *Email) error {
+	if t.Domain == "" || t.APIKey == "" {
+		return errors.New("mailgun: domain and api_key must be configured")
+	}
+
+	host := "api.mailgun.net"
+	if strings.EqualFold(t.Region, "eu") {
+		host = "api.eu.mailgun.net"
+	}
+
+	// The form is multipart so that it can carry attachments.
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	form.WriteField("from", e.From)
+	for _, to := range e.To {
+		form.WriteField("to", to)
+	}
+	for _, cc := range e.Cc {
+		form.WriteField("cc", cc)
+	}
+	for _, bcc := range e.Bcc {
+		form.WriteField("bcc", bcc)
+	}
+	if e.ReplyTo != "" {
+		form.WriteField("h:Reply-To", e.ReplyTo)
+	}
+	form.WriteField("subject", e.Subject)
+	form.WriteField("text", e.Text)
+	if e.HTML != "" {
+		form.WriteField("html", e.HTML)
+	}
+	for _, a := range e.Attachments {
+		w, err := form.CreatePart(textproto.MIMEHeader{
+			"Content-Disposition": {mime.FormatMediaType("form-data", map[string]string{"name": "attachment", "filename": a.Filename})},
+			"Content-Type":        {a.ContentType},
+		})
+		if err != nil {
+			return err
+		}
+		w.Write(a.Data)
+	}
+	form.Close()
+
+	endpoint := "https://" + host + "/v3/" + url.PathEscape(t.Domain) + "/messages"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	req.SetBasicAuth("api", t.APIKey)
+
+	if err := do(t.Client, req); err != nil {
+		return fmt.Errorf("mailgun: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/hunterheston/airbnb/pkg/scraper"
+)
+
+// matrixMaxJobs is how many jobs go in one message, which keeps events well
+// under the 64 KB homeservers accept.
+const matrixMaxJobs = 50
+
+// matrixTxn numbers the messages sent by this process; together with the
+// start time it makes each transaction ID unique.
+var matrixTxn atomic.Int64
+
+// MatrixNotifier sends the digest to a Matrix room as formatted messages.
+// The account the token belongs to must have joined the room.
+type MatrixNotifier struct {
+	// Homeserver is the client API base URL, e.g. https://matrix.org.
+	Homeserver string `yaml:"homeserver"`
+	// AccessToken authenticates the sending account, preferably a bot's.
+	AccessToken string `yaml:"access_token"`
+	// RoomID is the room's internal ID, e.g. !abcdefg:matrix.org, not an
+	// alias.
+	RoomID string `yaml:"room_id"`
+
+	Client *http.Client `yaml:"-"`
+}
+
+type matrixMessage struct {
+	MsgType       string `json:"msgtype"`
+	Body          string `json:"body"`
+	Format        string `json:"format,omitempty"`
+	FormattedBody string `json:"formatted_body,omitempty"`
+}
+
+// Notify implements Notifier. New jobs are sent 50 to a message, followed
+// by a list of the closed ones.
+func (n *MatrixNotifier) Notify(ctx context.Context, d Digest) error {
+	if err := n.validate(); err != nil {
+		return err
+	}
+	var plain, rich strings.Builder
+	if len(d.Partial) > 0 {
+		plain.WriteString("⚠️ " + partialNotice + "\n")
+		rich.WriteString("<p>⚠️ <em>" + partialNotice + "</em></p>")
+	}
+	if len(d.New) == 0 {
+		plain.WriteString("No new job postings found today.")
+		rich.WriteString("<p>No new job postings found today.</p>")
+		if err := n.send(ctx, plain.String(), rich.String()); err != nil {
+			return err
+		}
+		plain.Reset()
+		rich.Reset()
+	}
+	for start := 0; start < len(d.New); start += matrixMaxJobs {
+		if start == 0 {
+			fmt.Fprintf(&plain, "%d new job postings:\n", len(d.New))
+			fmt.Fprintf(&rich, "<p><strong>%d new job postings</strong></p>", len(d.New))
+		}
+		rich.WriteString("<ul>")
+		for _, job := range d.New[start:min(start+matrixMaxJobs, len(d.New))] {
+			fmt.Fprintf(&plain, "• %s — %s\n", matrixLine(job), job.URL)
+			fmt.Fprintf(&rich, "<li><a href=\"%s\">%s</a> — %s</li>",
+				html.EscapeString(job.URL), html.EscapeString(job.Title), html.EscapeString(matrixDetails(job)))
+		}
+		rich.WriteString("</ul>")
+		if err := n.send(ctx, plain.String(), rich.String()); err != nil {
+			return err
+		}
+		plain.Reset()
+		rich.Reset()
+	}
+
+	if len(d.Closed) > 0 {
+		plain.WriteString("Closed since last run:\n")
+		rich.WriteString("<p><strong>Closed since last run:</strong></p><ul>")
+		for _, job := range d.Closed {
+			fmt.Fprintf(&plain, "• %s (%s)\n", job.Title, job.Company)
+			fmt.Fprintf(&rich, "<li><del>%s</del> (%s)</li>", html.EscapeString(job.Title), html.EscapeString(job.Company))
+		}
+		rich.WriteString("</ul>")
+		return n.send(ctx, plain.String(), rich.String())
+	}
+	return nil
+}
+
+// Alert implements Alerter.
+func (n *MatrixNotifier) Alert(ctx context.Context, msg string) error {
+	if err := n.validate(); err != nil {
+		return err
+	}
+	return n.send(ctx, "⚠️ "+msg, "⚠️ "+html.EscapeString(msg))
+}
+
+func (n *MatrixNotifier) validate() error {
+	if n.Homeserver == "" || n.AccessToken == "" || n.RoomID == "" {
+		return errors.New("matrix: homeserver, access_token and room_id must be configured")
+	}
+	return nil
+}
+
+// send posts an m.text message with both a plain and an HTML body, for
+// clients that don't render HTML.
+func (n *MatrixNotifier) send(ctx context.Context, plain, rich string) error {
+	txn := fmt.Sprintf("jobwatch-%d-%d", time.Now().UnixNano(), matrixTxn.Add(1))
+	endpoint := strings.TrimSuffix(n.Homeserver, "/") + "/_matrix/client/v3/rooms/" +
+		url.PathEscape(n.RoomID) + "/send/m.room.message/" + txn
+	msg := matrixMessage{
+		MsgType:       "m.text",
+		Body:          strings.TrimSpace(plain),
+		Format:        "org.matrix.custom.html",
+		FormattedBody: rich,
+	}
+	header := http.Header{"Authorization": {"Bearer " + n.AccessToken}}
+	if err := doJSON(ctx, n.Client, http.MethodPut, endpoint, msg, header); err != nil {
+		return fmt.Errorf("matrix: %w", err)
+	}
+	return nil
+}
+
+// matrixLine is a job's title followed by its details, in plain text.
+func matrixLine(job scraper.JobPosting) string {
+	return job.Title + " — " + matrixDetails(job)
+}
+
+// matrixDetails is a job's company, location, salary and age.
+func matrixDetails(job scraper.JobPosting) string {
+	details := job.Company
+	if job.Location != "" {
+		details += " · " + job.Location
+	}
+	if s := job.Salary(); s != "" {
+		details += " · " + s
+	}
+	if a := job.Age(); a != "" {
+		details += " · " + a
+	}
+	return details
+}
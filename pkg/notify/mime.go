@@ -0,0 +1,143 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/base64"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"strings"
+)
+
+// messageBuilder assembles a message in Internet Message Format: header
+// fields encoded per RFC 2047 where they aren't plain ASCII, and bodies
+// quoted-printable so that any UTF-8 text survives 7-bit relays.
+type messageBuilder struct {
+	header bytes.Buffer
+}
+
+// mimePart is one body of a message.
+type mimePart struct {
+	contentType string
+	body        string
+}
+
+// Header adds an unstructured field such as Subject, encoding any non-ASCII
+// text. A long encoded value is folded between its encoded words.
+func (b *messageBuilder) Header(name, value string) {
+	value = mime.QEncoding.Encode("UTF-8", value)
+	b.field(name, strings.ReplaceAll(value, "?= =?", "?=\r\n =?"))
+}
+
+// Addresses adds an address field such as To. Display names are encoded
+// where needed; an address that doesn't parse is written as given.
+func (b *messageBuilder) Addresses(name string, addrs []string) {
+	encoded := make([]string, len(addrs))
+	for i, addr := range addrs {
+		if a, err := mail.ParseAddress(addr); err == nil {
+			encoded[i] = a.String()
+		} else {
+			encoded[i] = addr
+		}
+	}
+	b.field(name, strings.Join(encoded, ",\r\n "))
+}
+
+// field adds a header field as is.
+func (b *messageBuilder) field(name, value string) {
+	b.header.WriteString(name + ": " + value + "\r\n")
+}
+
+// Attachment is a file attached to an email.
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+// Build returns the message with parts as its body, multipart/alternative
+// when there is more than one, in order of preference from least to most.
+// With attachments the body and the files are sent as multipart/mixed.
+func (b *messageBuilder) Build(parts []mimePart, attachments ...Attachment) []byte {
+	var msg bytes.Buffer
+	msg.Write(b.header.Bytes())
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	header, content := entity(parts)
+	if len(attachments) > 0 {
+		var mixed bytes.Buffer
+		mw := multipart.NewWriter(&mixed)
+		w, _ := mw.CreatePart(header)
+		w.Write(content)
+		for _, a := range attachments {
+			mediaType, params, err := mime.ParseMediaType(a.ContentType)
+			if err != nil {
+				mediaType, params = "application/octet-stream", map[string]string{}
+			}
+			params["name"] = a.Filename
+			w, _ := mw.CreatePart(textproto.MIMEHeader{
+				"Content-Type":              {mime.FormatMediaType(mediaType, params)},
+				"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": a.Filename})},
+				"Content-Transfer-Encoding": {"base64"},
+			})
+			w.Write(base64Lines(a.Data))
+		}
+		mw.Close()
+		header = textproto.MIMEHeader{"Content-Type": {"multipart/mixed; boundary=" + mw.Boundary()}}
+		content = mixed.Bytes()
+	}
+	for _, name := range []string{"Content-Type", "Content-Transfer-Encoding"} {
+		if v := header.Get(name); v != "" {
+			msg.WriteString(name + ": " + v + "\r\n")
+		}
+	}
+	msg.WriteString("\r\n")
+	msg.Write(content)
+	return msg.Bytes()
+}
+
+// entity returns the header fields and encoded content of a body made of
+// parts: the part itself if there is one, else multipart/alternative.
+func entity(parts []mimePart) (textproto.MIMEHeader, []byte) {
+	if len(parts) == 1 {
+		return parts[0].header(), quotedPrintable(parts[0].body)
+	}
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for _, part := range parts {
+		w, _ := mw.CreatePart(part.header())
+		w.Write(quotedPrintable(part.body))
+	}
+	mw.Close()
+	return textproto.MIMEHeader{"Content-Type": {"multipart/alternative; boundary=" + mw.Boundary()}}, body.Bytes()
+}
+
+func (p mimePart) header() textproto.MIMEHeader {
+	return textproto.MIMEHeader{
+		"Content-Type":              {p.contentType},
+		"Content-Transfer-Encoding": {"quoted-printable"},
+	}
+}
+
+// base64Lines encodes data in base64 lines of 76 characters, as MIME
+// requires.
+func base64Lines(data []byte) []byte {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	var b bytes.Buffer
+	for len(encoded) > 76 {
+		b.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	b.WriteString(encoded + "\r\n")
+	return b.Bytes()
+}
+
+// quotedPrintable encodes s with CRLF line breaks.
+func quotedPrintable(s string) []byte {
+	var b bytes.Buffer
+	w := quotedprintable.NewWriter(&b)
+	w.Write([]byte(s))
+	w.Close()
+	return b.Bytes()
+}
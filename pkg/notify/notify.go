@@ -0,0 +1,132 @@
+// Package notify delivers job postings to the user.
+package notify
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/hunterheston/airbnb/pkg/filter"
+	"github.com/hunterheston/airbnb/pkg/scraper"
+)
+
+// Digest is what a notification reports.
+type Digest struct {
+	// New holds the jobs posted since the last digest.
+	New []scraper.JobPosting
+	// Closed holds previously announced jobs that are no longer listed.
+	Closed []scraper.JobPosting
+	// Partial lists what failed in the scrapes since the last digest, one
+	// line per failure. When it's set some jobs may be missing.
+	Partial []string
+	// Stats sum up the scrapes since the last digest, when they are known.
+	Stats Stats
+}
+
+// Stats count what the scrapes behind a digest did.
+type Stats struct {
+	// Runs is the number of scrapes since Since, the time of the last
+	// digest or zero if there was none.
+	Runs  int
+	Since time.Time
+	// Pages, Found and Matched add up the runs' pages fetched, jobs scraped
+	// and jobs that passed the filters.
+	Pages   int
+	Found   int
+	Matched int
+}
+
+// Empty reports whether the digest has nothing to say.
+func (d Digest) Empty() bool {
+	return len(d.New) == 0 && len(d.Closed) == 0 && len(d.Partial) == 0
+}
+
+// partialNotice is the banner the chat notifiers show for a partial digest;
+// the email lists the failures themselves.
+const partialNotice = "The scrape was partial, so some job postings may be missing."
+
+// Notifier sends a digest somewhere.
+type Notifier interface {
+	Notify(ctx context.Context, d Digest) error
+}
+
+// Renderer is implemented by notifiers that can show what they would send
+// for a digest without sending it.
+type Renderer interface {
+	Render(d Digest) (string, error)
+}
+
+// Alerter is implemented by notifiers that can also deliver a short
+// operational message, such as a warning that the scraper looks broken.
+type Alerter interface {
+	Alert(ctx context.Context, msg string) error
+}
+
+// Alert sends msg through n if it is an Alerter and does nothing otherwise.
+func Alert(ctx context.Context, n Notifier, msg string) error {
+	if a, ok := n.(Alerter); ok {
+		return a.Alert(ctx, msg)
+	}
+	return nil
+}
+
+// Multi sends to every notifier in turn. One failing notifier doesn't stop
+// the others; all errors are returned together.
+type Multi []Notifier
+
+// Notify implements Notifier.
+func (m Multi) Notify(ctx context.Context, d Digest) error {
+	var errs []error
+	for _, n := range m {
+		if err := n.Notify(ctx, d); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Alert implements Alerter.
+func (m Multi) Alert(ctx context.Context, msg string) error {
+	var errs []error
+	for _, n := range m {
+		if err := Alert(ctx, n, msg); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Filtered sends only the jobs Filter matches to Notifier. Partial is passed
+// on as is, since a failed scrape may have hidden matching jobs too.
+type Filtered struct {
+	Notifier Notifier
+	Filter   filter.Filter
+}
+
+// Notify implements Notifier.
+func (f Filtered) Notify(ctx context.Context, d Digest) error {
+	return f.Notifier.Notify(ctx, f.apply(d))
+}
+
+// Render implements Renderer if the filtered notifier does.
+func (f Filtered) Render(d Digest) (string, error) {
+	r, ok := f.Notifier.(Renderer)
+	if !ok {
+		return "", errors.New("the notifier can't render digests")
+	}
+	return r.Render(f.apply(d))
+}
+
+func (f Filtered) apply(d Digest) Digest {
+	return Digest{
+		New:     filter.Apply(f.Filter, d.New),
+		Closed:  filter.Apply(f.Filter, d.Closed),
+		Partial: d.Partial,
+		Stats:   d.Stats,
+	}
+}
+
+// Alert implements Alerter. Alerts aren't filtered.
+func (f Filtered) Alert(ctx context.Context, msg string) error {
+	return Alert(ctx, f.Notifier, msg)
+}
@@ -0,0 +1,118 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/hunterheston/airbnb/pkg/scraper"
+)
+
+const (
+	notionAPI     = "https://api.notion.com/v1/"
+	notionVersion = "2022-06-28"
+)
+
+// NotionNotifier creates a page per new job in a Notion database, for
+// tracking applications there. The database needs these properties:
+//
+//	Name      title
+//	Company   text
+//	Level     select
+//	Location  text
+//	Link      URL
+//	Status    select
+//
+// New pages get the status "New". Jobs whose link is already in the
+// database are skipped, and closed jobs aren't touched.
+type NotionNotifier struct {
+	// Token is the secret of an internal integration the database is
+	// shared with.
+	Token string `yaml:"token"`
+	// DatabaseID is the 32-character ID in the database's URL.
+	DatabaseID string `yaml:"database_id"`
+
+	Client *http.Client `yaml:"-"`
+}
+
+// Notify implements Notifier.
+func (n *NotionNotifier) Notify(ctx context.Context, d Digest) error {
+	if n.Token == "" || n.DatabaseID == "" {
+		return errors.New("notion: token and database_id must be configured")
+	}
+	var errs []error
+	for _, job := range d.New {
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, err)
+			break
+		}
+		if err := n.add(ctx, job); err != nil {
+			errs = append(errs, fmt.Errorf("notion: %s: %w", job.URL, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// add creates job's page unless the database already links to it.
+func (n *NotionNotifier) add(ctx context.Context, job scraper.JobPosting) error {
+	var found struct {
+		Results []struct {
+			ID string `json:"id"`
+		} `json:"results"`
+	}
+	query := map[string]any{
+		"filter":    map[string]any{"property": "Link", "url": map[string]string{"equals": job.URL}},
+		"page_size": 1,
+	}
+	endpoint := notionAPI + "databases/" + url.PathEscape(n.DatabaseID) + "/query"
+	if err := exchangeJSON(ctx, n.Client, http.MethodPost, endpoint, query, n.header(), &found); err != nil {
+		return fmt.Errorf("querying the database: %w", err)
+	}
+	if len(found.Results) > 0 {
+		return nil
+	}
+
+	props := map[string]any{
+		"Name":     map[string]any{"title": notionText(job.Title)},
+		"Company":  map[string]any{"rich_text": notionText(job.Company)},
+		"Location": map[string]any{"rich_text": notionText(job.Location)},
+		"Link":     map[string]any{"url": job.URL},
+		"Status":   notionSelect("New"),
+	}
+	if job.Level != "" {
+		props["Level"] = notionSelect(job.Level)
+	}
+	page := map[string]any{
+		"parent":     map[string]string{"database_id": n.DatabaseID},
+		"properties": props,
+	}
+	if err := doJSON(ctx, n.Client, http.MethodPost, notionAPI+"pages", page, n.header()); err != nil {
+		return fmt.Errorf("creating the page: %w", err)
+	}
+	return nil
+}
+
+func (n *NotionNotifier) header() http.Header {
+	return http.Header{
+		"Authorization":  {"Bearer " + n.Token},
+		"Notion-Version": {notionVersion},
+	}
+}
+
+// notionText is the rich text value for s. Notion caps text at 2000
+// characters.
+func notionText(s string) []map[string]any {
+	if s == "" {
+		return []map[string]any{}
+	}
+	if r := []rune(s); len(r) > 2000 {
+		s = string(r[:2000])
+	}
+	return []map[string]any{{"text": map[string]string{"content": s}}}
+}
+
+func notionSelect(name string) map[string]any {
+	return map[string]any{"select": map[string]string{"name": name}}
+}
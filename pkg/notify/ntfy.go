@@ -0,0 +1,97 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/hunterheston/airbnb/pkg/scraper"
+)
+
+// NtfyNotifier publishes job postings to an ntfy topic, one push
+// notification per new job. Closed jobs aren't pushed.
+type NtfyNotifier struct {
+	// Server defaults to https://ntfy.sh.
+	Server string `yaml:"server"`
+	Topic  string `yaml:"topic"`
+	// Token is an access token for protected topics.
+	Token string `yaml:"token"`
+	// Priority is ntfy's 1 (min) to 5 (max); 0 leaves the server default.
+	Priority int `yaml:"priority"`
+
+	Client *http.Client `yaml:"-"`
+}
+
+type ntfyMessage struct {
+	Topic    string   `json:"topic"`
+	Title    string   `json:"title,omitempty"`
+	Message  string   `json:"message"`
+	Click    string   `json:"click,omitempty"`
+	Priority int      `json:"priority,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+}
+
+// Notify implements Notifier.
+func (n *NtfyNotifier) Notify(ctx context.Context, d Digest) error {
+	if n.Topic == "" {
+		return errors.New("ntfy: topic is not configured")
+	}
+	for _, job := range d.New {
+		msg := ntfyMessage{
+			Title:   job.Title,
+			Message: pushText(job),
+			Click:   job.URL,
+			Tags:    []string{"briefcase"},
+		}
+		if err := n.publish(ctx, msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Alert implements Alerter.
+func (n *NtfyNotifier) Alert(ctx context.Context, msg string) error {
+	if n.Topic == "" {
+		return errors.New("ntfy: topic is not configured")
+	}
+	return n.publish(ctx, ntfyMessage{Title: "Job scraper alert", Message: msg, Tags: []string{"warning"}})
+}
+
+func (n *NtfyNotifier) publish(ctx context.Context, msg ntfyMessage) error {
+	server := n.Server
+	if server == "" {
+		server = "https://ntfy.sh"
+	}
+	msg.Topic = n.Topic
+	msg.Priority = n.Priority
+
+	header := http.Header{}
+	if n.Token != "" {
+		header.Set("Authorization", "Bearer "+n.Token)
+	}
+	if err := doJSON(ctx, n.Client, http.MethodPost, strings.TrimSuffix(server, "/"), msg, header); err != nil {
+		return fmt.Errorf("ntfy: %w", err)
+	}
+	return nil
+}
+
+// pushText is the body of a push notification about job, below its title.
+func pushText(job scraper.JobPosting) string {
+	text := job.Company
+	if job.Location != "" {
+		text += " · " + job.Location
+	}
+	if s := job.Salary(); s != "" {
+		text += " · " + s
+	}
+	if a := job.Age(); a != "" {
+		text += " · " + a
+	}
+	if job.Score != 0 {
+		text += fmt.Sprintf(" · score %d", job.Score)
+	}
+	return text
+}
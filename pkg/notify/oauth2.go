@@ -0,0 +1,145 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/smtp"
+	"net/url"
+	"os"
+	"strings"
+)
+
+const googleTokenURL = "https://oauth2.googleapis.com/token"
+
+// OAuth2Config holds the credentials for SMTP XOAUTH2. Each send exchanges
+// the long-lived refresh token for a short-lived access token.
+type OAuth2Config struct {
+	ClientID     string `yaml:"client_id"`
+	ClientSecret string `yaml:"client_secret"`
+	RefreshToken string `yaml:"refresh_token"`
+	// RefreshTokenFile is read when RefreshToken is empty, so the token can
+	// be kept out of the config file. It must not be readable by group or
+	// others.
+	RefreshTokenFile string `yaml:"refresh_token_file"`
+	// TokenURL defaults to Google's token endpoint.
+	TokenURL string `yaml:"token_url"`
+
+	Client *http.Client `yaml:"-"`
+}
+
+// Enabled reports whether XOAUTH2 is configured.
+func (c *OAuth2Config) Enabled() bool {
+	return c.ClientID != ""
+}
+
+func (c *OAuth2Config) refreshToken() (string, error) {
+	if c.RefreshToken != "" {
+		return c.RefreshToken, nil
+	}
+	if c.RefreshTokenFile == "" {
+		return "", errors.New("oauth2: refresh_token or refresh_token_file must be set")
+	}
+
+	info, err := os.Stat(c.RefreshTokenFile)
+	if err != nil {
+		return "", fmt.Errorf("oauth2: %w", err)
+	}
+	if info.Mode().Perm()&0o077 != 0 {
+		return "", fmt.Errorf("oauth2: %s is accessible by other users; chmod 600 it", c.RefreshTokenFile)
+	}
+	data, err := os.ReadFile(c.RefreshTokenFile)
+	if err != nil {
+		return "", fmt.Errorf("oauth2: %w", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// AccessToken exchanges the refresh token for a new access token.
+func (c *OAuth2Config) AccessToken(ctx context.Context) (string, error) {
+	refresh, err := c.refreshToken()
+	if err != nil {
+		return "", err
+	}
+
+	tokenURL := c.TokenURL
+	if tokenURL == "" {
+		tokenURL = googleTokenURL
+	}
+	client := c.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	form := url.Values{
+		"client_id":     {c.ClientID},
+		"client_secret": {c.ClientSecret},
+		"refresh_token": {refresh},
+		"grant_type":    {"refresh_token"},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("oauth2: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("oauth2: refreshing token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		AccessToken      string `json:"access_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("oauth2: refreshing token: HTTP %d: %w", resp.StatusCode, err)
+	}
+	if body.Error != "" {
+		return "", fmt.Errorf("oauth2: refreshing token: %s: %s", body.Error, body.ErrorDescription)
+	}
+	if body.AccessToken == "" {
+		return "", fmt.Errorf("oauth2: refreshing token: HTTP %d with no access_token", resp.StatusCode)
+	}
+	return body.AccessToken, nil
+}
+
+// xoauth2Auth implements the SMTP XOAUTH2 mechanism used by Gmail and
+// Outlook.
+type xoauth2Auth struct {
+	username, token, host string
+}
+
+// XOAuth2Auth returns an smtp.Auth that logs in as username with an OAuth2
+// access token. Like smtp.PlainAuth it refuses to send the token over an
+// unencrypted connection to anything but localhost.
+func XOAuth2Auth(username, token, host string) smtp.Auth {
+	return &xoauth2Auth{username: username, token: token, host: host}
+}
+
+func (a *xoauth2Auth) Start(server *smtp.ServerInfo) (string, []byte, error) {
+	if !server.TLS && !isLocalhost(server.Name) {
+		return "", nil, errors.New("unencrypted connection")
+	}
+	if server.Name != a.host {
+		return "", nil, errors.New("wrong host name")
+	}
+	resp := "user=" + a.username + "\x01auth=Bearer " + a.token + "\x01\x01"
+	return "XOAUTH2", []byte(resp), nil
+}
+
+func (a *xoauth2Auth) Next(fromServer []byte, more bool) ([]byte, error) {
+	if more {
+		// The server sends a JSON error as a challenge; an empty reply
+		// makes it finish the exchange with the actual failure.
+		return []byte{}, nil
+	}
+	return nil, nil
+}
+
+func isLocalhost(name string) bool {
+	return name == "localhost" || name == "127.0.0.1" || name == "::1"
+}
@@ -0,0 +1,76 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const pushoverAPI = "https://api.pushover.net/1/messages.json"
+
+// PushoverNotifier sends job postings through Pushover, one push
+// notification per new job. Closed jobs aren't pushed.
+type PushoverNotifier struct {
+	// Token is the application's API token and User the user or group key
+	// to deliver to.
+	Token string `yaml:"token"`
+	User  string `yaml:"user"`
+	// Priority is Pushover's -2 (silent) to 1 (high); emergency priority
+	// isn't supported.
+	Priority int `yaml:"priority"`
+
+	Client *http.Client `yaml:"-"`
+}
+
+// Notify implements Notifier.
+func (n *PushoverNotifier) Notify(ctx context.Context, d Digest) error {
+	if n.Token == "" || n.User == "" {
+		return errors.New("pushover: token and user must be configured")
+	}
+	for _, job := range d.New {
+		form := url.Values{}
+		form.Set("title", job.Title)
+		form.Set("message", pushText(job))
+		if job.URL != "" {
+			form.Set("url", job.URL)
+			form.Set("url_title", "Open posting")
+		}
+		if err := n.send(ctx, form); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Alert implements Alerter.
+func (n *PushoverNotifier) Alert(ctx context.Context, msg string) error {
+	if n.Token == "" || n.User == "" {
+		return errors.New("pushover: token and user must be configured")
+	}
+	form := url.Values{}
+	form.Set("title", "Job scraper alert")
+	form.Set("message", msg)
+	return n.send(ctx, form)
+}
+
+func (n *PushoverNotifier) send(ctx context.Context, form url.Values) error {
+	form.Set("token", n.Token)
+	form.Set("user", n.User)
+	if n.Priority != 0 {
+		form.Set("priority", strconv.Itoa(min(n.Priority, 1)))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, pushoverAPI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if err := do(n.Client, req); err != nil {
+		return fmt.Errorf("pushover: %w", err)
+	}
+	return nil
+}
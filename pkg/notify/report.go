@@ -0,0 +1,155 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hunterheston/airbnb/pkg/filter"
+	"github.com/hunterheston/airbnb/pkg/level"
+	"github.com/hunterheston/airbnb/pkg/scraper"
+)
+
+// Report summarizes a period, usually a week, of job postings.
+type Report struct {
+	Start, End time.Time
+	// Opened and Closed hold the jobs first seen and no longer listed
+	// during the period; Open holds every job still listed at its end.
+	Opened []ReportJob
+	Closed []ReportJob
+	Open   []ReportJob
+	// Runs counts the scrapes during the period and FailedRuns the ones
+	// among them that failed at least in part.
+	Runs       int
+	FailedRuns int
+}
+
+// ReportJob is a job posting with the dates a report needs.
+type ReportJob struct {
+	scraper.JobPosting
+	FirstSeen time.Time
+	// ClosedAt is zero for a job that is still listed.
+	ClosedAt time.Time
+}
+
+// Days returns how long the job has been or was listed, as of end for open
+// jobs.
+func (j ReportJob) Days(end time.Time) float64 {
+	if !j.ClosedAt.IsZero() {
+		end = j.ClosedAt
+	}
+	return end.Sub(j.FirstSeen).Hours() / 24
+}
+
+// Count is the number of jobs under a name, such as a level or location.
+type Count struct {
+	Name string
+	Jobs int
+}
+
+// DaysOnMarket returns the average number of days the closed jobs were
+// listed, or 0 if none closed.
+func (r Report) DaysOnMarket() float64 {
+	if len(r.Closed) == 0 {
+		return 0
+	}
+	var total float64
+	for _, j := range r.Closed {
+		total += j.Days(r.End)
+	}
+	return total / float64(len(r.Closed))
+}
+
+// ByLevel counts the open jobs by seniority, most junior first.
+func (r Report) ByLevel() []Count {
+	counts := map[level.Level]int{}
+	for _, j := range r.Open {
+		l, err := level.Parse(j.Level)
+		if err != nil {
+			l = level.Default().Classify(j.Title)
+		}
+		counts[l]++
+	}
+	levels := make([]level.Level, 0, len(counts))
+	for l := range counts {
+		levels = append(levels, l)
+	}
+	sort.Slice(levels, func(a, b int) bool { return levels[a] < levels[b] })
+
+	var out []Count
+	for _, l := range levels {
+		out = append(out, Count{Name: l.String(), Jobs: counts[l]})
+	}
+	return out
+}
+
+// ByLocation counts the open jobs by location, most common first. A job
+// listed in several places counts once for each.
+func (r Report) ByLocation() []Count {
+	counts := map[string]int{}
+	for _, j := range r.Open {
+		if j.Location == "" {
+			counts["Unspecified"]++
+			continue
+		}
+		for _, place := range strings.Split(j.Location, " / ") {
+			counts[place]++
+		}
+	}
+	var out []Count
+	for name, n := range counts {
+		out = append(out, Count{Name: name, Jobs: n})
+	}
+	sort.Slice(out, func(a, b int) bool {
+		if out[a].Jobs != out[b].Jobs {
+			return out[a].Jobs > out[b].Jobs
+		}
+		return out[a].Name < out[b].Name
+	})
+	return out
+}
+
+// Reporter is implemented by notifiers that can deliver a Report.
+type Reporter interface {
+	Report(ctx context.Context, r Report) error
+}
+
+// SendReport sends r through n if it is a Reporter and does nothing
+// otherwise.
+func SendReport(ctx context.Context, n Notifier, r Report) error {
+	if rep, ok := n.(Reporter); ok {
+		return rep.Report(ctx, r)
+	}
+	return nil
+}
+
+// Report implements Reporter.
+func (m Multi) Report(ctx context.Context, r Report) error {
+	var errs []error
+	for _, n := range m {
+		if err := SendReport(ctx, n, r); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Report implements Reporter, leaving out the jobs Filter doesn't match.
+func (f Filtered) Report(ctx context.Context, r Report) error {
+	r.Opened = filterReportJobs(f.Filter, r.Opened)
+	r.Closed = filterReportJobs(f.Filter, r.Closed)
+	r.Open = filterReportJobs(f.Filter, r.Open)
+	return SendReport(ctx, f.Notifier, r)
+}
+
+func filterReportJobs(f filter.Filter, jobs []ReportJob) []ReportJob {
+	var out []ReportJob
+	for _, j := range jobs {
+		if f.Match(j.JobPosting) {
+			out = append(out, j)
+		}
+	}
+	return out
+}
@@ -0,0 +1,95 @@
+package notify
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+const sendGridAPI = "https://api.sendgrid.com/v3/mail/send"
+
+// SendGridTransport sends mail through the SendGrid v3 API.
+type SendGridTransport struct {
+	APIKey string `yaml:"api_key"`
+
+	Client *http.Client `yaml:"-"`
+}
+
+type sendGridAddress struct {
+	Email string `json:"email"`
+}
+
+type sendGridContent struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+type sendGridAttachment struct {
+	// Content is base64.
+	Content  string `json:"content"`
+	Filename string `json:"filename"`
+	Type     string `json:"type"`
+}
+
+type sendGridPersonalization struct {
+	To  []sendGridAddress `json:"to,omitempty"`
+	Cc  []sendGridAddress `json:"cc,omitempty"`
+	Bcc []sendGridAddress `json:"bcc,omitempty"`
+}
+
+type sendGridMail struct {
+	Personalizations []sendGridPersonalization `json:"personalizations"`
+	From             sendGridAddress           `json:"from"`
+	ReplyTo          *sendGridAddress          `json:"reply_to,omitempty"`
+	Subject          string                    `json:"subject"`
+	Content          []sendGridContent         `json:"content"`
+	Attachments      []sendGridAttachment      `json:"attachments,omitempty"`
+}
+
+// Send implements Transport.
+func (t *SendGridTransport) Send(ctx context.Context, e *Email) error {
+	if t.APIKey == "" {
+		return errors.New("sendgrid: api_key is not configured")
+	}
+
+	mail := sendGridMail{
+		From:    sendGridAddress{Email: e.From},
+		Subject: e.Subject,
+		Content: []sendGridContent{{Type: "text/plain", Value: e.Text}},
+	}
+	if e.HTML != "" {
+		mail.Content = append(mail.Content, sendGridContent{Type: "text/html", Value: e.HTML})
+	}
+	for _, a := range e.Attachments {
+		mail.Attachments = append(mail.Attachments, sendGridAttachment{
+			Content: base64.StdEncoding.EncodeToString(a.Data), Filename: a.Filename, Type: a.ContentType,
+		})
+	}
+	if e.ReplyTo != "" {
+		mail.ReplyTo = &sendGridAddress{Email: e.ReplyTo}
+	}
+	p := sendGridPersonalization{
+		To:  sendGridAddresses(e.To),
+		Cc:  sendGridAddresses(e.Cc),
+		Bcc: sendGridAddresses(e.Bcc),
+	}
+	mail.Personalizations = []sendGridPersonalization{p}
+
+	err := doJSON(ctx, t.Client, http.MethodPost, sendGridAPI, mail, http.Header{
+		"Authorization": {"Bearer " + t.APIKey},
+	})
+	if err != nil {
+		return fmt.Errorf("sendgrid: %w", err)
+	}
+	return nil
+}
+
+func sendGridAddresses(emails []string) []sendGridAddress {
+	var out []sendGridAddress
+	for _, email := range emails {
+		out = append(out, sendGridAddress{Email: email})
+	}
+	return out
+}
@@ -0,0 +1,68 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/hunterheston/airbnb/pkg/awssig"
+)
+
+// SESTransport sends mail through the Amazon SES v2 API.
+type SESTransport struct {
+	Region             string `yaml:"region"`
+	awssig.Credentials `yaml:",inline"`
+
+	Client *http.Client `yaml:"-"`
+}
+
+type sesRequest struct {
+	FromEmailAddress string `json:"FromEmailAddress"`
+	Destination      struct {
+		ToAddresses  []string `json:"ToAddresses,omitempty"`
+		CcAddresses  []string `json:"CcAddresses,omitempty"`
+		BccAddresses []string `json:"BccAddresses,omitempty"`
+	} `json:"Destination"`
+	Content struct {
+		Raw struct {
+			Data string `json:"Data"`
+		} `json:"Raw"`
+	} `json:"Content"`
+}
+
+// Send implements Transport. The message is sent raw so SES delivers
+// exactly what MIME renders.
+func (t *SESTransport) Send(ctx context.Context, e *Email) error {
+	if t.Region == "" || t.AccessKeyID == "" || t.SecretAccessKey == "" {
+		return errors.New("ses: region, access_key_id and secret_access_key must be configured")
+	}
+
+	var payload sesRequest
+	payload.FromEmailAddress = e.From
+	payload.Destination.ToAddresses = e.To
+	payload.Destination.CcAddresses = e.Cc
+	payload.Destination.BccAddresses = e.Bcc
+	payload.Content.Raw.Data = base64.StdEncoding.EncodeToString(e.MIME())
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	endpoint := fmt.Sprintf("https://email.%s.amazonaws.com/v2/email/outbound-emails", t.Region)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	awssig.Sign(req, body, t.Credentials, t.Region, "ses", time.Now())
+
+	if err := do(t.Client, req); err != nil {
+		return fmt.Errorf("ses: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/hunterheston/airbnb/pkg/googleauth"
+)
+
+const (
+	sheetsAPI   = "https://sheets.googleapis.com/v4/spreadsheets/"
+	sheetsScope = "https://www.googleapis.com/auth/spreadsheets"
+)
+
+// sheetsHeader is written above the first row of an empty sheet. The URL
+// column is the one read back to skip jobs that are already listed.
+var sheetsHeader = []string{"Title", "Company", "URL", "Location", "First seen", "Status"}
+
+// SheetsNotifier appends a row per new job to a Google Sheet, so the sheet
+// can be used to track applications. It authenticates as a service account,
+// which must be given edit access to the spreadsheet. Jobs whose URL is
+// already in the sheet are skipped, and closed jobs aren't touched: the
+// rows belong to the user once written.
+type SheetsNotifier struct {
+	// SpreadsheetID is the long ID in the spreadsheet's URL.
+	SpreadsheetID string `yaml:"spreadsheet_id"`
+	// Sheet is the tab rows are appended to, "Sheet1" by default.
+	Sheet string `yaml:"sheet"`
+	// CredentialsFile is the service account's JSON key.
+	CredentialsFile string `yaml:"credentials_file"`
+
+	Client *http.Client `yaml:"-"`
+}
+
+// Notify implements Notifier. A job's first-seen date is the day it is
+// added, which is the day it was found unless earlier sends failed.
+func (n *SheetsNotifier) Notify(ctx context.Context, d Digest) error {
+	if n.SpreadsheetID == "" || n.CredentialsFile == "" {
+		return errors.New("sheets: spreadsheet_id and credentials_file must be configured")
+	}
+	if len(d.New) == 0 {
+		return nil
+	}
+	sa, err := googleauth.Load(n.CredentialsFile)
+	if err != nil {
+		return fmt.Errorf("sheets: %w", err)
+	}
+	token, err := sa.AccessToken(ctx, n.Client, sheetsScope)
+	if err != nil {
+		return fmt.Errorf("sheets: %w", err)
+	}
+	header := http.Header{"Authorization": {"Bearer " + token}}
+
+	listed, err := n.urls(ctx, header)
+	if err != nil {
+		return fmt.Errorf("sheets: reading the sheet: %w", err)
+	}
+	var rows [][]string
+	if len(listed) == 0 {
+		rows = append(rows, sheetsHeader)
+	}
+	today := time.Now().Format("2006-01-02")
+	for _, job := range d.New {
+		if listed[job.URL] {
+			continue
+		}
+		rows = append(rows, []string{job.Title, job.Company, job.URL, job.Location, today, "New"})
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+
+	endpoint := n.valuesURL("A:F") + ":append?valueInputOption=RAW&insertDataOption=INSERT_ROWS"
+	body := map[string]any{"values": rows}
+	if err := doJSON(ctx, n.Client, http.MethodPost, endpoint, body, header); err != nil {
+		return fmt.Errorf("sheets: appending rows: %w", err)
+	}
+	return nil
+}
+
+// urls returns the job URLs already in the sheet, header included.
+func (n *SheetsNotifier) urls(ctx context.Context, header http.Header) (map[string]bool, error) {
+	var resp struct {
+		Values [][]string `json:"values"`
+	}
+	if err := exchangeJSON(ctx, n.Client, http.MethodGet, n.valuesURL("C:C"), nil, header, &resp); err != nil {
+		return nil, err
+	}
+	urls := map[string]bool{}
+	for _, row := range resp.Values {
+		if len(row) > 0 {
+			urls[row[0]] = true
+		}
+	}
+	return urls, nil
+}
+
+// valuesURL is the API URL for the columns cols, e.g. "A:F", of the sheet.
+func (n *SheetsNotifier) valuesURL(cols string) string {
+	sheet := n.Sheet
+	if sheet == "" {
+		sheet = "Sheet1"
+	}
+	// Quoting makes names with spaces or punctuation valid A1 ranges.
+	a1 := "'" + strings.ReplaceAll(sheet, "'", "''") + "'!" + cols
+	return sheetsAPI + url.PathEscape(n.SpreadsheetID) + "/values/" + url.PathEscape(a1)
+}
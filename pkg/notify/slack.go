@@ -0,0 +1,93 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// SlackNotifier posts job postings to a Slack incoming webhook.
+type SlackNotifier struct {
+	WebhookURL string `yaml:"webhook_url"`
+	// Template is an optional path to a text/template that writes the
+	// message, in Slack's mrkdwn, instead of the built-in layout. It
+	// receives a TemplateData.
+	Template string `yaml:"template"`
+
+	Client *http.Client `yaml:"-"`
+}
+
+type slackMessage struct {
+	Text string `json:"text"`
+}
+
+// Notify implements Notifier.
+func (n *SlackNotifier) Notify(ctx context.Context, d Digest) error {
+	if n.WebhookURL == "" {
+		return errors.New("slack: webhook_url is not configured")
+	}
+	text, err := n.Render(d)
+	if err != nil {
+		return fmt.Errorf("slack: rendering: %w", err)
+	}
+	if err := postJSON(ctx, n.Client, n.WebhookURL, slackMessage{Text: text}); err != nil {
+		return fmt.Errorf("slack: %w", err)
+	}
+	return nil
+}
+
+// Alert implements Alerter.
+func (n *SlackNotifier) Alert(ctx context.Context, msg string) error {
+	if n.WebhookURL == "" {
+		return errors.New("slack: webhook_url is not configured")
+	}
+	if err := postJSON(ctx, n.Client, n.WebhookURL, slackMessage{Text: ":warning: " + slackEscape(msg)}); err != nil {
+		return fmt.Errorf("slack: %w", err)
+	}
+	return nil
+}
+
+// Render implements Renderer with the message's text.
+func (n *SlackNotifier) Render(d Digest) (string, error) {
+	if n.Template != "" {
+		return renderText("", n.Template, NewTemplateData(d, nil))
+	}
+	return slackText(d), nil
+}
+
+func slackText(d Digest) string {
+	var text strings.Builder
+	if len(d.Partial) > 0 {
+		text.WriteString(":warning: _" + partialNotice + "_\n")
+	}
+	if len(d.New) == 0 {
+		text.WriteString("No new job postings found today.\n")
+	} else {
+		fmt.Fprintf(&text, "*%d new job postings:*\n", len(d.New))
+	}
+	for _, job := range d.New {
+		fmt.Fprintf(&text, "• <%s|%s> — %s", job.URL, slackEscape(job.Title), slackEscape(job.Company))
+		if s := job.Salary(); s != "" {
+			text.WriteString(" · " + s)
+		}
+		if a := job.Age(); a != "" {
+			text.WriteString(" · " + a)
+		}
+		text.WriteString("\n")
+	}
+
+	if len(d.Closed) > 0 {
+		text.WriteString("\n*Closed since last run:*\n")
+		for _, job := range d.Closed {
+			fmt.Fprintf(&text, "• ~%s~ — %s\n", slackEscape(job.Title), slackEscape(job.Company))
+		}
+	}
+	return text.String()
+}
+
+// slackEscape escapes the characters Slack treats as control sequences.
+func slackEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
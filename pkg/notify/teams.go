@@ -0,0 +1,158 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/hunterheston/airbnb/pkg/scraper"
+)
+
+// teamsMaxJobs is how many jobs go in one card, which keeps it well under
+// the 28 KB Teams accepts per message.
+const teamsMaxJobs = 25
+
+// TeamsNotifier posts the digest to a Microsoft Teams incoming webhook, or
+// a Workflows "post to a channel when a webhook request is received" URL,
+// as an Adaptive Card.
+type TeamsNotifier struct {
+	WebhookURL string `yaml:"webhook_url"`
+
+	Client *http.Client `yaml:"-"`
+}
+
+type teamsMessage struct {
+	Type        string            `json:"type"`
+	Attachments []teamsAttachment `json:"attachments"`
+}
+
+type teamsAttachment struct {
+	ContentType string    `json:"contentType"`
+	Content     teamsCard `json:"content"`
+}
+
+type teamsCard struct {
+	Schema  string       `json:"$schema"`
+	Type    string       `json:"type"`
+	Version string       `json:"version"`
+	Body    []teamsBlock `json:"body"`
+}
+
+// teamsBlock is an Adaptive Card TextBlock.
+type teamsBlock struct {
+	Type      string `json:"type"`
+	Text      string `json:"text"`
+	Wrap      bool   `json:"wrap"`
+	Weight    string `json:"weight,omitempty"`
+	Size      string `json:"size,omitempty"`
+	Color     string `json:"color,omitempty"`
+	IsSubtle  bool   `json:"isSubtle,omitempty"`
+	Spacing   string `json:"spacing,omitempty"`
+	Separator bool   `json:"separator,omitempty"`
+}
+
+// Notify implements Notifier. New jobs are sent 25 to a card, followed by a
+// card listing the closed ones.
+func (n *TeamsNotifier) Notify(ctx context.Context, d Digest) error {
+	if n.WebhookURL == "" {
+		return errors.New("teams: webhook_url is not configured")
+	}
+	var notice []teamsBlock
+	if len(d.Partial) > 0 {
+		notice = append(notice, teamsText("⚠️ "+partialNotice, "warning"))
+	}
+	if len(d.New) == 0 {
+		if err := n.post(ctx, append(notice, teamsHeading("No new job postings found today."))); err != nil {
+			return err
+		}
+	}
+	for start := 0; start < len(d.New); start += teamsMaxJobs {
+		body := notice
+		notice = nil
+		if start == 0 {
+			body = append(body, teamsHeading(fmt.Sprintf("%d new job postings", len(d.New))))
+		}
+		for _, job := range d.New[start:min(start+teamsMaxJobs, len(d.New))] {
+			body = append(body, teamsJob(job)...)
+		}
+		if err := n.post(ctx, body); err != nil {
+			return err
+		}
+	}
+
+	if len(d.Closed) > 0 {
+		var list strings.Builder
+		for _, job := range d.Closed {
+			fmt.Fprintf(&list, "- %s (%s)\n", teamsEscape(job.Title), teamsEscape(job.Company))
+		}
+		return n.post(ctx, []teamsBlock{
+			teamsHeading("Closed since last run"),
+			teamsText(truncate(list.String(), 20000), ""),
+		})
+	}
+	return nil
+}
+
+// Alert implements Alerter.
+func (n *TeamsNotifier) Alert(ctx context.Context, msg string) error {
+	if n.WebhookURL == "" {
+		return errors.New("teams: webhook_url is not configured")
+	}
+	return n.post(ctx, []teamsBlock{teamsText("⚠️ "+teamsEscape(truncate(msg, 20000)), "attention")})
+}
+
+func (n *TeamsNotifier) post(ctx context.Context, body []teamsBlock) error {
+	msg := teamsMessage{
+		Type: "message",
+		Attachments: []teamsAttachment{{
+			ContentType: "application/vnd.microsoft.card.adaptive",
+			Content: teamsCard{
+				Schema:  "http://adaptivecards.io/schemas/adaptive-card.json",
+				Type:    "AdaptiveCard",
+				Version: "1.4",
+				Body:    body,
+			},
+		}},
+	}
+	if err := postJSON(ctx, n.Client, n.WebhookURL, msg); err != nil {
+		return fmt.Errorf("teams: %w", err)
+	}
+	return nil
+}
+
+// teamsJob is a job's title, linked to the posting, over a subtle line of
+// company, location and salary.
+func teamsJob(job scraper.JobPosting) []teamsBlock {
+	title := teamsText(fmt.Sprintf("**[%s](%s)**", teamsEscape(job.Title), job.URL), "")
+	title.Separator = true
+	details := []string{teamsEscape(job.Company)}
+	if job.Location != "" {
+		details = append(details, teamsEscape(job.Location))
+	}
+	if s := job.Salary(); s != "" {
+		details = append(details, s)
+	}
+	if a := job.Age(); a != "" {
+		details = append(details, a)
+	}
+	line := teamsText(strings.Join(details, " · "), "")
+	line.IsSubtle = true
+	line.Spacing = "None"
+	return []teamsBlock{title, line}
+}
+
+func teamsHeading(text string) teamsBlock {
+	return teamsBlock{Type: "TextBlock", Text: text, Wrap: true, Weight: "Bolder", Size: "Medium"}
+}
+
+func teamsText(text, color string) teamsBlock {
+	return teamsBlock{Type: "TextBlock", Text: text, Wrap: true, Color: color}
+}
+
+// teamsEscape escapes the characters Adaptive Card markdown would
+// interpret.
+func teamsEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "*", `\*`, "_", `\_`, "[", `\[`, "]", `\]`).Replace(s)
+}
@@ -0,0 +1,116 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"strings"
+
+	"github.com/hunterheston/airbnb/pkg/scraper"
+)
+
+const telegramAPI = "https://api.telegram.org/bot"
+
+// TelegramNotifier sends job postings through a Telegram bot, one message
+// per job with a button that opens the posting.
+type TelegramNotifier struct {
+	// Token is the bot token from @BotFather.
+	Token string `yaml:"token"`
+	// ChatID is the numeric chat ID or @channelusername to send to.
+	ChatID string `yaml:"chat_id"`
+
+	Client *http.Client `yaml:"-"`
+}
+
+type telegramMessage struct {
+	ChatID      string            `json:"chat_id"`
+	Text        string            `json:"text"`
+	ParseMode   string            `json:"parse_mode"`
+	ReplyMarkup *telegramKeyboard `json:"reply_markup,omitempty"`
+}
+
+type telegramKeyboard struct {
+	InlineKeyboard [][]telegramButton `json:"inline_keyboard"`
+}
+
+type telegramButton struct {
+	Text string `json:"text"`
+	URL  string `json:"url"`
+}
+
+// Notify implements Notifier.
+func (n *TelegramNotifier) Notify(ctx context.Context, d Digest) error {
+	if n.Token == "" || n.ChatID == "" {
+		return errors.New("telegram: token and chat_id must be configured")
+	}
+
+	if len(d.Partial) > 0 {
+		if err := n.send(ctx, telegramMessage{Text: "⚠️ <i>" + partialNotice + "</i>"}); err != nil {
+			return err
+		}
+	}
+	if len(d.New) == 0 {
+		if err := n.send(ctx, telegramMessage{Text: "No new job postings found today."}); err != nil {
+			return err
+		}
+	} else if err := n.send(ctx, telegramMessage{Text: fmt.Sprintf("<b>%d new job postings</b>", len(d.New))}); err != nil {
+		return err
+	}
+	for _, job := range d.New {
+		if err := n.send(ctx, telegramMessageFor(job)); err != nil {
+			return err
+		}
+	}
+
+	if len(d.Closed) > 0 {
+		var text strings.Builder
+		text.WriteString("<b>Closed since last run:</b>")
+		for _, job := range d.Closed {
+			fmt.Fprintf(&text, "\n• <s>%s</s> (%s)", html.EscapeString(job.Title), html.EscapeString(job.Company))
+		}
+		return n.send(ctx, telegramMessage{Text: text.String()})
+	}
+	return nil
+}
+
+// Alert implements Alerter.
+func (n *TelegramNotifier) Alert(ctx context.Context, msg string) error {
+	if n.Token == "" || n.ChatID == "" {
+		return errors.New("telegram: token and chat_id must be configured")
+	}
+	return n.send(ctx, telegramMessage{Text: "⚠️ " + html.EscapeString(msg)})
+}
+
+func (n *TelegramNotifier) send(ctx context.Context, msg telegramMessage) error {
+	msg.ChatID = n.ChatID
+	msg.ParseMode = "HTML"
+	if err := postJSON(ctx, n.Client, telegramAPI+n.Token+"/sendMessage", msg); err != nil {
+		// The request URL contains the token, so don't let it leak into logs.
+		return fmt.Errorf("telegram: %s", strings.ReplaceAll(err.Error(), n.Token, "<token>"))
+	}
+	return nil
+}
+
+func telegramMessageFor(job scraper.JobPosting) telegramMessage {
+	var text strings.Builder
+	fmt.Fprintf(&text, "<b>%s</b>\n%s", html.EscapeString(job.Title), html.EscapeString(job.Company))
+	if job.Location != "" {
+		fmt.Fprintf(&text, " · %s", html.EscapeString(job.Location))
+	}
+	if s := job.Salary(); s != "" {
+		fmt.Fprintf(&text, " · %s", s)
+	}
+	if a := job.Age(); a != "" {
+		fmt.Fprintf(&text, " · %s", a)
+	}
+
+	msg := telegramMessage{Text: text.String()}
+	if job.URL != "" {
+		msg.ReplyMarkup = &telegramKeyboard{
+			InlineKeyboard: [][]telegramButton{{{Text: "Open posting", URL: job.URL}}},
+		}
+	}
+	return msg
+}
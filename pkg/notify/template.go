@@ -0,0 +1,142 @@
+package notify
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	htmltemplate "html/template"
+	"path/filepath"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/hunterheston/airbnb/pkg/actions"
+	"github.com/hunterheston/airbnb/pkg/scraper"
+)
+
+//go:embed templates
+var templates embed.FS
+
+// TemplateData is what every notification template receives: the email's
+// html_template and text_template and the template of the Slack, Discord
+// and webhook notifiers.
+//
+// Besides the Digest's New, Closed and Partial jobs and its Stats, it holds
+// the new jobs grouped into Sections, and the Links to the careers pages
+// (for email only). Its Actions method returns a job's one-click action
+// links, when the email has them. Each job has the fields of scraper.JobPosting, its
+// Summary included when summaries are on, and its Salary and Age methods. Templates can call:
+//
+//   - excerpt, which shortens a description to a few lines;
+//   - days, which formats a number of days such as 3.5;
+//   - join, which joins a list such as .Tags with a separator;
+//   - json, which encodes any value as JSON, for webhook bodies;
+//   - date, which formats a time with a Go layout, e.g. date "Jan 2" .PostedAt.
+type TemplateData struct {
+	Digest
+	// Sections are the new jobs grouped by team; see Sections.
+	Sections []Section
+	Links    []Link
+
+	actions *actions.Signer
+}
+
+// actionNames are the action links' names.
+var actionNames = map[string]string{
+	actions.Interested: "Interested",
+	actions.Applied:    "Applied",
+	actions.Mute:       "Not interested",
+}
+
+// Actions returns the links that mark job as interesting, applied to or
+// muted, or none if the notifier doesn't sign action links.
+func (d TemplateData) Actions(job scraper.JobPosting) []Link {
+	if d.actions == nil {
+		return nil
+	}
+	var links []Link
+	for _, a := range actions.All {
+		links = append(links, Link{Name: actionNames[a], URL: d.actions.URL(a, job)})
+	}
+	return links
+}
+
+// NewTemplateData returns the template data of d.
+func NewTemplateData(d Digest, links []Link) TemplateData {
+	return TemplateData{Digest: d, Sections: Sections(d.New), Links: links}
+}
+
+// excerptLength is roughly how much of each description the email shows.
+const excerptLength = 240
+
+var templateFuncs = map[string]any{
+	"excerpt": func(s string) string { return scraper.Excerpt(s, excerptLength) },
+	"days":    func(d float64) string { return fmt.Sprintf("%.1f", d) },
+	"join":    strings.Join,
+	"json": func(v any) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+	"date": func(layout string, t any) string {
+		switch t := t.(type) {
+		case time.Time:
+			return t.Format(layout)
+		case *time.Time:
+			if t != nil {
+				return t.Format(layout)
+			}
+		}
+		return ""
+	},
+}
+
+// RenderTemplate executes the template at path with data: as html/template
+// if its name contains ".html", and as text/template otherwise.
+func RenderTemplate(path string, data TemplateData) (string, error) {
+	if strings.Contains(filepath.Base(path), ".html") {
+		return renderHTML("", path, data, htmltemplate.FuncMap{"highlight": NewHighlighter(nil).HTML})
+	}
+	return renderText("", path, data)
+}
+
+// renderText executes the built-in text template name, or the one at path
+// if path is set.
+func renderText(name, path string, data any) (string, error) {
+	var t *texttemplate.Template
+	var err error
+	if path != "" {
+		t, err = texttemplate.New(filepath.Base(path)).Funcs(templateFuncs).ParseFiles(path)
+	} else {
+		t, err = texttemplate.New(name).Funcs(templateFuncs).ParseFS(templates, "templates/"+name)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	var buf strings.Builder
+	if err := t.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// renderHTML is renderText for html/template, with funcs available to the
+// template besides the shared ones.
+func renderHTML(name, path string, data any, funcs htmltemplate.FuncMap) (string, error) {
+	var t *htmltemplate.Template
+	var err error
+	if path != "" {
+		t, err = htmltemplate.New(filepath.Base(path)).Funcs(templateFuncs).Funcs(funcs).ParseFiles(path)
+	} else {
+		t, err = htmltemplate.New(name).Funcs(templateFuncs).Funcs(funcs).ParseFS(templates, "templates/"+name)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	var buf strings.Builder
+	if err := t.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,344 @@
+package notify
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/smtp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/hunterheston/airbnb/pkg/dkim"
+)
+
+// Email is a rendered message, ready for a Transport.
+type Email struct {
+	From string
+	To   []string
+	Cc   []string
+	// Bcc receive the message without being listed in its headers.
+	Bcc     []string
+	ReplyTo string
+	Subject string
+	// Date and MessageID are fixed when the email is composed, so that a
+	// retried delivery is the same message. MIME fills in either if unset.
+	Date      time.Time
+	MessageID string
+	Text      string
+	// HTML is optional; without it the message is plain text.
+	HTML        string
+	Attachments []Attachment
+}
+
+// Recipients returns every address the message is delivered to.
+func (e *Email) Recipients() []string {
+	return slices.Concat(e.To, e.Cc, e.Bcc)
+}
+
+// MIME returns the message in Internet Message Format, headers included,
+// with non-ASCII headers encoded and the bodies quoted-printable. With both
+// bodies present it is multipart/alternative, and with attachments
+// multipart/mixed.
+func (e *Email) MIME() []byte {
+	date, id := e.Date, e.MessageID
+	if date.IsZero() {
+		date = time.Now()
+	}
+	if id == "" {
+		id = NewMessageID(e.From)
+	}
+
+	var b messageBuilder
+	b.Addresses("From", []string{e.From})
+	if len(e.To) > 0 {
+		b.Addresses("To", e.To)
+	} else {
+		// RFC 5322's way of saying every recipient is in Bcc.
+		b.field("To", "undisclosed-recipients:;")
+	}
+	if len(e.Cc) > 0 {
+		b.Addresses("Cc", e.Cc)
+	}
+	if e.ReplyTo != "" {
+		b.Addresses("Reply-To", []string{e.ReplyTo})
+	}
+	b.Header("Subject", e.Subject)
+	b.field("Date", date.Format(time.RFC1123Z))
+	b.field("Message-ID", id)
+
+	parts := []mimePart{{"text/plain; charset=UTF-8", e.Text}}
+	if e.HTML != "" {
+		// Clients show the last alternative they understand, so HTML goes last.
+		parts = append(parts, mimePart{"text/html; charset=UTF-8", e.HTML})
+	}
+	return b.Build(parts, e.Attachments...)
+}
+
+// NewMessageID returns a unique Message-ID for mail from the address from,
+// on its domain.
+func NewMessageID(from string) string {
+	domain := "jobwatch.localhost"
+	if i := strings.LastIndex(from, "@"); i >= 0 && i < len(from)-1 {
+		domain = strings.TrimSuffix(from[i+1:], ">")
+	}
+	b := make([]byte, 12)
+	rand.Read(b)
+	return fmt.Sprintf("<%d.%x@%s>", time.Now().Unix(), b, domain)
+}
+
+// Transport delivers a rendered email.
+type Transport interface {
+	Send(ctx context.Context, e *Email) error
+}
+
+// WriterTransport writes each email to W instead of sending it, for dry
+// runs.
+type WriterTransport struct {
+	W io.Writer
+}
+
+// Send implements Transport.
+func (t *WriterTransport) Send(ctx context.Context, e *Email) error {
+	if _, err := t.W.Write(e.MIME()); err != nil {
+		return err
+	}
+	_, err := io.WriteString(t.W, "\r\n")
+	return err
+}
+
+// SMTP TLS modes, for SMTPTransport.TLS.
+const (
+	// SMTPTLSOpportunistic upgrades with STARTTLS when the server offers it.
+	SMTPTLSOpportunistic = ""
+	// SMTPTLSStartTLS requires STARTTLS, failing if the server lacks it.
+	SMTPTLSStartTLS = "starttls"
+	// SMTPTLSImplicit speaks TLS from the start, as on port 465.
+	SMTPTLSImplicit = "implicit"
+	// SMTPTLSNone never encrypts, for relays on a trusted network.
+	SMTPTLSNone = "none"
+)
+
+// SMTP authentication methods, for SMTPTransport.Auth.
+const (
+	SMTPAuthPlain   = "plain"
+	SMTPAuthLogin   = "login"
+	SMTPAuthCRAMMD5 = "cram-md5"
+	// SMTPAuthNone sends without logging in, for relays that accept mail
+	// from their network.
+	SMTPAuthNone = "none"
+)
+
+// SMTPTransport sends mail through an SMTP server, with PLAIN auth unless
+// Auth says otherwise, or XOAUTH2 when OAuth2 is set.
+type SMTPTransport struct {
+	Host string
+	// Port defaults to 465 with implicit TLS, 25 without TLS and 587
+	// otherwise.
+	Port     string
+	Username string
+	Password string
+	OAuth2   *OAuth2Config
+	// TLS is one of the SMTPTLS modes.
+	TLS string
+	// Auth is one of the SMTPAuth methods; "" means PLAIN.
+	Auth string
+	// InsecureSkipVerify accepts any certificate, for servers with a
+	// self-signed one. It leaves the connection open to interception.
+	InsecureSkipVerify bool
+	// DKIM signs each message when set.
+	DKIM *dkim.Signer
+}
+
+// Validate checks the TLS mode and authentication method, and that the
+// DKIM key loads.
+func (t *SMTPTransport) Validate() error {
+	switch t.TLS {
+	case SMTPTLSOpportunistic, SMTPTLSStartTLS, SMTPTLSImplicit, SMTPTLSNone:
+	default:
+		return fmt.Errorf("unknown tls mode %q (want starttls, implicit or none)", t.TLS)
+	}
+	switch strings.ToLower(t.Auth) {
+	case "", SMTPAuthPlain, SMTPAuthLogin, SMTPAuthCRAMMD5, SMTPAuthNone:
+	default:
+		return fmt.Errorf("unknown auth method %q (want plain, login, cram-md5 or none)", t.Auth)
+	}
+	if t.DKIM != nil {
+		return t.DKIM.Validate()
+	}
+	return nil
+}
+
+// port returns the port to connect to.
+func (t *SMTPTransport) port() string {
+	switch {
+	case t.Port != "":
+		return t.Port
+	case t.TLS == SMTPTLSImplicit:
+		return "465"
+	case t.TLS == SMTPTLSNone:
+		return "25"
+	default:
+		return "587"
+	}
+}
+
+// auth returns how to log in, or nil to send without logging in.
+func (t *SMTPTransport) auth(ctx context.Context) (smtp.Auth, error) {
+	if t.OAuth2 != nil {
+		token, err := t.OAuth2.AccessToken(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return XOAuth2Auth(t.Username, token, t.Host), nil
+	}
+	switch strings.ToLower(t.Auth) {
+	case SMTPAuthLogin:
+		return LoginAuth(t.Username, t.Password, t.Host), nil
+	case SMTPAuthCRAMMD5:
+		return smtp.CRAMMD5Auth(t.Username, t.Password), nil
+	case SMTPAuthNone:
+		return nil, nil
+	default:
+		return smtp.PlainAuth("", t.Username, t.Password, t.Host), nil
+	}
+}
+
+// Send implements Transport. Unless TLS says otherwise it upgrades to TLS
+// when the server offers STARTTLS, like smtp.SendMail.
+func (t *SMTPTransport) Send(ctx context.Context, e *Email) error {
+	if err := t.Validate(); err != nil {
+		return fmt.Errorf("smtp: %w", err)
+	}
+	auth, err := t.auth(ctx)
+	if err != nil {
+		return err
+	}
+	msg := e.MIME()
+	if t.DKIM != nil {
+		if msg, err = t.DKIM.Sign(msg, time.Now()); err != nil {
+			return err
+		}
+	}
+
+	addr := net.JoinHostPort(t.Host, t.port())
+	var conn net.Conn
+	if t.TLS == SMTPTLSImplicit {
+		d := tls.Dialer{Config: t.tlsConfig()}
+		conn, err = d.DialContext(ctx, "tcp", addr)
+	} else {
+		var d net.Dialer
+		conn, err = d.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return err
+	}
+	// The SMTP client doesn't take a context, so the connection carries its
+	// deadline and is closed if it's cancelled.
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	if err := t.send(conn, auth, e, msg); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return err
+	}
+	return nil
+}
+
+func (t *SMTPTransport) tlsConfig() *tls.Config {
+	return &tls.Config{ServerName: t.Host, InsecureSkipVerify: t.InsecureSkipVerify}
+}
+
+// send has the SMTP conversation for e over conn, sending msg as its
+// content.
+func (t *SMTPTransport) send(conn net.Conn, auth smtp.Auth, e *Email, msg []byte) error {
+	c, err := smtp.NewClient(conn, t.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+
+	switch ok, _ := c.Extension("STARTTLS"); {
+	case t.TLS == SMTPTLSImplicit || t.TLS == SMTPTLSNone:
+	case ok:
+		if err := c.StartTLS(t.tlsConfig()); err != nil {
+			return err
+		}
+	case t.TLS == SMTPTLSStartTLS:
+		return errors.New("smtp: server doesn't support STARTTLS")
+	}
+	if auth != nil {
+		if ok, _ := c.Extension("AUTH"); !ok {
+			return errors.New("smtp: server doesn't support AUTH")
+		}
+		if err := c.Auth(auth); err != nil {
+			return err
+		}
+	}
+
+	if err := c.Mail(e.From); err != nil {
+		return err
+	}
+	for _, to := range e.Recipients() {
+		if err := c.Rcpt(to); err != nil {
+			return err
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+type loginAuth struct {
+	username, password, host string
+}
+
+// LoginAuth returns an smtp.Auth for the LOGIN method, which some servers,
+// Exchange and Office 365 among them, offer instead of PLAIN. Like
+// smtp.PlainAuth it refuses to send the password over an unencrypted
+// connection to anything but localhost.
+func LoginAuth(username, password, host string) smtp.Auth {
+	return &loginAuth{username: username, password: password, host: host}
+}
+
+func (a *loginAuth) Start(server *smtp.ServerInfo) (string, []byte, error) {
+	if !server.TLS && !isLocalhost(server.Name) {
+		return "", nil, errors.New("unencrypted connection")
+	}
+	if server.Name != a.host {
+		return "", nil, errors.New("wrong host name")
+	}
+	return "LOGIN", nil, nil
+}
+
+func (a *loginAuth) Next(fromServer []byte, more bool) ([]byte, error) {
+	if !more {
+		return nil, nil
+	}
+	switch strings.ToLower(strings.TrimSpace(string(fromServer))) {
+	case "username:":
+		return []byte(a.username), nil
+	case "password:":
+		return []byte(a.password), nil
+	default:
+		return nil, fmt.Errorf("unexpected LOGIN challenge %q", fromServer)
+	}
+}
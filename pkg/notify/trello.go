@@ -0,0 +1,165 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/hunterheston/airbnb/pkg/scraper"
+)
+
+const trelloAPI = "https://api.trello.com/1/"
+
+// TrelloNotifier adds a card per new job to a list on a Trello board, so
+// that jobs land in the first column of an application pipeline. Each card
+// has the job's title, link and the start of its description, and the
+// board's labels named after the job's level and location and Labels;
+// missing ones are added to the board. Jobs whose link is already on a
+// card of the board, archived ones included, are skipped, and closed jobs
+// aren't touched.
+type TrelloNotifier struct {
+	// Key and Token are an API key from https://trello.com/power-ups/admin
+	// and a token it issued with write access.
+	Key   string `yaml:"key"`
+	Token string `yaml:"token"`
+	// ListID is the list cards are added to: open a card in it and append
+	// ".json" to its URL to find the "idList".
+	ListID string `yaml:"list_id"`
+	// Labels are put on every card.
+	Labels []string `yaml:"labels"`
+
+	Client *http.Client `yaml:"-"`
+}
+
+// Notify implements Notifier.
+func (n *TrelloNotifier) Notify(ctx context.Context, d Digest) error {
+	if n.Key == "" || n.Token == "" || n.ListID == "" {
+		return errors.New("trello: key, token and list_id must be configured")
+	}
+	if len(d.New) == 0 {
+		return nil
+	}
+	var board struct {
+		ID string `json:"id"`
+	}
+	if err := n.call(ctx, http.MethodGet, "lists/"+url.PathEscape(n.ListID)+"/board?fields=id", nil, &board); err != nil {
+		return fmt.Errorf("trello: finding the list's board: %w", err)
+	}
+	carded, err := n.carded(ctx, board.ID)
+	if err != nil {
+		return fmt.Errorf("trello: listing cards: %w", err)
+	}
+	labels, err := n.labels(ctx, board.ID)
+	if err != nil {
+		return fmt.Errorf("trello: listing labels: %w", err)
+	}
+
+	var errs []error
+	for _, job := range d.New {
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, err)
+			break
+		}
+		if carded[job.URL] {
+			continue
+		}
+		if err := n.add(ctx, board.ID, labels, job); err != nil {
+			errs = append(errs, fmt.Errorf("trello: %s: %w", job.URL, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// carded returns the links already on the board's cards: the lines of
+// their descriptions, where a card's link is on a line of its own.
+func (n *TrelloNotifier) carded(ctx context.Context, board string) (map[string]bool, error) {
+	var cards []struct {
+		Desc string `json:"desc"`
+	}
+	if err := n.call(ctx, http.MethodGet, "boards/"+url.PathEscape(board)+"/cards/all?fields=desc", nil, &cards); err != nil {
+		return nil, err
+	}
+	links := map[string]bool{}
+	for _, c := range cards {
+		for _, line := range strings.Split(c.Desc, "\n") {
+			links[strings.TrimSpace(line)] = true
+		}
+	}
+	return links, nil
+}
+
+// labels returns the IDs of the board's labels by name.
+func (n *TrelloNotifier) labels(ctx context.Context, board string) (map[string]string, error) {
+	var labels []struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	}
+	if err := n.call(ctx, http.MethodGet, "boards/"+url.PathEscape(board)+"/labels?fields=name&limit=1000", nil, &labels); err != nil {
+		return nil, err
+	}
+	ids := map[string]string{}
+	for _, l := range labels {
+		if l.Name != "" {
+			ids[l.Name] = l.ID
+		}
+	}
+	return ids, nil
+}
+
+// add creates job's card, and the labels it needs that the board lacks,
+// recording them in labels.
+func (n *TrelloNotifier) add(ctx context.Context, board string, labels map[string]string, job scraper.JobPosting) error {
+	var ids []string
+	for _, name := range append([]string{job.Level, job.Location}, n.Labels...) {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		id, ok := labels[name]
+		if !ok {
+			var created struct {
+				ID string `json:"id"`
+			}
+			body := map[string]any{"name": name, "color": nil}
+			if err := n.call(ctx, http.MethodPost, "boards/"+url.PathEscape(board)+"/labels", body, &created); err != nil {
+				return fmt.Errorf("adding label %q: %w", name, err)
+			}
+			id, labels[name] = created.ID, created.ID
+		}
+		ids = append(ids, id)
+	}
+	card := map[string]any{
+		"idList":    n.ListID,
+		"name":      fmt.Sprintf("%s at %s", job.Title, job.Company),
+		"desc":      trelloDesc(job),
+		"urlSource": job.URL,
+		"idLabels":  strings.Join(ids, ","),
+		"pos":       "bottom",
+	}
+	return n.call(ctx, http.MethodPost, "cards", card, nil)
+}
+
+// trelloDesc is a card's Markdown description.
+func trelloDesc(job scraper.JobPosting) string {
+	var b strings.Builder
+	facts := []string{"**" + job.Company + "**"}
+	for _, s := range []string{job.Location, job.Team, job.Level, job.Salary()} {
+		if s != "" {
+			facts = append(facts, s)
+		}
+	}
+	fmt.Fprintf(&b, "%s\n\n%s\n", strings.Join(facts, " · "), job.URL)
+	if job.Description != "" {
+		fmt.Fprintf(&b, "\n%s\n", scraper.Excerpt(job.Description, 1000))
+	}
+	return b.String()
+}
+
+// call makes an API request, with the key and token in the Authorization
+// header rather than the URL, where they would end up in logs.
+func (n *TrelloNotifier) call(ctx context.Context, method, path string, body, result any) error {
+	header := http.Header{"Authorization": {fmt.Sprintf(`OAuth oauth_consumer_key="%s", oauth_token="%s"`, n.Key, n.Token)}}
+	return exchangeJSON(ctx, n.Client, method, trelloAPI+path, body, header, result)
+}
@@ -0,0 +1,232 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hunterheston/airbnb/pkg/scraper"
+)
+
+// Webhook event types.
+const (
+	EventJobNew    = "job.new"
+	EventJobClosed = "job.closed"
+	EventAlert     = "alert"
+)
+
+// WebhookNotifier POSTs one JSON event per new or closed job to each URL,
+// or with a Template one body per digest.
+//
+// When Secret is set, every request carries an X-Jobwatch-Timestamp header
+// with the Unix time and an X-Jobwatch-Signature header of the form
+// "sha256=<hex>", the HMAC-SHA256 of the timestamp, a dot and the body,
+// keyed with Secret. Receivers should recompute it and reject old
+// timestamps. X-Jobwatch-Delivery identifies the event across retries.
+type WebhookNotifier struct {
+	URLs   []string `yaml:"urls"`
+	Secret string   `yaml:"secret"`
+	// Attempts is the number of tries per delivery, 3 by default. Failed
+	// deliveries are retried after Backoff (1s by default), doubling each
+	// time. Only network errors, 429s and 5xx responses are retried.
+	Attempts int           `yaml:"attempts"`
+	Backoff  time.Duration `yaml:"backoff"`
+	// Template is an optional path to a text/template that writes a single
+	// request body for the whole digest, in place of an event per job. It
+	// receives a TemplateData; its json function encodes a value. The
+	// body is sent as ContentType, application/json by default. Alerts are
+	// still sent as events.
+	Template    string `yaml:"template"`
+	ContentType string `yaml:"content_type"`
+
+	Client *http.Client `yaml:"-"`
+
+	sleep func(context.Context, time.Duration) error
+}
+
+// WebhookEvent is the body of a webhook request.
+type WebhookEvent struct {
+	Event  string    `json:"event"`
+	SentAt time.Time `json:"sent_at"`
+	// Job is set for job events and Message for alerts.
+	Job     *scraper.JobPosting `json:"job,omitempty"`
+	Message string              `json:"message,omitempty"`
+}
+
+// Notify implements Notifier.
+func (n *WebhookNotifier) Notify(ctx context.Context, d Digest) error {
+	if len(n.URLs) == 0 {
+		return errors.New("webhook: no urls configured")
+	}
+	if n.Template != "" {
+		body, err := renderText("", n.Template, NewTemplateData(d, nil))
+		if err != nil {
+			return fmt.Errorf("webhook: rendering: %w", err)
+		}
+		return n.send(ctx, []byte(body), n.ContentType)
+	}
+	var errs []error
+	for _, ev := range webhookEvents(d) {
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, err)
+			break
+		}
+		errs = append(errs, n.deliver(ctx, ev))
+	}
+	return errors.Join(errs...)
+}
+
+// Render implements Renderer with the request bodies, one per line.
+func (n *WebhookNotifier) Render(d Digest) (string, error) {
+	if n.Template != "" {
+		return renderText("", n.Template, NewTemplateData(d, nil))
+	}
+	var out strings.Builder
+	for _, ev := range webhookEvents(d) {
+		ev.SentAt = time.Now().UTC()
+		b, err := json.Marshal(ev)
+		if err != nil {
+			return "", err
+		}
+		out.Write(b)
+		out.WriteString("\n")
+	}
+	return out.String(), nil
+}
+
+// webhookEvents returns an event for each of d's new and closed jobs.
+func webhookEvents(d Digest) []WebhookEvent {
+	var events []WebhookEvent
+	for _, job := range d.New {
+		events = append(events, WebhookEvent{Event: EventJobNew, Job: &job})
+	}
+	for _, job := range d.Closed {
+		events = append(events, WebhookEvent{Event: EventJobClosed, Job: &job})
+	}
+	return events
+}
+
+// Alert implements Alerter.
+func (n *WebhookNotifier) Alert(ctx context.Context, msg string) error {
+	if len(n.URLs) == 0 {
+		return errors.New("webhook: no urls configured")
+	}
+	return n.deliver(ctx, WebhookEvent{Event: EventAlert, Message: msg})
+}
+
+// deliver sends ev to every URL.
+func (n *WebhookNotifier) deliver(ctx context.Context, ev WebhookEvent) error {
+	ev.SentAt = time.Now().UTC()
+	body, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	return n.send(ctx, body, "")
+}
+
+// send POSTs body to every URL as contentType, application/json if empty.
+// A URL that keeps failing doesn't stop the others.
+func (n *WebhookNotifier) send(ctx context.Context, body []byte, contentType string) error {
+	if contentType == "" {
+		contentType = "application/json"
+	}
+	id := make([]byte, 16)
+	rand.Read(id)
+	delivery := hex.EncodeToString(id)
+
+	var errs []error
+	for _, url := range n.URLs {
+		if err := n.post(ctx, url, body, contentType, delivery); err != nil {
+			errs = append(errs, fmt.Errorf("webhook %s: %w", url, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// post sends body to url, retrying transient failures.
+func (n *WebhookNotifier) post(ctx context.Context, url string, body []byte, contentType, delivery string) error {
+	attempts := n.Attempts
+	if attempts <= 0 {
+		attempts = 3
+	}
+	backoff := n.Backoff
+	if backoff <= 0 {
+		backoff = time.Second
+	}
+	sleep := n.sleep
+	if sleep == nil {
+		sleep = sleepContext
+	}
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		var retry bool
+		retry, err = n.postOnce(ctx, url, body, contentType, delivery)
+		if err == nil || !retry || attempt >= attempts || ctx.Err() != nil {
+			break
+		}
+		slog.Warn("webhook delivery failed; retrying", "url", url, "attempt", attempt, "attempts", attempts,
+			"err", err, "delay", backoff)
+		if err := sleep(ctx, backoff); err != nil {
+			return err
+		}
+		backoff *= 2
+	}
+	return err
+}
+
+// postOnce makes one delivery attempt and reports whether a failure is
+// worth retrying.
+func (n *WebhookNotifier) postOnce(ctx context.Context, url string, body []byte, contentType, delivery string) (retry bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("User-Agent", "jobwatch-webhook")
+	req.Header.Set("X-Jobwatch-Delivery", delivery)
+	if n.Secret != "" {
+		ts := strconv.FormatInt(time.Now().Unix(), 10)
+		req.Header.Set("X-Jobwatch-Timestamp", ts)
+		req.Header.Set("X-Jobwatch-Signature", "sha256="+WebhookSignature(n.Secret, ts, body))
+	}
+
+	client := n.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		retry = resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+		return retry, fmt.Errorf("HTTP %d: %s", resp.StatusCode, bytes.TrimSpace(msg))
+	}
+	return false, nil
+}
+
+// WebhookSignature returns the hex HMAC-SHA256 of timestamp, ".", and body
+// keyed with secret, as sent in X-Jobwatch-Signature.
+func WebhookSignature(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
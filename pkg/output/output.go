@@ -0,0 +1,235 @@
+// Package output writes job postings in machine- and human-readable formats.
+package output
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/hunterheston/airbnb/pkg/scraper"
+	"github.com/hunterheston/airbnb/pkg/store"
+)
+
+// Format is an output format name.
+type Format string
+
+// The supported formats.
+const (
+	Text Format = "text"
+	JSON Format = "json"
+	CSV  Format = "csv"
+)
+
+// ParseFormat validates a format name given on the command line.
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(s); f {
+	case Text, JSON, CSV:
+		return f, nil
+	}
+	return "", fmt.Errorf("unknown output format %q (want text, json or csv)", s)
+}
+
+// postingHeader is the CSV header for a JobPosting; postingRow must list the
+// same fields in the same order.
+var postingHeader = []string{"company", "title", "url", "location", "team", "level", "description"}
+
+func postingRow(j scraper.JobPosting) []string {
+	return []string{j.Company, j.Title, j.URL, j.Location, j.Team, j.Level, j.Description}
+}
+
+// Write writes jobs to w in format f. JSON output is an array of objects
+// with the JobPosting field names in lower case; CSV has a header row.
+func Write(w io.Writer, f Format, jobs []scraper.JobPosting) error {
+	switch f {
+	case JSON:
+		return writeJSON(w, nonNil(jobs))
+	case CSV:
+		rows := [][]string{append(postingHeader, "posted_at")}
+		for _, j := range jobs {
+			rows = append(rows, append(postingRow(j), formatTime(j.PostedAt, time.RFC3339, "")))
+		}
+		return writeCSV(w, rows)
+	default:
+		for _, j := range jobs {
+			if j.Location != "" {
+				fmt.Fprintf(w, "- [%s] %s, %s (%s)\n", j.Company, j.Title, j.Location, j.URL)
+			} else {
+				fmt.Fprintf(w, "- [%s] %s (%s)\n", j.Company, j.Title, j.URL)
+			}
+		}
+		return nil
+	}
+}
+
+// WriteStored is Write for stored jobs, adding their IDs and when each was
+// seen, sent, closed and muted.
+func WriteStored(w io.Writer, f Format, jobs []store.Job) error {
+	switch f {
+	case JSON:
+		return writeJSON(w, nonNil(jobs))
+	case CSV:
+		rows := [][]string{append(postingHeader, "first_seen", "last_seen", "notified_at", "closed_at", "id", "muted_at", "posted_at")}
+		for _, j := range jobs {
+			rows = append(rows, append(postingRow(j.JobPosting),
+				j.FirstSeen.Format(time.RFC3339), j.LastSeen.Format(time.RFC3339),
+				formatTime(j.NotifiedAt, time.RFC3339, ""), formatTime(j.ClosedAt, time.RFC3339, ""),
+				strconv.FormatInt(j.ID, 10), formatTime(j.MutedAt, time.RFC3339, ""),
+				formatTime(j.PostedAt, time.RFC3339, "")))
+		}
+		return writeCSV(w, rows)
+	default:
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "ID\tFIRST SEEN\tSENT\tCLOSED\tCOMPANY\tTITLE\tURL")
+		for _, j := range jobs {
+			title := j.Title
+			if j.MutedAt != nil {
+				title += " (muted)"
+			}
+			fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\t%s\n", j.ID, j.FirstSeen.Format(time.DateOnly),
+				formatTime(j.NotifiedAt, time.DateOnly, "-"), formatTime(j.ClosedAt, time.DateOnly, "-"),
+				j.Company, title, j.URL)
+		}
+		return tw.Flush()
+	}
+}
+
+// WriteHits writes search results in format f: WriteStored's fields and
+// the matching passage. The text format gives each hit a few lines.
+func WriteHits(w io.Writer, f Format, hits []store.Hit) error {
+	switch f {
+	case JSON:
+		return writeJSON(w, nonNil(hits))
+	case CSV:
+		rows := [][]string{append(postingHeader, "first_seen", "closed_at", "id", "snippet")}
+		for _, h := range hits {
+			rows = append(rows, append(postingRow(h.JobPosting),
+				h.FirstSeen.Format(time.RFC3339), formatTime(h.ClosedAt, time.RFC3339, ""),
+				strconv.FormatInt(h.ID, 10), h.Snippet))
+		}
+		return writeCSV(w, rows)
+	default:
+		for i, h := range hits {
+			if i > 0 {
+				fmt.Fprintln(w)
+			}
+			status := "seen " + h.FirstSeen.Format(time.DateOnly)
+			if h.ClosedAt != nil {
+				status += ", closed " + h.ClosedAt.Format(time.DateOnly)
+			}
+			fmt.Fprintf(w, "%d  [%s] %s (%s)\n    %s\n", h.ID, h.Company, h.Title, status, h.URL)
+			if snippet := strings.Join(strings.Fields(h.Snippet), " "); snippet != "" {
+				fmt.Fprintf(w, "    %s\n", snippet)
+			}
+		}
+		return nil
+	}
+}
+
+// WriteRuns writes the history of scrapes in format f. The text table
+// shows only the first line of each run's errors.
+func WriteRuns(w io.Writer, f Format, runs []store.Run) error {
+	switch f {
+	case JSON:
+		return writeJSON(w, nonNil(runs))
+	case CSV:
+		rows := [][]string{{"id", "started_at", "finished_at", "pages", "found", "matched", "new", "closed", "error", "notification"}}
+		for _, r := range runs {
+			rows = append(rows, []string{strconv.FormatInt(r.ID, 10),
+				r.StartedAt.Format(time.RFC3339), r.FinishedAt.Format(time.RFC3339),
+				strconv.Itoa(r.Pages), strconv.Itoa(r.Found), strconv.Itoa(r.Matched),
+				strconv.Itoa(r.New), strconv.Itoa(r.Closed), r.Error, r.Notification})
+		}
+		return writeCSV(w, rows)
+	default:
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "ID\tSTARTED\tTOOK\tPAGES\tFOUND\tMATCHED\tNEW\tCLOSED\tDIGEST\tERRORS")
+		for _, r := range runs {
+			errs := "-"
+			if r.Error != "" {
+				lines := strings.Split(r.Error, "\n")
+				errs = lines[0]
+				if len(lines) > 1 {
+					errs = fmt.Sprintf("%s (+%d more)", errs, len(lines)-1)
+				}
+			}
+			fmt.Fprintf(tw, "%d\t%s\t%s\t%d\t%d\t%d\t%d\t%d\t%s\t%s\n", r.ID,
+				r.StartedAt.Local().Format("2006-01-02 15:04"), r.FinishedAt.Sub(r.StartedAt).Round(time.Second),
+				r.Pages, r.Found, r.Matched, r.New, r.Closed, orDash(r.Notification), errs)
+		}
+		return tw.Flush()
+	}
+}
+
+// WriteRun describes one run in full, in format f. CSV isn't a good fit
+// for a single record and is written as a one-row table.
+func WriteRun(w io.Writer, f Format, r store.Run) error {
+	switch f {
+	case JSON:
+		return writeJSON(w, r)
+	case CSV:
+		return WriteRuns(w, f, []store.Run{r})
+	default:
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		fmt.Fprintf(tw, "Run:\t%d\n", r.ID)
+		fmt.Fprintf(tw, "Started:\t%s\n", r.StartedAt.Local().Format(time.DateTime))
+		fmt.Fprintf(tw, "Finished:\t%s (%s)\n", r.FinishedAt.Local().Format(time.DateTime), r.FinishedAt.Sub(r.StartedAt).Round(time.Millisecond))
+		fmt.Fprintf(tw, "Pages fetched:\t%d\n", r.Pages)
+		fmt.Fprintf(tw, "Jobs found:\t%d\n", r.Found)
+		fmt.Fprintf(tw, "Matched:\t%d\n", r.Matched)
+		fmt.Fprintf(tw, "New:\t%d\n", r.New)
+		fmt.Fprintf(tw, "Closed:\t%d\n", r.Closed)
+		fmt.Fprintf(tw, "Digest:\t%s\n", orDash(r.Notification))
+		if r.Error == "" {
+			fmt.Fprintln(tw, "Errors:\tnone")
+			return tw.Flush()
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+		fmt.Fprintln(w, "Errors:")
+		for _, line := range strings.Split(r.Error, "\n") {
+			fmt.Fprintf(w, "  %s\n", line)
+		}
+		return nil
+	}
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// formatTime formats an optional time, returning none for nil.
+func formatTime(t *time.Time, layout, none string) string {
+	if t == nil {
+		return none
+	}
+	return t.Format(layout)
+}
+
+// nonNil makes empty results encode as [] rather than null.
+func nonNil[T any](s []T) []T {
+	if s == nil {
+		return []T{}
+	}
+	return s
+}
+
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func writeCSV(w io.Writer, rows [][]string) error {
+	cw := csv.NewWriter(w)
+	cw.WriteAll(rows)
+	return cw.Error()
+}
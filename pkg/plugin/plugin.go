@@ -0,0 +1,266 @@
+// Package plugin runs executables dropped into a directory as job sources
+// and filters, so that a site or a rule the program doesn't know can be
+// added without changing it. A plugin is any executable, in any language,
+// that reads one JSON request on standard input and writes one JSON
+// response on standard output; what it writes on standard error is logged.
+//
+// Source plugins live in <dir>/sources and are used by sources of type
+// "plugin". The request is
+//
+//	{"protocol": 1, "name": "Acme", "url": "...", "board": "...",
+//	 "departments": [...], "offices": [...], "options": {...}}
+//
+// from the source's config, and the response is {"jobs": [...]}, the jobs
+// having the fields of jobwatch's JSON output. An "error" string beside
+// the jobs reports a partial scrape.
+//
+// Filter plugins live in <dir>/filters and all run, in name order, on the
+// jobs that passed the configured filters. The request is
+// {"protocol": 1, "jobs": [...]} and the response {"keep": [...]}, a bool
+// for each job.
+//
+// A plugin's name is its file name without the extension, so both
+// sources/linkedin and sources/linkedin.py are "linkedin".
+package plugin
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hunterheston/airbnb/pkg/scraper"
+)
+
+// Protocol is the version of the requests plugins are sent.
+const Protocol = 1
+
+// Config is the plugins section of the config file.
+type Config struct {
+	// Dir holds the sources and filters subdirectories. Without it there
+	// are no plugins.
+	Dir string `yaml:"dir"`
+	// Timeout bounds each run of a plugin.
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// Plugin is one executable.
+type Plugin struct {
+	Name string
+	Path string
+}
+
+// Sources lists the source plugins found in c.Dir, by name.
+func (c Config) Sources() ([]Plugin, error) {
+	return discover(c.Dir, "sources")
+}
+
+// Filters lists the filter plugins found in c.Dir, in the order they run.
+func (c Config) Filters() ([]Plugin, error) {
+	return discover(c.Dir, "filters")
+}
+
+// discover lists the executables in dir/kind. A missing directory holds no
+// plugins.
+func discover(dir, kind string) ([]Plugin, error) {
+	if dir == "" {
+		return nil, nil
+	}
+	entries, err := os.ReadDir(filepath.Join(dir, kind))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("plugins: %w", err)
+	}
+	var plugins []Plugin
+	for _, e := range entries {
+		info, err := e.Info()
+		if err != nil || !info.Mode().IsRegular() || info.Mode().Perm()&0o111 == 0 {
+			continue
+		}
+		name := strings.TrimSuffix(e.Name(), filepath.Ext(e.Name()))
+		plugins = append(plugins, Plugin{Name: name, Path: filepath.Join(dir, kind, e.Name())})
+	}
+	sort.Slice(plugins, func(i, j int) bool { return plugins[i].Name < plugins[j].Name })
+	return plugins, nil
+}
+
+// Source finds the source plugin called name.
+func (c Config) Source(name string) (Plugin, error) {
+	plugins, err := c.Sources()
+	if err != nil {
+		return Plugin{}, err
+	}
+	for _, p := range plugins {
+		if p.Name == name {
+			return p, nil
+		}
+	}
+	return Plugin{}, fmt.Errorf("plugins: no source plugin %q in %s", name, filepath.Join(c.Dir, "sources"))
+}
+
+// run sends req to the plugin and decodes its response into resp.
+func (p Plugin) run(ctx context.Context, timeout time.Duration, req, resp any) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	in, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, p.Path)
+	cmd.Stdin = bytes.NewReader(in)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	start := time.Now()
+	err = cmd.Run()
+	var last string
+	scanner := bufio.NewScanner(&stderr)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			slog.Info("plugin output", "plugin", p.Name, "line", line)
+			last = line
+		}
+	}
+	slog.Debug("ran plugin", "plugin", p.Name, "duration", time.Since(start))
+	if err != nil {
+		if ctx.Err() != nil {
+			err = ctx.Err()
+		}
+		if last != "" {
+			return fmt.Errorf("plugin %s: %w: %s", p.Name, err, last)
+		}
+		return fmt.Errorf("plugin %s: %w", p.Name, err)
+	}
+	if err := json.Unmarshal(stdout.Bytes(), resp); err != nil {
+		return fmt.Errorf("plugin %s: decoding its output: %w", p.Name, err)
+	}
+	return nil
+}
+
+// Filter runs every filter plugin on jobs in turn and returns the jobs all
+// of them kept.
+func (c Config) Filter(ctx context.Context, jobs []scraper.JobPosting) ([]scraper.JobPosting, error) {
+	filters, err := c.Filters()
+	if err != nil {
+		return jobs, err
+	}
+	for _, p := range filters {
+		if len(jobs) == 0 {
+			break
+		}
+		req := struct {
+			Protocol int                  `json:"protocol"`
+			Jobs     []scraper.JobPosting `json:"jobs"`
+		}{Protocol, jobs}
+		var resp struct {
+			Keep []bool `json:"keep"`
+		}
+		if err := p.run(ctx, c.Timeout, req, &resp); err != nil {
+			return jobs, err
+		}
+		if len(resp.Keep) != len(jobs) {
+			return jobs, fmt.Errorf("plugin %s: kept %d of %d jobs; it must answer for each", p.Name, len(resp.Keep), len(jobs))
+		}
+		var kept []scraper.JobPosting
+		for i, job := range jobs {
+			if resp.Keep[i] {
+				kept = append(kept, job)
+			}
+		}
+		slog.Info("filter plugin ran", "plugin", p.Name, "jobs", len(jobs), "kept", len(kept))
+		jobs = kept
+	}
+	return jobs, nil
+}
+
+// SourceFactory returns the factory of "plugin" sources, which run the
+// source plugin the source's plugin setting names.
+func (c Config) SourceFactory() scraper.Factory {
+	return func(cfg scraper.SourceConfig, env scraper.Env) (scraper.Source, error) {
+		if cfg.Plugin == "" {
+			return nil, errors.New("plugin must be set")
+		}
+		if cfg.Name == "" {
+			return nil, errors.New("name must be set")
+		}
+		p, err := c.Source(cfg.Plugin)
+		if err != nil {
+			return nil, err
+		}
+		return &Source{Config: cfg, Plugin: p, Timeout: c.Timeout}, nil
+	}
+}
+
+// Source scrapes jobs by running a source plugin.
+type Source struct {
+	Config  scraper.SourceConfig
+	Plugin  Plugin
+	Timeout time.Duration
+}
+
+// Name implements scraper.Source.
+func (s *Source) Name() string {
+	return s.Config.Name
+}
+
+// sourceRequest is what a source plugin is sent.
+type sourceRequest struct {
+	Protocol    int            `json:"protocol"`
+	Name        string         `json:"name"`
+	URL         string         `json:"url,omitempty"`
+	Board       string         `json:"board,omitempty"`
+	Departments []string       `json:"departments,omitempty"`
+	Offices     []string       `json:"offices,omitempty"`
+	Options     map[string]any `json:"options,omitempty"`
+}
+
+// Scrape implements scraper.Source. Jobs without a company are listed
+// under the source's name.
+func (s *Source) Scrape(ctx context.Context) ([]scraper.JobPosting, error) {
+	c := s.Config
+	req := sourceRequest{
+		Protocol:    Protocol,
+		Name:        c.Name,
+		URL:         c.URL,
+		Board:       c.Board,
+		Departments: c.Departments,
+		Offices:     c.Offices,
+		Options:     c.Options,
+	}
+	var resp struct {
+		Jobs  []scraper.JobPosting `json:"jobs"`
+		Error string               `json:"error"`
+	}
+	if err := s.Plugin.run(ctx, s.Timeout, req, &resp); err != nil {
+		return nil, err
+	}
+	var jobs []scraper.JobPosting
+	for _, job := range resp.Jobs {
+		if job.Title == "" || job.URL == "" {
+			continue
+		}
+		if job.Company == "" {
+			job.Company = c.Name
+		}
+		jobs = append(jobs, job)
+	}
+	if resp.Error != "" {
+		return jobs, fmt.Errorf("plugin %s: %s", s.Plugin.Name, resp.Error)
+	}
+	return jobs, nil
+}
@@ -0,0 +1,70 @@
+// Package score ranks job postings against a profile of weighted keywords.
+package score
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hunterheston/airbnb/pkg/scraper"
+)
+
+// Profile weights keywords by how interesting they make a job, e.g.
+// {"Go": 5, "Kubernetes": 3, "payments": 2}. Negative weights push jobs
+// down instead.
+type Profile map[string]int
+
+// Scorer scores jobs against a Profile.
+type Scorer struct {
+	terms []term
+}
+
+type term struct {
+	weight int
+	re     *regexp.Regexp
+}
+
+// New returns a Scorer for p.
+func New(p Profile) *Scorer {
+	keywords := make([]string, 0, len(p))
+	for kw := range p {
+		keywords = append(keywords, kw)
+	}
+	sort.Strings(keywords)
+
+	s := &Scorer{}
+	for _, kw := range keywords {
+		kw = strings.TrimSpace(kw)
+		if kw == "" {
+			continue
+		}
+		// Spelled-out word boundaries, as in the level package, so that
+		// keywords like "C++" and "Node.js" match.
+		re := regexp.MustCompile(`(?i)(?:^|[^\pL\pN])` + regexp.QuoteMeta(kw) + `(?:$|[^\pL\pN])`)
+		s.terms = append(s.terms, term{p[kw], re})
+	}
+	return s
+}
+
+// Score returns the sum of the weights of the keywords job's title or
+// description mentions, as whole words ignoring case. Each keyword counts
+// once however often it appears.
+func (s *Scorer) Score(job scraper.JobPosting) int {
+	text := job.Title + "\n" + job.Description
+	total := 0
+	for _, t := range s.terms {
+		if t.re.MatchString(text) {
+			total += t.weight
+		}
+	}
+	return total
+}
+
+// Rank sets each job's Score and sorts jobs from the highest score down.
+// Jobs with equal scores keep their order.
+func (s *Scorer) Rank(jobs []scraper.JobPosting) {
+	for i := range jobs {
+		jobs[i].Score = s.Score(jobs[i])
+	}
+	sort.SliceStable(jobs, func(a, b int) bool { return jobs[a].Score > jobs[b].Score })
+}
@@ -0,0 +1,117 @@
+package scraper
+
+import (
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// AirbnbSelectors match the job list on careers.airbnb.com. Each job posting
+// is contained in a <li> inside <ul class="job-list" role="list">, and the
+// title and URL are in the <h3 class="text-size-4"> element's <a> tag. The
+// looser fallbacks keep working through small markup changes. The page
+// links, including "next", are WordPress-style "page-numbers".
+var AirbnbSelectors = Selectors{
+	Item:       SelectorList{"ul.job-list li[role='listitem']", "ul.job-list > li"},
+	Link:       SelectorList{"h3.text-size-4 a", "h3 a", "a[href*='/positions/']"},
+	Location:   SelectorList{".job-location", "[class*='location']"},
+	Pagination: SelectorList{".page-numbers", "nav.pagination a"},
+	Next:       SelectorList{"a.next.page-numbers", "a[rel~='next']"},
+}
+
+// AirbnbDetailSelectors match a careers.airbnb.com job page, newest layout
+// first.
+var AirbnbDetailSelectors = DetailSelectors{
+	Description: SelectorList{".job-description", ".entry-content", "main article"},
+	Location:    SelectorList{".job-location", ".job-detail-location"},
+	Team:        SelectorList{".job-department", ".job-team"},
+}
+
+const airbnbBaseURL = "https://careers.airbnb.com/positions/"
+
+func init() {
+	Register("airbnb", newAirbnbSource)
+}
+
+// newAirbnbSource scrapes the careers.airbnb.com list for every combination
+// of the configured departments and offices, and fills in the known
+// selectors. A browse_url that is set is scraped as is instead.
+func newAirbnbSource(cfg SourceConfig, env Env) (Source, error) {
+	if cfg.BrowseURL != "" {
+		cfg = airbnbDefaults(cfg)
+		cfg.URL = cfg.BrowseURL + "&_paged="
+		return newHTMLSource(cfg, env)
+	}
+	cfg = airbnbDefaults(cfg)
+	urls := airbnbListURLs(cfg)
+	if len(urls) == 1 {
+		cfg.URL = urls[0] + "&_paged="
+		return newHTMLSource(cfg, env)
+	}
+	merged := &MergedSource{Company: cfg.Name}
+	for _, u := range urls {
+		part := cfg
+		part.URL = u + "&_paged="
+		src, err := newHTMLSource(part, env)
+		if err != nil {
+			return nil, err
+		}
+		merged.Sources = append(merged.Sources, src)
+	}
+	return merged, nil
+}
+
+func airbnbDefaults(cfg SourceConfig) SourceConfig {
+	if cfg.Name == "" {
+		cfg.Name = "Airbnb"
+	}
+	if cfg.Selectors.IsZero() {
+		cfg.Selectors = AirbnbSelectors
+	}
+	if cfg.Detail.IsZero() {
+		structured := cfg.Detail.StructuredData
+		cfg.Detail = AirbnbDetailSelectors
+		cfg.Detail.StructuredData = structured
+	}
+	if cfg.BrowseURL == "" {
+		// FacetWP, which filters the list, takes several values separated
+		// by commas.
+		cfg.BrowseURL = airbnbURL(
+			strings.Join(airbnbSlugs(cfg.Department, cfg.Departments), ","),
+			strings.Join(airbnbSlugs(cfg.Office, cfg.Offices), ","))
+	}
+	return cfg
+}
+
+// airbnbListURLs returns a list URL per department and office pair.
+func airbnbListURLs(cfg SourceConfig) []string {
+	var urls []string
+	for _, dept := range airbnbSlugs(cfg.Department, cfg.Departments) {
+		for _, office := range airbnbSlugs(cfg.Office, cfg.Offices) {
+			urls = append(urls, airbnbURL(dept, office))
+		}
+	}
+	return urls
+}
+
+// airbnbSlugs merges the singular and list forms of a setting, without
+// duplicates. With neither, it is one empty slug, which doesn't filter.
+func airbnbSlugs(one string, many []string) []string {
+	var slugs []string
+	for _, s := range append([]string{one}, many...) {
+		if s != "" && !slices.Contains(slugs, s) {
+			slugs = append(slugs, s)
+		}
+	}
+	if len(slugs) == 0 {
+		return []string{""}
+	}
+	return slugs
+}
+
+func airbnbURL(departments, offices string) string {
+	q := url.Values{}
+	q.Set("_departments", departments)
+	q.Set("_offices", offices)
+	return airbnbBaseURL + "?" + q.Encode()
+}
@@ -0,0 +1,111 @@
+package scraper
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// ArchiveIndex is the file in an archive directory listing the URL of
+// every page it holds.
+const ArchiveIndex = "index.tsv"
+
+// Archive keeps the raw pages of one scrape, so a page that stops parsing
+// can be looked at and the parser run against it again offline with
+// ReplayFetcher.
+type Archive struct {
+	// Dir holds the pages; it is created on first use.
+	Dir string
+
+	mu sync.Mutex
+}
+
+// NewArchive returns an archive for a scrape started at now, in a
+// subdirectory of root named after that time.
+func NewArchive(root string, now time.Time) *Archive {
+	return &Archive{Dir: filepath.Join(root, now.Format("2006-01-02T150405"))}
+}
+
+// save stores body as the page at url and lists it in the index. For a
+// POST, url is followed by the request body; see requestKey.
+func (a *Archive) save(url string, body []byte) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if err := os.MkdirAll(a.Dir, 0o755); err != nil {
+		return err
+	}
+	if err := writeFile(archivePath(a.Dir, url), body); err != nil {
+		return err
+	}
+	index, err := os.OpenFile(filepath.Join(a.Dir, ArchiveIndex), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(index, "%s\t%s\n", filepath.Base(archivePath(a.Dir, url)), url)
+	if closeErr := index.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// archivePath is where the page at url is kept in dir.
+func archivePath(dir, url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return filepath.Join(dir, hex.EncodeToString(sum[:16])+".html")
+}
+
+// ArchiveFetcher saves every page the wrapped Fetcher returns in Archive.
+// A page that can't be saved is still returned.
+type ArchiveFetcher struct {
+	Fetcher Fetcher
+	Archive *Archive
+}
+
+// Fetch implements Fetcher.
+func (f *ArchiveFetcher) Fetch(ctx context.Context, url string) (io.ReadCloser, error) {
+	body, err := f.Fetcher.Fetch(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(body)
+	body.Close()
+	if err != nil {
+		return nil, err
+	}
+	if err := f.Archive.save(requestKey(ctx, url), data); err != nil {
+		slog.Warn("archiving page", "url", url, "err", err)
+	}
+	r := io.NopCloser(bytes.NewReader(data))
+	if _, ok := body.(notModified); ok {
+		return notModified{r}, nil
+	}
+	return r, nil
+}
+
+// ReplayFetcher serves the pages saved in an Archive directory instead of
+// fetching them.
+type ReplayFetcher struct {
+	Dir string
+}
+
+// Fetch implements Fetcher.
+func (f *ReplayFetcher) Fetch(ctx context.Context, url string) (io.ReadCloser, error) {
+	body, err := os.Open(archivePath(f.Dir, requestKey(ctx, url)))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%s is not in the archive %s", url, f.Dir)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return body, nil
+}
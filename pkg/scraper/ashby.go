@@ -0,0 +1,121 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const ashbyAPI = "https://api.ashbyhq.com/posting-api/job-board/"
+
+// AshbySource lists jobs through an Ashby job board's public posting API.
+type AshbySource struct {
+	Company string
+	// Board is the job board name, as in jobs.ashbyhq.com/<board>.
+	Board string
+	// Departments and Offices restrict the jobs returned, matching the
+	// job's department or team and any of its locations. Names are
+	// compared case-insensitively; empty means no restriction.
+	Departments []string
+	Offices     []string
+
+	Fetcher Fetcher
+}
+
+func init() {
+	Register("ashby", newAshbySource)
+}
+
+func newAshbySource(cfg SourceConfig, env Env) (Source, error) {
+	if cfg.Board == "" {
+		return nil, errors.New("board must be set")
+	}
+	name := cfg.Name
+	if name == "" {
+		name = cfg.Board
+	}
+	return &AshbySource{
+		Company:     name,
+		Board:       cfg.Board,
+		Departments: cfg.Departments,
+		Offices:     cfg.Offices,
+		Fetcher:     env.Fetcher,
+	}, nil
+}
+
+// Name implements Source.
+func (s *AshbySource) Name() string {
+	return s.Company
+}
+
+type ashbyJobs struct {
+	Jobs []struct {
+		Title              string `json:"title"`
+		JobURL             string `json:"jobUrl"`
+		Location           string `json:"location"`
+		SecondaryLocations []struct {
+			Location string `json:"location"`
+		} `json:"secondaryLocations"`
+		Department       string `json:"department"`
+		Team             string `json:"team"`
+		IsListed         bool   `json:"isListed"`
+		DescriptionPlain string `json:"descriptionPlain"`
+		DescriptionHTML  string `json:"descriptionHtml"`
+		PublishedAt      string `json:"publishedAt"`
+	} `json:"jobs"`
+}
+
+// Scrape implements Source. Jobs the board doesn't list publicly are left
+// out.
+func (s *AshbySource) Scrape(ctx context.Context) ([]JobPosting, error) {
+	apiURL := ashbyAPI + url.PathEscape(s.Board)
+	start := time.Now()
+	var resp ashbyJobs
+	if err := fetchJSON(ctx, s.Fetcher, apiURL, &resp); err != nil {
+		return nil, err
+	}
+
+	var jobs []JobPosting
+	for _, j := range resp.Jobs {
+		if !j.IsListed {
+			continue
+		}
+		var locations []string
+		if j.Location != "" {
+			locations = append(locations, j.Location)
+		}
+		for _, l := range j.SecondaryLocations {
+			locations = append(locations, l.Location)
+		}
+		if !matchesAny(s.Departments, []string{j.Department, j.Team}) || !matchesAny(s.Offices, locations) {
+			continue
+		}
+
+		team := j.Department
+		if team == "" {
+			team = j.Team
+		}
+		description := cleanText(j.DescriptionPlain)
+		if description == "" {
+			description = htmlToText(j.DescriptionHTML)
+		}
+		job := JobPosting{
+			Company:     s.Company,
+			Title:       strings.TrimSpace(j.Title),
+			URL:         j.JobURL,
+			Location:    strings.Join(locations, " / "),
+			Team:        team,
+			Description: description,
+		}
+		if t, err := time.Parse(time.RFC3339, j.PublishedAt); err == nil {
+			job.PostedAt = &t
+		}
+		jobs = append(jobs, job)
+	}
+	slog.Info("fetched Ashby job board", "source", s.Company, "url", apiURL,
+		"duration", time.Since(start), "listed", len(resp.Jobs), "jobs_found", len(jobs))
+	return jobs, nil
+}
@@ -0,0 +1,114 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/chromedp"
+)
+
+// Renderers, as named by SourceConfig.Renderer.
+const (
+	RendererHTTP    = "http"
+	RendererBrowser = "browser"
+)
+
+// BrowserFetcher loads pages in headless Chrome and returns the HTML once
+// scripts have run, for careers pages that fill in their job lists
+// client-side. Chrome (or Chromium) must be installed; CHROME_PATH points
+// at it if it isn't found on its own. Each page gets a fresh browser, so
+// nothing keeps running between fetches.
+type BrowserFetcher struct {
+	// Header is sent with every request. Its User-Agent defaults to
+	// DefaultUserAgent.
+	Header http.Header
+	// Proxy is the proxy URL Chrome should use, if any.
+	Proxy string
+	// WaitFor is a CSS selector that must match an element before the page
+	// is read, such as the job list. Without it the page is read as soon as
+	// it has loaded.
+	WaitFor string
+	// Timeout, if set, bounds each page, starting the browser included.
+	Timeout time.Duration
+}
+
+// Fetch implements Fetcher. Non-200 responses are reported as a
+// *StatusError.
+func (f *BrowserFetcher) Fetch(ctx context.Context, url string) (io.ReadCloser, error) {
+	if _, ok := postFrom(ctx); ok {
+		return nil, errors.New("the browser renderer can only fetch pages, not post to APIs")
+	}
+	if f.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, f.Timeout)
+		defer cancel()
+	}
+
+	header := network.Headers{}
+	userAgent := DefaultUserAgent
+	for name, values := range f.Header {
+		if http.CanonicalHeaderKey(name) == "User-Agent" {
+			userAgent = values[0]
+			continue
+		}
+		header[name] = strings.Join(values, ", ")
+	}
+	opts := append(chromedp.DefaultExecAllocatorOptions[:], chromedp.UserAgent(userAgent))
+	if f.Proxy != "" {
+		opts = append(opts, chromedp.ProxyServer(f.Proxy))
+	}
+	if path := os.Getenv("CHROME_PATH"); path != "" {
+		opts = append(opts, chromedp.ExecPath(path))
+	}
+	if os.Geteuid() == 0 {
+		// Chrome won't run its sandbox as root, as in most containers.
+		opts = append(opts, chromedp.NoSandbox)
+	}
+	allocCtx, cancelAlloc := chromedp.NewExecAllocator(ctx, opts...)
+	defer cancelAlloc()
+	tabCtx, cancelTab := chromedp.NewContext(allocCtx)
+	defer cancelTab()
+
+	var resp *network.Response
+	var html string
+	err := chromedp.Run(tabCtx,
+		network.SetExtraHTTPHeaders(header),
+		chromedp.ActionFunc(func(ctx context.Context) error {
+			var err error
+			resp, err = chromedp.RunResponse(ctx, chromedp.Navigate(url))
+			return err
+		}),
+	)
+	if err != nil {
+		return nil, browserError(ctx, url, err)
+	}
+	if resp != nil && resp.Status != http.StatusOK {
+		return nil, &StatusError{StatusCode: int(resp.Status)}
+	}
+
+	var actions []chromedp.Action
+	if f.WaitFor != "" {
+		actions = append(actions, chromedp.WaitReady(f.WaitFor, chromedp.ByQuery))
+	}
+	actions = append(actions, chromedp.OuterHTML("html", &html, chromedp.ByQuery))
+	if err := chromedp.Run(tabCtx, actions...); err != nil {
+		return nil, browserError(ctx, url, err)
+	}
+	return io.NopCloser(strings.NewReader(html)), nil
+}
+
+// browserError reports ctx's error when it is what stopped the browser,
+// so callers can tell timeouts and cancellation apart.
+func browserError(ctx context.Context, url string, err error) error {
+	if ctx.Err() != nil {
+		return fmt.Errorf("rendering %s: %w", url, ctx.Err())
+	}
+	return fmt.Errorf("rendering %s: %w", url, err)
+}
@@ -0,0 +1,179 @@
+package scraper
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// Cache keeps fetched pages on disk along with their ETag and Last-Modified
+// headers, so HTTPFetcher can ask the server whether a page changed instead
+// of downloading it again. It also remembers what each page parsed to, so an
+// unchanged page doesn't need parsing either.
+type Cache struct {
+	// Dir holds the cached files; it is created on first use.
+	Dir string
+}
+
+// cacheEntry is the metadata stored next to a cached body.
+type cacheEntry struct {
+	URL          string `json:"url"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+}
+
+// path returns the file for key with the given extension. Keys are hashed
+// since URLs don't make good file names.
+func (c *Cache) path(key, ext string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.Dir, hex.EncodeToString(sum[:16])+ext)
+}
+
+// validators adds to req the conditional request headers for a cached copy
+// of url, if there is one.
+func (c *Cache) validators(req *http.Request, url string) {
+	data, err := os.ReadFile(c.path(url, ".json"))
+	if err != nil {
+		return
+	}
+	var e cacheEntry
+	if json.Unmarshal(data, &e) != nil || e.URL != url {
+		return
+	}
+	if _, err := os.Stat(c.path(e.URL, ".body")); err != nil {
+		return
+	}
+	if e.ETag != "" {
+		req.Header.Set("If-None-Match", e.ETag)
+	}
+	if e.LastModified != "" {
+		req.Header.Set("If-Modified-Since", e.LastModified)
+	}
+}
+
+// body opens the cached copy of url, marked as not modified.
+func (c *Cache) body(url string) (io.ReadCloser, error) {
+	f, err := os.Open(c.path(url, ".body"))
+	if err != nil {
+		return nil, err
+	}
+	return notModified{f}, nil
+}
+
+// store saves a 200 response's body and validators. Responses without
+// either validator aren't worth keeping.
+func (c *Cache) store(url string, header http.Header, body []byte) error {
+	e := cacheEntry{URL: url, ETag: header.Get("ETag"), LastModified: header.Get("Last-Modified")}
+	if e.ETag == "" && e.LastModified == "" {
+		return nil
+	}
+	meta, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(c.Dir, 0o755); err != nil {
+		return err
+	}
+	// The body goes first so that metadata never points at a missing or
+	// stale one.
+	if err := writeFile(c.path(url, ".body"), body); err != nil {
+		return err
+	}
+	return writeFile(c.path(url, ".json"), meta)
+}
+
+// page returns what the page at key parsed to last time.
+func (c *Cache) page(key string) (Page, bool) {
+	data, err := os.ReadFile(c.path(key, ".page"))
+	if err != nil {
+		return Page{}, false
+	}
+	var p Page
+	if json.Unmarshal(data, &p) != nil {
+		return Page{}, false
+	}
+	return p, true
+}
+
+// setPage records what the page at key parsed to.
+func (c *Cache) setPage(key string, p Page) error {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(c.Dir, 0o755); err != nil {
+		return err
+	}
+	return writeFile(c.path(key, ".page"), data)
+}
+
+// writeFile replaces path atomically, so a crash never leaves half a file.
+func writeFile(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+	return err
+}
+
+// notModified is a cached body returned for a 304 response.
+type notModified struct {
+	io.ReadCloser
+}
+
+// NotModified reports whether body was served from the cache because the
+// server said the page hasn't changed.
+func NotModified(body io.Reader) bool {
+	_, ok := body.(notModified)
+	return ok
+}
+
+// cachedFetch is HTTPFetcher.Fetch with the cache in front of it.
+func (f *HTTPFetcher) cachedFetch(client *http.Client, req *http.Request) (io.ReadCloser, error) {
+	url := req.URL.String()
+	f.Cache.validators(req, url)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusNotModified:
+		body, err := f.Cache.body(url)
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, &StatusError{StatusCode: resp.StatusCode}
+		}
+		return body, err
+	case http.StatusOK:
+		data, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, err
+		}
+		if err := f.Cache.store(url, resp.Header, data); err != nil {
+			slog.Warn("caching page", "url", url, "err", err)
+		}
+		return io.NopCloser(bytes.NewReader(data)), nil
+	default:
+		return nil, statusError(resp)
+	}
+}
@@ -0,0 +1,214 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// DetailSelectors locate extra information on a job's own page. Each
+// field is an optional list of fallbacks; the first element matched by the
+// first matching selector is used.
+type DetailSelectors struct {
+	Description SelectorList `yaml:"description"`
+	// Location may match several elements when a job is offered in more
+	// than one place; their texts are joined with " / ".
+	Location SelectorList `yaml:"location"`
+	Team     SelectorList `yaml:"team"`
+	// Requisition matches the opening's requisition ID, e.g. "Req ID:
+	// R12345". A label before a colon is dropped.
+	Requisition SelectorList `yaml:"requisition"`
+	// Posted matches the posting date, read from a datetime or content
+	// attribute when the element has one (as <time> and <meta> do) and
+	// from its text otherwise; see ParsePosted.
+	Posted SelectorList `yaml:"posted"`
+	// StructuredData reads the schema.org JobPosting JSON-LD on the page,
+	// when it has one, in preference to the selectors; see
+	// JSONLDDetailParser. It makes detail pages be fetched even without
+	// selectors.
+	StructuredData bool `yaml:"structured_data"`
+}
+
+// IsZero reports whether no selectors are set, ignoring StructuredData.
+func (s DetailSelectors) IsZero() bool {
+	return len(s.Description) == 0 && len(s.Location) == 0 && len(s.Team) == 0 && len(s.Requisition) == 0 &&
+		len(s.Posted) == 0
+}
+
+// DetailParser fills in a job's fields from its detail page.
+type DetailParser interface {
+	ParseDetail(r io.Reader, job *JobPosting) error
+}
+
+// HTMLDetailParser is a DetailParser driven by CSS selectors.
+type HTMLDetailParser struct {
+	Selectors DetailSelectors
+}
+
+// ParseDetail implements DetailParser. Fields whose selectors are empty or
+// match nothing are left as they were.
+func (p HTMLDetailParser) ParseDetail(r io.Reader, job *JobPosting) error {
+	doc, err := goquery.NewDocumentFromReader(r)
+	if err != nil {
+		return err
+	}
+
+	if text := cleanText(p.Selectors.Description.find(doc.Selection).First().Text()); text != "" {
+		job.Description = text
+	}
+	var locations []string
+	p.Selectors.Location.find(doc.Selection).Each(func(i int, s *goquery.Selection) {
+		if text := collapseSpace(s.Text()); text != "" {
+			locations = append(locations, text)
+		}
+	})
+	if len(locations) > 0 {
+		job.Location = strings.Join(locations, " / ")
+	}
+	if text := collapseSpace(p.Selectors.Team.find(doc.Selection).First().Text()); text != "" {
+		job.Team = text
+	}
+	text := collapseSpace(p.Selectors.Requisition.find(doc.Selection).First().Text())
+	if _, id, ok := strings.Cut(text, ":"); ok {
+		text = strings.TrimSpace(id)
+	}
+	if text != "" {
+		job.Requisition = text
+	}
+	if sel := p.Selectors.Posted.find(doc.Selection).First(); sel.Length() > 0 {
+		text := sel.AttrOr("datetime", sel.AttrOr("content", sel.Text()))
+		if t, ok := ParsePosted(text, time.Now()); ok {
+			job.PostedAt = &t
+		}
+	}
+	return nil
+}
+
+// enrich fetches every job's detail page with at most workers requests in
+// flight and lets p fill in the job. Jobs whose page fails, or that weren't
+// reached before ctx was done, are left as they were; the failures are
+// returned together.
+func enrich(ctx context.Context, jobs []JobPosting, f Fetcher, p DetailParser, workers int) error {
+	if workers < 1 {
+		workers = 1
+	}
+
+	var (
+		mu   sync.Mutex
+		errs []error
+		wg   sync.WaitGroup
+	)
+	indexes := make(chan int)
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				if err := enrichOne(ctx, &jobs[i], f, p); err != nil {
+					mu.Lock()
+					errs = append(errs, fmt.Errorf("detail page %s: %w", jobs[i].URL, err))
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < len(jobs) && ctx.Err() == nil; i++ {
+		if jobs[i].URL == "" {
+			continue
+		}
+		select {
+		case indexes <- i:
+		case <-ctx.Done():
+		}
+	}
+	close(indexes)
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+func enrichOne(ctx context.Context, job *JobPosting, f Fetcher, p DetailParser) error {
+	body, err := f.Fetch(ctx, job.URL)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	return p.ParseDetail(body, job)
+}
+
+// apiDetailFetcher fetches a job board API's record of a job in place of
+// the page at the job's URL, the API URL being the job URL with its Site
+// prefix replaced by API. It lets enrich read the details of API sources.
+type apiDetailFetcher struct {
+	Fetcher   Fetcher
+	Site, API string
+}
+
+func (f *apiDetailFetcher) Fetch(ctx context.Context, jobURL string) (io.ReadCloser, error) {
+	path, ok := strings.CutPrefix(jobURL, f.Site)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a job on %s", jobURL, f.Site)
+	}
+	return f.Fetcher.Fetch(ctx, f.API+path)
+}
+
+// collapseSpace joins the words of s with single spaces.
+func collapseSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// cleanText trims every line of s and drops blank runs, keeping paragraph
+// breaks.
+func cleanText(s string) string {
+	var lines []string
+	blank := false
+	for _, line := range strings.Split(s, "\n") {
+		line = collapseSpace(line)
+		if line == "" {
+			blank = len(lines) > 0
+			continue
+		}
+		if blank {
+			lines = append(lines, "")
+			blank = false
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Excerpt returns roughly the first n characters of s, cut at a word
+// boundary, with line breaks flattened.
+func Excerpt(s string, n int) string {
+	s = collapseSpace(s)
+	if len(s) <= n {
+		return s
+	}
+	cut := strings.LastIndexByte(s[:n], ' ')
+	if cut <= 0 {
+		cut = n
+	}
+	return strings.TrimRight(s[:cut], ",.;:") + "…"
+}
+
+// detailParser returns the parser for the configured detail pages, or nil
+// if they aren't read.
+func (s DetailSelectors) detailParser() DetailParser {
+	var p DetailParser
+	if !s.IsZero() {
+		p = HTMLDetailParser{Selectors: s}
+	}
+	if s.StructuredData {
+		p = JSONLDDetailParser{Fallback: p}
+	}
+	return p
+}
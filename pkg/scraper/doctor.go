@@ -0,0 +1,264 @@
+package scraper
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Check is the outcome of one of the checks Diagnose runs on a source.
+type Check struct {
+	Name string
+	// Note says what was found, e.g. how many elements a selector matched.
+	Note string
+	// Err is nil if the check passed.
+	Err error
+	// Optional checks fail without failing the source, such as a pagination
+	// selector on a list that fits on one page.
+	Optional bool
+}
+
+// Checker is implemented by sources that can check themselves more closely
+// than by scraping.
+type Checker interface {
+	Check(ctx context.Context) []Check
+}
+
+// Diagnose checks that src still works against the live site. Sources that
+// implement Checker check themselves; any other source is scraped and its
+// jobs looked at.
+func Diagnose(ctx context.Context, src Source) []Check {
+	if c, ok := src.(Checker); ok {
+		return c.Check(ctx)
+	}
+	jobs, err := src.Scrape(ctx)
+	checks := []Check{{Name: "scrape", Note: fmt.Sprintf("%d jobs", len(jobs)), Err: err}}
+	return append(checks, checkJobs(jobs)...)
+}
+
+// Failed reports whether any required check failed.
+func Failed(checks []Check) bool {
+	for _, c := range checks {
+		if c.Err != nil && !c.Optional {
+			return true
+		}
+	}
+	return false
+}
+
+// Check implements Checker. It fetches the first list page, checks every
+// selector against it and that its jobs parse, and, if job pages are read
+// too, checks the detail selectors against the first job's page.
+func (s *HTMLSource) Check(ctx context.Context) []Check {
+	pageURL := s.pageURL(1)
+	data, err := fetchAll(ctx, s.Fetcher, pageURL)
+	checks := []Check{{Name: "fetch " + pageURL, Err: err}}
+	if err != nil {
+		return checks
+	}
+
+	if p, ok := s.Parser.(HTMLParser); ok {
+		doc, err := goquery.NewDocumentFromReader(bytes.NewReader(data))
+		if err != nil {
+			return append(checks, Check{Name: "parse HTML", Err: err})
+		}
+		checks = append(checks, checkSelectors(p.Selectors, doc.Selection)...)
+	}
+
+	page, err := parsePage(s.Parser, bytes.NewReader(data))
+	if err != nil {
+		return append(checks, Check{Name: "parse jobs", Err: err})
+	}
+	base, _ := url.Parse(pageURL)
+	for i := range page.Jobs {
+		page.Jobs[i].URL = resolveURL(base, page.Jobs[i].URL)
+	}
+	checks = append(checks, checkJobs(page.Jobs)...)
+
+	if s.Detail != nil {
+		if job, ok := firstUsable(page.Jobs); ok {
+			checks = append(checks, s.checkDetail(ctx, job.URL)...)
+		}
+	}
+	return checks
+}
+
+// checkSelectors checks that each of sel's selectors matches something in
+// doc. Link and Location must match within at least one item.
+func checkSelectors(sel Selectors, doc *goquery.Selection) []Check {
+	items := sel.Item.find(doc)
+	checks := []Check{selectorCheck("item", sel.Item, items.Length(), false)}
+	if items.Length() == 0 {
+		return checks
+	}
+	within := func(l SelectorList) int {
+		n := 0
+		items.Each(func(i int, item *goquery.Selection) {
+			if l.find(item).Length() > 0 {
+				n++
+			}
+		})
+		return n
+	}
+	checks = append(checks, itemCheck("link", sel.Link, within(sel.Link), items.Length()))
+	if len(sel.Location) > 0 {
+		checks = append(checks, itemCheck("location", sel.Location, within(sel.Location), items.Length()))
+	}
+	if len(sel.Pagination) > 0 {
+		checks = append(checks, selectorCheck("pagination", sel.Pagination, sel.Pagination.find(doc).Length(), true))
+	}
+	if len(sel.Next) > 0 {
+		checks = append(checks, selectorCheck("next", sel.Next, sel.Next.find(doc).Length(), true))
+	}
+	return checks
+}
+
+// checkDetail checks the detail selectors against the job page at jobURL.
+func (s *HTMLSource) checkDetail(ctx context.Context, jobURL string) []Check {
+	data, err := fetchAll(ctx, s.Fetcher, jobURL)
+	checks := []Check{{Name: "fetch job page " + jobURL, Err: err}}
+	if err != nil {
+		return checks
+	}
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(data))
+	if err != nil {
+		return append(checks, Check{Name: "parse job page", Err: err})
+	}
+	detail := s.Detail
+	if j, ok := detail.(JSONLDDetailParser); ok {
+		c := Check{Name: "structured data", Note: "JobPosting JSON-LD found", Optional: j.Fallback != nil}
+		if _, found := findJSONLDPosting(doc); !found {
+			c.Note, c.Err = "", errors.New("no JobPosting JSON-LD on the page")
+		}
+		checks = append(checks, c)
+		if j.Fallback == nil {
+			return checks
+		}
+		detail = j.Fallback
+	}
+	p, ok := detail.(HTMLDetailParser)
+	if !ok {
+		var job JobPosting
+		return append(checks, Check{Name: "parse job page", Err: detail.ParseDetail(bytes.NewReader(data), &job)})
+	}
+	for _, f := range []struct {
+		field string
+		list  SelectorList
+	}{
+		{"detail description", p.Selectors.Description},
+		{"detail location", p.Selectors.Location},
+		{"detail team", p.Selectors.Team},
+		{"detail requisition", p.Selectors.Requisition},
+		{"detail posted", p.Selectors.Posted},
+	} {
+		if len(f.list) > 0 {
+			checks = append(checks, selectorCheck(f.field, f.list, f.list.find(doc.Selection).Length(), false))
+		}
+	}
+	return checks
+}
+
+func selectorCheck(field string, l SelectorList, matches int, optional bool) Check {
+	c := Check{Name: field + " selector", Note: fmt.Sprintf("%d matches", matches), Optional: optional}
+	if matches == 0 {
+		c.Err = fmt.Errorf("nothing matched %s", l)
+	}
+	return c
+}
+
+func itemCheck(field string, l SelectorList, matched, items int) Check {
+	c := Check{Name: field + " selector", Note: fmt.Sprintf("found in %d of %d items", matched, items)}
+	if matched == 0 {
+		c.Err = fmt.Errorf("nothing matched %s in any item", l)
+	}
+	return c
+}
+
+// checkJobs checks that at least one job has a title and an absolute URL.
+// Jobs missing either only make an optional check fail.
+func checkJobs(jobs []JobPosting) []Check {
+	good := 0
+	for _, job := range jobs {
+		if usable(job) {
+			good++
+		}
+	}
+	c := Check{Name: "jobs", Note: fmt.Sprintf("%d of %d have a title and an absolute URL", good, len(jobs))}
+	switch {
+	case len(jobs) == 0:
+		c.Err = errors.New("no jobs parsed")
+	case good == 0:
+		c.Err = errors.New("no job has both a title and an absolute URL")
+	}
+	checks := []Check{c}
+	if good > 0 && good < len(jobs) {
+		checks = append(checks, Check{
+			Name:     "every job",
+			Err:      fmt.Errorf("%d jobs lack a title or an absolute URL", len(jobs)-good),
+			Optional: true,
+		})
+	}
+	return checks
+}
+
+func firstUsable(jobs []JobPosting) (JobPosting, bool) {
+	for _, job := range jobs {
+		if usable(job) {
+			return job, true
+		}
+	}
+	return JobPosting{}, false
+}
+
+// usable reports whether job has a title and an absolute URL.
+func usable(job JobPosting) bool {
+	u, err := url.Parse(job.URL)
+	return job.Title != "" && err == nil && u.IsAbs() && u.Host != ""
+}
+
+func fetchAll(ctx context.Context, f Fetcher, url string) ([]byte, error) {
+	body, err := f.Fetch(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	return io.ReadAll(body)
+}
+
+// Check implements Checker, checking the fallback as well since it is only
+// any use if it works.
+func (s *FallbackSource) Check(ctx context.Context) []Check {
+	checks := Diagnose(ctx, s.Primary)
+	for _, c := range Diagnose(ctx, s.Fallback) {
+		c.Name = "fallback: " + c.Name
+		checks = append(checks, c)
+	}
+	return checks
+}
+
+// Check implements Checker. As when scraping, a listing that fails its
+// checks only fails the source when no listing passes, since one filter
+// combination can legitimately have no jobs.
+func (s *MergedSource) Check(ctx context.Context) []Check {
+	var checks []Check
+	passed := false
+	var parts [][]Check
+	for _, src := range s.Sources {
+		part := Diagnose(ctx, src)
+		passed = passed || !Failed(part)
+		parts = append(parts, part)
+	}
+	for i, part := range parts {
+		for _, c := range part {
+			c.Name = fmt.Sprintf("listing %d/%d: %s", i+1, len(parts), c.Name)
+			c.Optional = c.Optional || passed
+			checks = append(checks, c)
+		}
+	}
+	return checks
+}
@@ -0,0 +1,238 @@
+package scraper
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// exportFields maps the column names other aggregators' exports use, once
+// lower-cased with spaces and dashes turned into underscores, to the
+// JobPosting field they hold. jobwatch's own CSV and JSON names are among
+// them.
+var exportFields = map[string]string{
+	"title": "title", "job_title": "title", "jobtitle": "title", "position": "title",
+	"url": "url", "link": "url", "job_url": "url", "joburl": "url", "apply_url": "url", "job_link": "url",
+	"company": "company", "company_name": "company", "employer": "company", "organization": "company",
+	"location": "location", "job_location": "location", "city": "location",
+	"team": "team", "department": "team",
+	"description": "description", "job_description": "description", "summary": "description", "snippet": "description",
+	"requisition": "requisition", "req_id": "requisition", "job_id": "requisition",
+	"posted_at": "posted", "date_posted": "posted", "dateposted": "posted", "posted": "posted",
+	"published_at": "posted", "date": "posted", "listed_at": "posted",
+	"salary": "salary", "salary_min": "salary_min", "salary_max": "salary_max",
+}
+
+// ExportSource reads jobs from a CSV or JSON file exported by another job
+// aggregator, or by jobwatch itself, from Path or fetched from URL. Columns
+// are matched by name; see exportFields. Jobs are listed under their
+// company column, or under Company without one. An export holds whatever
+// its search found, so jobs missing from it aren't closed.
+type ExportSource struct {
+	Company string
+	Path    string
+	URL     string
+	// TitlePattern, if set, splits titles into fields; see
+	// parseTitlePattern.
+	TitlePattern *regexp.Regexp
+
+	Fetcher Fetcher
+}
+
+func init() {
+	Register("export", newExportSource)
+}
+
+func newExportSource(cfg SourceConfig, env Env) (Source, error) {
+	if (cfg.Path == "") == (cfg.URL == "") {
+		return nil, errors.New("exactly one of path and url must be set")
+	}
+	if cfg.Name == "" {
+		return nil, errors.New("name must be set")
+	}
+	pattern, err := parseTitlePattern(cfg.TitlePattern)
+	if err != nil {
+		return nil, err
+	}
+	return &ExportSource{Company: cfg.Name, Path: cfg.Path, URL: cfg.URL, TitlePattern: pattern, Fetcher: env.Fetcher}, nil
+}
+
+// Name implements Source.
+func (s *ExportSource) Name() string {
+	return s.Company
+}
+
+// Incomplete implements Incomplete.
+func (s *ExportSource) Incomplete() bool {
+	return true
+}
+
+// Scrape implements Source. Rows without a title or URL are skipped.
+func (s *ExportSource) Scrape(ctx context.Context) ([]JobPosting, error) {
+	start := time.Now()
+	data, err := s.read(ctx)
+	if err != nil {
+		return nil, err
+	}
+	records, err := exportRecords(data)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", s.location(), err)
+	}
+
+	now := time.Now()
+	var jobs []JobPosting
+	for _, r := range records {
+		job := r.posting(s.Company, now)
+		applyTitlePattern(s.TitlePattern, &job)
+		if job.Title != "" && job.URL != "" {
+			jobs = append(jobs, job)
+		}
+	}
+	slog.Info("read job export", "source", s.Company, "from", s.location(),
+		"duration", time.Since(start), "rows", len(records), "jobs_found", len(jobs))
+	return jobs, nil
+}
+
+func (s *ExportSource) location() string {
+	if s.Path != "" {
+		return s.Path
+	}
+	return s.URL
+}
+
+func (s *ExportSource) read(ctx context.Context) ([]byte, error) {
+	if s.Path != "" {
+		return os.ReadFile(s.Path)
+	}
+	body, err := s.Fetcher.Fetch(ctx, s.URL)
+	if err != nil {
+		return nil, fmt.Errorf("fetching: %w", err)
+	}
+	defer body.Close()
+	return io.ReadAll(body)
+}
+
+// exportRecord is one row of an export, by JobPosting field.
+type exportRecord map[string]string
+
+// exportRecords decodes a JSON export, a list of objects or an object with
+// a list of them under "jobs", or else a CSV export with a header row.
+func exportRecords(data []byte) ([]exportRecord, error) {
+	data = bytes.TrimPrefix(data, []byte("\ufeff"))
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && (trimmed[0] == '[' || trimmed[0] == '{') {
+		var rows []map[string]any
+		if trimmed[0] == '{' {
+			var wrapped struct {
+				Jobs []map[string]any `json:"jobs"`
+			}
+			if err := json.Unmarshal(trimmed, &wrapped); err != nil {
+				return nil, err
+			}
+			rows = wrapped.Jobs
+		} else if err := json.Unmarshal(trimmed, &rows); err != nil {
+			return nil, err
+		}
+		records := make([]exportRecord, len(rows))
+		for i, row := range rows {
+			records[i] = exportRecord{}
+			names := make([]string, 0, len(row))
+			for name := range row {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				records[i].set(name, jsonText(row[name]))
+			}
+		}
+		return records, nil
+	}
+
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = -1
+	rows, err := r.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	var records []exportRecord
+	for _, row := range rows[1:] {
+		rec := exportRecord{}
+		for i, name := range rows[0] {
+			if i < len(row) {
+				rec.set(name, row[i])
+			}
+		}
+		records = append(records, rec)
+	}
+	return records, nil
+}
+
+// set stores value under the field column holds. If several columns hold
+// the same field, the first one with a value wins: CSV columns in order,
+// JSON keys sorted.
+func (r exportRecord) set(column, value string) {
+	column = strings.NewReplacer(" ", "_", "-", "_").Replace(strings.ToLower(strings.TrimSpace(column)))
+	field, ok := exportFields[column]
+	if value = strings.TrimSpace(value); !ok || value == "" {
+		return
+	}
+	if _, dup := r[field]; !dup {
+		r[field] = value
+	}
+}
+
+// jsonText returns a JSON value as text: strings as they are, numbers in
+// full, and other values empty.
+func jsonText(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return ""
+	}
+}
+
+// posting returns the job the record describes, under company unless it
+// names its own.
+func (r exportRecord) posting(company string, now time.Time) JobPosting {
+	if r["company"] != "" {
+		company = r["company"]
+	}
+	job := JobPosting{
+		Company:     company,
+		Title:       collapseSpace(r["title"]),
+		URL:         r["url"],
+		Location:    r["location"],
+		Team:        r["team"],
+		Description: cleanText(r["description"]),
+		Requisition: r["requisition"],
+	}
+	if strings.Contains(r["description"], "</") {
+		job.Description = htmlToText(r["description"])
+	}
+	if t, ok := ParsePosted(r["posted"], now); ok {
+		job.PostedAt = &t
+	}
+	job.SalaryMin, _ = strconv.Atoi(r["salary_min"])
+	job.SalaryMax, _ = strconv.Atoi(r["salary_max"])
+	if job.SalaryMax == 0 && r["salary"] != "" {
+		job.SalaryMin, job.SalaryMax = ParseSalary(r["salary"])
+	}
+	return job
+}
@@ -0,0 +1,35 @@
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+// FallbackSource scrapes Primary and, only if that fails outright, Fallback.
+// A typical use is an API source backed by an HTML scraper for when the API
+// is unavailable.
+type FallbackSource struct {
+	Primary  Source
+	Fallback Source
+}
+
+// Name implements Source.
+func (s *FallbackSource) Name() string {
+	return s.Primary.Name()
+}
+
+// Scrape implements Source.
+func (s *FallbackSource) Scrape(ctx context.Context) ([]JobPosting, error) {
+	jobs, err := s.Primary.Scrape(ctx)
+	if err == nil || len(jobs) > 0 || ctx.Err() != nil {
+		return jobs, err
+	}
+
+	slog.Warn("source failed; falling back", "source", s.Primary.Name(), "err", err, "fallback", s.Fallback.Name())
+	jobs, fallbackErr := s.Fallback.Scrape(ctx)
+	if fallbackErr != nil {
+		return jobs, fmt.Errorf("%w; fallback: %w", err, fallbackErr)
+	}
+	return jobs, nil
+}
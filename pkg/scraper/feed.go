@@ -0,0 +1,202 @@
+package scraper
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"log/slog"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// FeedSource reads the jobs of an RSS 2.0 or Atom feed, such as the job
+// alerts LinkedIn, Indeed and other aggregators publish. Jobs are listed
+// under their employer where the feed or TitlePattern gives it, and under
+// Company otherwise. A feed only carries the latest postings, so jobs that
+// drop out of it aren't closed.
+type FeedSource struct {
+	Company string
+	URL     string
+	// TitlePattern, if set, splits item titles such as "Backend Engineer -
+	// Acme - Austin, TX" into fields; see parseTitlePattern.
+	TitlePattern *regexp.Regexp
+
+	Fetcher Fetcher
+}
+
+func init() {
+	Register("feed", newFeedSource)
+}
+
+func newFeedSource(cfg SourceConfig, env Env) (Source, error) {
+	if cfg.URL == "" {
+		return nil, errors.New("url must be set to the feed")
+	}
+	if cfg.Name == "" {
+		return nil, errors.New("name must be set")
+	}
+	pattern, err := parseTitlePattern(cfg.TitlePattern)
+	if err != nil {
+		return nil, err
+	}
+	return &FeedSource{Company: cfg.Name, URL: cfg.URL, TitlePattern: pattern, Fetcher: env.Fetcher}, nil
+}
+
+// Name implements Source.
+func (s *FeedSource) Name() string {
+	return s.Company
+}
+
+// Incomplete implements Incomplete.
+func (s *FeedSource) Incomplete() bool {
+	return true
+}
+
+// feed holds both kinds of feed: an RSS channel's items or Atom entries.
+type feed struct {
+	Items []struct {
+		Title       string `xml:"title"`
+		Link        string `xml:"link"`
+		GUID        string `xml:"guid"`
+		Description string `xml:"description"`
+		// Content is content:encoded, the full text some feeds add.
+		Content string `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+		PubDate string `xml:"pubDate"`
+		// Source is the employer on Indeed's feeds.
+		Source string `xml:"source"`
+	} `xml:"channel>item"`
+	Entries []struct {
+		Title string `xml:"title"`
+		Links []struct {
+			Href string `xml:"href,attr"`
+			Rel  string `xml:"rel,attr"`
+		} `xml:"link"`
+		Summary   string `xml:"summary"`
+		Content   string `xml:"content"`
+		Published string `xml:"published"`
+		Updated   string `xml:"updated"`
+	} `xml:"entry"`
+}
+
+// Scrape implements Source.
+func (s *FeedSource) Scrape(ctx context.Context) ([]JobPosting, error) {
+	start := time.Now()
+	body, err := s.Fetcher.Fetch(ctx, s.URL)
+	if err != nil {
+		return nil, fmt.Errorf("fetching: %w", err)
+	}
+	defer body.Close()
+	var f feed
+	dec := xml.NewDecoder(body)
+	// Feeds declare all sorts of encodings; the text is read as is.
+	dec.CharsetReader = func(charset string, input io.Reader) (io.Reader, error) { return input, nil }
+	if err := dec.Decode(&f); err != nil {
+		return nil, fmt.Errorf("decoding: %w", err)
+	}
+
+	now := time.Now()
+	var jobs []JobPosting
+	add := func(company, title, link, description, date string) {
+		if company == "" {
+			company = s.Company
+		}
+		job := JobPosting{
+			Company:     company,
+			Title:       collapseSpace(html.UnescapeString(title)),
+			URL:         strings.TrimSpace(link),
+			Description: htmlToText(description),
+		}
+		if t, ok := parseFeedDate(date, now); ok {
+			job.PostedAt = &t
+		}
+		applyTitlePattern(s.TitlePattern, &job)
+		if job.Title != "" && job.URL != "" {
+			jobs = append(jobs, job)
+		}
+	}
+	for _, item := range f.Items {
+		link := item.Link
+		if link == "" && strings.HasPrefix(item.GUID, "http") {
+			link = item.GUID
+		}
+		description := item.Description
+		if item.Content != "" {
+			description = item.Content
+		}
+		add(collapseSpace(item.Source), item.Title, link, description, item.PubDate)
+	}
+	for _, entry := range f.Entries {
+		var link string
+		for _, l := range entry.Links {
+			if l.Rel == "" || l.Rel == "alternate" {
+				link = l.Href
+				break
+			}
+		}
+		description := entry.Summary
+		if entry.Content != "" {
+			description = entry.Content
+		}
+		date := entry.Published
+		if date == "" {
+			date = entry.Updated
+		}
+		add("", entry.Title, link, description, date)
+	}
+	slog.Info("fetched feed", "source", s.Company, "url", s.URL,
+		"duration", time.Since(start), "items", len(f.Items)+len(f.Entries), "jobs_found", len(jobs))
+	return jobs, nil
+}
+
+// parseFeedDate reads an RSS (RFC 822) or Atom (RFC 3339) date, or failing
+// that any date ParsePosted understands.
+func parseFeedDate(s string, now time.Time) (time.Time, bool) {
+	s = strings.TrimSpace(s)
+	for _, layout := range []string{time.RFC1123Z, time.RFC1123, "Mon, 2 Jan 2006 15:04:05 -0700", "Mon, 2 Jan 2006 15:04:05 MST"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	return ParsePosted(s, now)
+}
+
+// parseTitlePattern compiles a title_pattern: a regular expression whose
+// named groups (?P<title>…), (?P<company>…), (?P<location>…) and
+// (?P<team>…) pick those fields out of a job title that holds several,
+// as aggregators' often do. An empty pattern returns nil.
+func parseTitlePattern(pattern string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return nil, nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("title_pattern: %w", err)
+	}
+	for _, name := range re.SubexpNames() {
+		if name == "title" {
+			return re, nil
+		}
+	}
+	return nil, errors.New("title_pattern must have a (?P<title>...) group")
+}
+
+// applyTitlePattern splits job's title with re, if it is set and matches.
+func applyTitlePattern(re *regexp.Regexp, job *JobPosting) {
+	if re == nil {
+		return
+	}
+	m := re.FindStringSubmatch(job.Title)
+	if m == nil {
+		return
+	}
+	fields := map[string]*string{"title": &job.Title, "company": &job.Company, "location": &job.Location, "team": &job.Team}
+	for name, field := range fields {
+		if i := re.SubexpIndex(name); i >= 0 && strings.TrimSpace(m[i]) != "" {
+			*field = strings.TrimSpace(m[i])
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package scraper
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"log/slog"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/hunterheston/airbnb/pkg/trace"
+)
+
+const greenhouseAPI = "https://boards-api.greenhouse.io/v1/boards/"
+
+// GreenhouseSource lists jobs through a Greenhouse job board's public JSON
+// API, which needs no CSS selectors.
+type GreenhouseSource struct {
+	Company string
+	// Board is the board token, as in boards.greenhouse.io/<board>.
+	Board string
+	// Departments and Offices restrict the jobs returned. Names are
+	// compared case-insensitively; empty means no restriction.
+	Departments []string
+	Offices     []string
+
+	Fetcher Fetcher
+}
+
+func init() {
+	Register("greenhouse", newGreenhouseSource)
+}
+
+func newGreenhouseSource(cfg SourceConfig, env Env) (Source, error) {
+	if cfg.Board == "" {
+		return nil, errors.New("board must be set")
+	}
+	name := cfg.Name
+	if name == "" {
+		name = cfg.Board
+	}
+	return &GreenhouseSource{
+		Company:     name,
+		Board:       cfg.Board,
+		Departments: cfg.Departments,
+		Offices:     cfg.Offices,
+		Fetcher:     env.Fetcher,
+	}, nil
+}
+
+// Name implements Source.
+func (s *GreenhouseSource) Name() string {
+	return s.Company
+}
+
+type greenhouseJobs struct {
+	Jobs []struct {
+		Title       string `json:"title"`
+		AbsoluteURL string `json:"absolute_url"`
+		// InternalJobID is the job the posting is for; a job posted in
+		// several locations has a posting for each.
+		InternalJobID int64 `json:"internal_job_id"`
+		// Content is the HTML-escaped job description.
+		Content string `json:"content"`
+		// FirstPublished is when the job was posted; boards that predate
+		// the field only have UpdatedAt.
+		FirstPublished string `json:"first_published"`
+		UpdatedAt      string `json:"updated_at"`
+		Location       struct {
+			Name string `json:"name"`
+		} `json:"location"`
+		Departments []struct {
+			Name string `json:"name"`
+		} `json:"departments"`
+		Offices []struct {
+			Name string `json:"name"`
+		} `json:"offices"`
+	} `json:"jobs"`
+}
+
+// Scrape implements Source.
+func (s *GreenhouseSource) Scrape(ctx context.Context) ([]JobPosting, error) {
+	// content=true is what makes the API include departments and offices.
+	apiURL := greenhouseAPI + url.PathEscape(s.Board) + "/jobs?content=true"
+	start := time.Now()
+	var resp greenhouseJobs
+	if err := fetchJSON(ctx, s.Fetcher, apiURL, &resp); err != nil {
+		return nil, err
+	}
+
+	var jobs []JobPosting
+	for _, j := range resp.Jobs {
+		var departments, offices []string
+		for _, d := range j.Departments {
+			departments = append(departments, d.Name)
+		}
+		for _, o := range j.Offices {
+			offices = append(offices, o.Name)
+		}
+		if !matchesAny(s.Departments, departments) || !matchesAny(s.Offices, append(offices, j.Location.Name)) {
+			continue
+		}
+
+		job := JobPosting{
+			Company:     s.Company,
+			Title:       strings.TrimSpace(j.Title),
+			URL:         j.AbsoluteURL,
+			Location:    j.Location.Name,
+			Team:        strings.Join(departments, ", "),
+			Description: htmlToText(html.UnescapeString(j.Content)),
+		}
+		if j.InternalJobID != 0 {
+			job.Requisition = strconv.FormatInt(j.InternalJobID, 10)
+		}
+		for _, date := range []string{j.FirstPublished, j.UpdatedAt} {
+			if t, err := time.Parse(time.RFC3339, date); err == nil {
+				job.PostedAt = &t
+				break
+			}
+		}
+		jobs = append(jobs, job)
+	}
+	slog.Info("fetched Greenhouse board", "source", s.Company, "url", apiURL,
+		"duration", time.Since(start), "listed", len(resp.Jobs), "jobs_found", len(jobs))
+	return jobs, nil
+}
+
+// fetchJSON fetches url and decodes the JSON response into v, for the
+// sources that read a job board's API.
+func fetchJSON(ctx context.Context, f Fetcher, url string, v any) error {
+	body, err := f.Fetch(ctx, url)
+	if err != nil {
+		return fmt.Errorf("fetching: %w", err)
+	}
+	defer body.Close()
+
+	_, span := trace.Start(ctx, "parse", attribute.String("url.full", url))
+	defer span.End()
+	if err := json.NewDecoder(body).Decode(v); err != nil {
+		trace.SetError(span, err)
+		return fmt.Errorf("decoding: %w", err)
+	}
+	return nil
+}
+
+// matchesAny reports whether any of have equals one of want, ignoring case.
+// An empty want matches everything.
+func matchesAny(want, have []string) bool {
+	if len(want) == 0 {
+		return true
+	}
+	for _, w := range want {
+		for _, h := range have {
+			if strings.EqualFold(w, h) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// htmlToText returns the text content of an HTML fragment.
+func htmlToText(fragment string) string {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(fragment))
+	if err != nil {
+		return ""
+	}
+	// Keep block boundaries as line breaks.
+	doc.Find("p, li, br, h1, h2, h3, h4, div").Each(func(i int, s *goquery.Selection) {
+		s.AppendHtml("\n")
+	})
+	return cleanText(doc.Text())
+}
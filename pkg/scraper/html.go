@@ -0,0 +1,376 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/hunterheston/airbnb/pkg/trace"
+)
+
+// Selectors locate job postings in a list page. Each field is a list of
+// fallbacks tried in order.
+type Selectors struct {
+	// Item matches one element per job posting.
+	Item SelectorList `yaml:"item"`
+	// Link matches, within an item, the <a> whose text is the job title and
+	// whose href is the posting URL.
+	Link SelectorList `yaml:"link"`
+	// Location optionally matches, within an item, the job location.
+	Location SelectorList `yaml:"location"`
+	// Pagination optionally matches the page-number links; the largest
+	// number is taken as the page count, which lets the remaining pages be
+	// fetched concurrently.
+	Pagination SelectorList `yaml:"pagination"`
+	// Next optionally matches the link to the following page. Without it,
+	// rel="next" links are followed when present.
+	Next SelectorList `yaml:"next"`
+}
+
+// IsZero reports whether no selectors are set.
+func (s Selectors) IsZero() bool {
+	return len(s.Item) == 0 && len(s.Link) == 0 && len(s.Location) == 0 && len(s.Pagination) == 0 && len(s.Next) == 0
+}
+
+// HTMLParser extracts job postings from an HTML list page using CSS
+// selectors.
+type HTMLParser struct {
+	Selectors Selectors
+}
+
+// Parse returns every job listed on the page, unfiltered. It returns a
+// *SelectorError if items were found but none of them had a title link.
+func (p HTMLParser) Parse(r io.Reader) ([]JobPosting, error) {
+	page, err := p.ParsePage(r)
+	return page.Jobs, err
+}
+
+// ParsePage implements PageParser.
+func (p HTMLParser) ParsePage(r io.Reader) (Page, error) {
+	doc, err := goquery.NewDocumentFromReader(r)
+	if err != nil {
+		return Page{}, err
+	}
+
+	var jobs []JobPosting
+	titled := 0
+	p.Selectors.Item.find(doc.Selection).Each(func(i int, s *goquery.Selection) {
+		jobLink := p.Selectors.Link.find(s).First()
+		title := strings.TrimSpace(jobLink.Text())
+		link, exists := jobLink.Attr("href")
+		if !exists {
+			link = ""
+		}
+		if title != "" {
+			titled++
+		}
+
+		job := JobPosting{
+			Title: title,
+			URL:   link,
+		}
+		if len(p.Selectors.Location) > 0 {
+			job.Location = collapseSpace(p.Selectors.Location.find(s).First().Text())
+		}
+		jobs = append(jobs, job)
+	})
+
+	if len(jobs) > 0 && titled == 0 {
+		return Page{}, &SelectorError{Field: "link", Selectors: p.Selectors.Link}
+	}
+
+	page := Page{Jobs: jobs}
+	if len(p.Selectors.Pagination) > 0 {
+		page.TotalPages = pageCount(p.Selectors.Pagination.find(doc.Selection))
+	}
+
+	next := p.Selectors.Next
+	if len(next) == 0 {
+		next = relNext
+	}
+	if href, ok := next.find(doc.Selection).First().Attr("href"); ok && href != "" {
+		page.Next = href
+	} else if len(p.Selectors.Next) > 0 && (len(p.Selectors.Pagination) == 0 || page.TotalPages > 0) {
+		// A missing next link only means something when the pagination
+		// itself was found; otherwise the selectors may just be stale.
+		page.Last = true
+	}
+	return page, nil
+}
+
+// HTMLSource walks a paginated HTML job list.
+type HTMLSource struct {
+	// Company is recorded on every posting.
+	Company string
+	// BaseURL is the list URL. A "{page}" placeholder is replaced with the
+	// page number; without one the page number is appended at the end.
+	BaseURL string
+	// PageSize is the number of items on a full page. When the pagination
+	// doesn't reveal whether there's another page, one with fewer items is
+	// assumed to be the last.
+	PageSize int
+	// MaxConsecutiveFailures stops pagination after this many pages in a
+	// row fail to load.
+	MaxConsecutiveFailures int
+	// Concurrency bounds how many list pages are fetched at once when the
+	// first page reveals the page count. Delay is the minimum time between
+	// starting two list page requests.
+	Concurrency int
+	Delay       time.Duration
+
+	Fetcher Fetcher
+	Parser  Parser
+	// Cache, if set, holds the parsed pages so pages the server reports as
+	// unchanged aren't parsed again.
+	Cache *Cache
+
+	// Detail, if set, is used to read each job's own page, fetching up to
+	// DetailWorkers pages at a time.
+	Detail        DetailParser
+	DetailWorkers int
+}
+
+func init() {
+	Register("html", newHTMLSource)
+}
+
+func newHTMLSource(cfg SourceConfig, env Env) (Source, error) {
+	if cfg.URL == "" {
+		return nil, errors.New("url must be set")
+	}
+	if len(cfg.Selectors.Item) == 0 || len(cfg.Selectors.Link) == 0 {
+		return nil, errors.New("selectors.item and selectors.link must be set")
+	}
+	pageSize := cfg.PageSize
+	if pageSize == 0 {
+		pageSize = 10
+	}
+	concurrency := cfg.Concurrency
+	if concurrency == 0 {
+		concurrency = 4
+	}
+	delay := cfg.Delay
+	if delay == 0 {
+		delay = 500 * time.Millisecond
+	}
+	src := &HTMLSource{
+		Company:                cfg.Name,
+		BaseURL:                cfg.URL,
+		PageSize:               pageSize,
+		MaxConsecutiveFailures: 3,
+		Concurrency:            concurrency,
+		Delay:                  delay,
+		Fetcher:                env.Fetcher,
+		Parser:                 HTMLParser{Selectors: cfg.Selectors},
+		Cache:                  env.Cache,
+		DetailWorkers:          env.DetailWorkers,
+		Detail:                 cfg.Detail.detailParser(),
+	}
+	return src, nil
+}
+
+// Name implements Source.
+func (s *HTMLSource) Name() string {
+	return s.Company
+}
+
+// Scrape fetches pages until it runs out of listings and returns every job
+// found. A page that can't be loaded is skipped; the jobs from the other
+// pages are still returned, along with an error describing the failures.
+// If the first page has no listings at all, that is reported as a
+// *SelectorError since it almost always means the selectors are stale.
+//
+// When the first page shows how many pages there are, the rest are fetched
+// concurrently; otherwise pages are walked one at a time. Once ctx is done
+// no more pages are requested.
+func (s *HTMLSource) Scrape(ctx context.Context) ([]JobPosting, error) {
+	var allJobs []JobPosting
+	var errs []error
+	pace := newPacer(s.Delay)
+
+	page := 1
+	failures := 0
+	next := ""
+	for {
+		url := next
+		if url == "" {
+			url = s.pageURL(page)
+		}
+		next = ""
+
+		p, err := s.scrapePage(ctx, page, url, pace)
+		if err != nil {
+			slog.Warn("skipping page", "source", s.Company, "page", page, "url", url, "err", err)
+			errs = append(errs, fmt.Errorf("page %d: %w", page, err))
+			if ctx.Err() != nil {
+				break
+			}
+			failures++
+			if failures >= s.MaxConsecutiveFailures {
+				slog.Warn("too many failed pages in a row; ending pagination", "source", s.Company, "failures", failures)
+				break
+			}
+			page++
+			continue
+		}
+		failures = 0
+		jobs := p.Jobs
+
+		if len(jobs) == 0 {
+			if page == 1 {
+				err := &SelectorError{URL: url, Field: "item", Selectors: s.selectors().Item}
+				slog.Warn("no job listings on the first page", "source", s.Company, "url", url, "err", err)
+				errs = append(errs, err)
+				break
+			}
+			slog.Info("no job listings on page; ending pagination", "source", s.Company, "page", page)
+			break
+		}
+		allJobs = append(allJobs, jobs...)
+
+		if page == 1 && p.TotalPages > 1 && s.Concurrency > 1 {
+			slog.Info("fetching remaining pages concurrently", "source", s.Company, "pages", p.TotalPages, "concurrency", s.Concurrency)
+			rest, err := s.scrapePages(ctx, 2, p.TotalPages, pace)
+			allJobs = append(allJobs, rest...)
+			if err != nil {
+				errs = append(errs, err)
+			}
+			break
+		}
+
+		if s.lastPage(page, p) {
+			break
+		}
+		if p.Next != url {
+			next = p.Next
+		}
+		page++
+	}
+
+	if s.Detail != nil && len(allJobs) > 0 && ctx.Err() == nil {
+		start := time.Now()
+		err := enrich(ctx, allJobs, s.Fetcher, s.Detail, s.DetailWorkers)
+		slog.Info("fetched detail pages", "source", s.Company, "count", len(allJobs), "duration", time.Since(start))
+		if err != nil {
+			slog.Warn("some detail pages failed", "source", s.Company, "err", err)
+			errs = append(errs, err)
+		}
+	}
+	return allJobs, errors.Join(errs...)
+}
+
+// lastPage reports whether page, parsed as p, ends the list. The pagination
+// controls are trusted when present; the page-size heuristic is only a
+// fallback for pages without them.
+func (s *HTMLSource) lastPage(page int, p Page) bool {
+	switch {
+	case p.Next != "":
+		return false
+	case p.Last, p.TotalPages > 0 && page >= p.TotalPages:
+		slog.Debug("reached the last page", "source", s.Company, "page", page)
+		return true
+	case p.TotalPages == 0 && len(p.Jobs) < s.PageSize:
+		// If fewer than a full page of job items are found, assume it's the last page.
+		slog.Debug("short page; assuming it is the last", "source", s.Company, "page", page, "page_size", s.PageSize)
+		return true
+	}
+	return false
+}
+
+// selectors returns the list selectors if the parser is an HTMLParser.
+func (s *HTMLSource) selectors() Selectors {
+	if p, ok := s.Parser.(HTMLParser); ok {
+		return p.Selectors
+	}
+	return Selectors{}
+}
+
+func (s *HTMLSource) pageURL(page int) string {
+	n := strconv.Itoa(page)
+	if strings.Contains(s.BaseURL, "{page}") {
+		return strings.ReplaceAll(s.BaseURL, "{page}", n)
+	}
+	return s.BaseURL + n
+}
+
+func (s *HTMLSource) scrapePage(ctx context.Context, n int, pageURL string, pace *pacer) (Page, error) {
+	if err := pace.wait(ctx); err != nil {
+		return Page{}, err
+	}
+	start := time.Now()
+	body, err := s.Fetcher.Fetch(ctx, pageURL)
+	if err != nil {
+		return Page{}, fmt.Errorf("fetching: %w", err)
+	}
+	defer body.Close()
+
+	_, span := trace.Start(ctx, "parse", attribute.String("url.full", pageURL))
+	page, err := s.parse(pageURL, body)
+	span.SetAttributes(attribute.Int("jobs_found", len(page.Jobs)))
+	trace.SetError(span, err)
+	span.End()
+	var selErr *SelectorError
+	if errors.As(err, &selErr) {
+		selErr.URL = pageURL
+		return Page{}, err
+	}
+	if err != nil {
+		return Page{}, fmt.Errorf("parsing HTML: %w", err)
+	}
+
+	base, _ := url.Parse(pageURL)
+	for i := range page.Jobs {
+		page.Jobs[i].Company = s.Company
+		page.Jobs[i].URL = resolveURL(base, page.Jobs[i].URL)
+	}
+	page.Next = resolveURL(base, page.Next)
+	slog.Info("fetched page", "source", s.Company, "page", n, "url", pageURL,
+		"duration", time.Since(start), "jobs_found", len(page.Jobs))
+	return page, nil
+}
+
+// parse parses body, the page at pageURL, or reuses the previous result when
+// the page came unchanged from the cache.
+func (s *HTMLSource) parse(pageURL string, body io.Reader) (Page, error) {
+	if s.Cache == nil {
+		return parsePage(s.Parser, body)
+	}
+	// The parser is part of the key so that editing the selectors takes
+	// effect even for unchanged pages.
+	key := fmt.Sprintf("%s %#v", pageURL, s.Parser)
+	if NotModified(body) {
+		if page, ok := s.Cache.page(key); ok {
+			slog.Debug("page not modified; reusing parsed jobs", "source", s.Company, "url", pageURL)
+			return page, nil
+		}
+	}
+	page, err := parsePage(s.Parser, body)
+	if err != nil {
+		return Page{}, err
+	}
+	if err := s.Cache.setPage(key, page); err != nil {
+		slog.Warn("caching parsed page", "source", s.Company, "url", pageURL, "err", err)
+	}
+	return page, nil
+}
+
+// resolveURL makes a possibly relative link absolute.
+func resolveURL(base *url.URL, link string) string {
+	if base == nil || link == "" {
+		return link
+	}
+	u, err := base.Parse(link)
+	if err != nil {
+		return link
+	}
+	return u.String()
+}
@@ -0,0 +1,59 @@
+package scraper
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// trackingParams are query parameters that say how a link was found rather
+// than which job it is. Parameters starting with "utm_" are dropped too.
+var trackingParams = map[string]bool{
+	"gclid": true, "fbclid": true, "msclkid": true, "mc_cid": true, "mc_eid": true,
+	"_hsenc": true, "_hsmi": true, "ref": true, "referrer": true, "source": true,
+	"src": true, "trk": true, "gh_src": true, "lever-source": true, "lever-origin": true,
+}
+
+// trailingID matches the job number at the end of a URL path segment, as in
+// "4567890" or "senior-software-engineer-4567890".
+var trailingID = regexp.MustCompile(`(?:^|[-_])(\d{3,})$`)
+
+// CanonicalURL returns link without tracking parameters or fragment, with a
+// lower-case host and the remaining parameters sorted, so that links to the
+// same posting compare equal. A link that doesn't parse is returned as is.
+func CanonicalURL(link string) string {
+	u, err := url.Parse(link)
+	if err != nil || u.Host == "" {
+		return link
+	}
+	u.Host = strings.ToLower(u.Host)
+	u.Fragment = ""
+	u.RawFragment = ""
+	q := u.Query()
+	for name := range q {
+		if trackingParams[strings.ToLower(name)] || strings.HasPrefix(strings.ToLower(name), "utm_") {
+			q.Del(name)
+		}
+	}
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+// JobID returns the numeric job ID in a posting URL, or "" if it has none:
+// Greenhouse's gh_jid parameter, or else the number ending the last path
+// segment, as in careers.airbnb.com/positions/4567890/.
+func JobID(link string) string {
+	u, err := url.Parse(link)
+	if err != nil {
+		return ""
+	}
+	if id := u.Query().Get("gh_jid"); id != "" && strings.Trim(id, "0123456789") == "" {
+		return id
+	}
+	path := strings.TrimSuffix(u.Path, "/")
+	last := path[strings.LastIndex(path, "/")+1:]
+	if m := trailingID.FindStringSubmatch(last); m != nil {
+		return m[1]
+	}
+	return ""
+}
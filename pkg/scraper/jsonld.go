@@ -0,0 +1,219 @@
+package scraper
+
+import (
+	"bytes"
+	"encoding/json"
+	"html"
+	"io"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// JSONLDDetailParser reads the schema.org JobPosting that many job pages,
+// Greenhouse-hosted ones among them, embed as JSON-LD for search engines.
+// Fields the structured data gives win over what Fallback, if set, reads
+// with selectors; pages without it are left to Fallback entirely.
+type JSONLDDetailParser struct {
+	Fallback DetailParser
+}
+
+// ParseDetail implements DetailParser.
+func (p JSONLDDetailParser) ParseDetail(r io.Reader, job *JobPosting) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	if p.Fallback != nil {
+		if err := p.Fallback.ParseDetail(bytes.NewReader(data), job); err != nil {
+			return err
+		}
+	}
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	if posting, ok := findJSONLDPosting(doc); ok {
+		posting.apply(job)
+	}
+	return nil
+}
+
+// jsonldPosting holds the schema.org JobPosting properties used. Those that
+// may be a single value or a list, or text or an object, are left raw.
+type jsonldPosting struct {
+	Type            json.RawMessage `json:"@type"`
+	Title           string          `json:"title"`
+	Description     string          `json:"description"`
+	DatePosted      string          `json:"datePosted"`
+	JobLocation     json.RawMessage `json:"jobLocation"`
+	JobLocationType string          `json:"jobLocationType"`
+	BaseSalary      *struct {
+		Value struct {
+			Value    float64 `json:"value"`
+			MinValue float64 `json:"minValue"`
+			MaxValue float64 `json:"maxValue"`
+			// UnitText is HOUR, DAY, WEEK, MONTH or YEAR.
+			UnitText string `json:"unitText"`
+		} `json:"value"`
+		Currency string `json:"currency"`
+	} `json:"baseSalary"`
+	Identifier json.RawMessage `json:"identifier"`
+}
+
+type jsonldPlace struct {
+	Address json.RawMessage `json:"address"`
+}
+
+type jsonldAddress struct {
+	Locality string          `json:"addressLocality"`
+	Region   string          `json:"addressRegion"`
+	Country  json.RawMessage `json:"addressCountry"`
+}
+
+// findJSONLDPosting returns the first JobPosting in the page's JSON-LD
+// scripts, looking inside lists and @graph containers.
+func findJSONLDPosting(doc *goquery.Document) (jsonldPosting, bool) {
+	var found jsonldPosting
+	ok := false
+	doc.Find(`script[type="application/ld+json"]`).EachWithBreak(func(i int, s *goquery.Selection) bool {
+		found, ok = jsonldSearch(json.RawMessage(s.Text()))
+		return !ok
+	})
+	return found, ok
+}
+
+func jsonldSearch(raw json.RawMessage) (jsonldPosting, bool) {
+	var list []json.RawMessage
+	if json.Unmarshal(raw, &list) != nil {
+		var graph struct {
+			Graph []json.RawMessage `json:"@graph"`
+		}
+		json.Unmarshal(raw, &graph)
+		list = graph.Graph
+	}
+	if len(list) > 0 {
+		for _, item := range list {
+			if p, ok := jsonldSearch(item); ok {
+				return p, true
+			}
+		}
+		return jsonldPosting{}, false
+	}
+	var p jsonldPosting
+	if json.Unmarshal(raw, &p) != nil {
+		return jsonldPosting{}, false
+	}
+	for _, t := range textOrList(p.Type) {
+		if t == "JobPosting" {
+			return p, true
+		}
+	}
+	return jsonldPosting{}, false
+}
+
+// apply copies the fields the posting has onto job.
+func (p jsonldPosting) apply(job *JobPosting) {
+	if title := collapseSpace(html.UnescapeString(p.Title)); title != "" {
+		job.Title = title
+	}
+	// Descriptions are HTML, sometimes escaped once more.
+	if text := htmlToText(html.UnescapeString(p.Description)); text != "" {
+		job.Description = text
+	}
+	if t, ok := ParsePosted(p.DatePosted, time.Now()); ok {
+		job.PostedAt = &t
+	}
+	if loc := p.location(); loc != "" {
+		job.Location = loc
+	}
+	if min, max := p.salary(); max > 0 {
+		job.SalaryMin, job.SalaryMax = min, max
+	}
+	var id struct {
+		Value json.RawMessage `json:"value"`
+	}
+	if json.Unmarshal(p.Identifier, &id) == nil {
+		if v := strings.Trim(string(id.Value), `" `); v != "" && v != "null" {
+			job.Requisition = v
+		}
+	}
+}
+
+// location joins the posting's places as "City, Region, Country", with
+// "Remote" for telecommuting jobs.
+func (p jsonldPosting) location() string {
+	var places []jsonldPlace
+	if json.Unmarshal(p.JobLocation, &places) != nil {
+		var one jsonldPlace
+		if json.Unmarshal(p.JobLocation, &one) == nil {
+			places = []jsonldPlace{one}
+		}
+	}
+	var locations []string
+	if strings.EqualFold(p.JobLocationType, "TELECOMMUTE") {
+		locations = append(locations, "Remote")
+	}
+	for _, place := range places {
+		var a jsonldAddress
+		if json.Unmarshal(place.Address, &a) != nil {
+			// The address may be plain text.
+			var text string
+			if json.Unmarshal(place.Address, &text) == nil && text != "" {
+				locations = append(locations, collapseSpace(text))
+			}
+			continue
+		}
+		country := strings.Join(textOrList(a.Country), "")
+		var name struct {
+			Name string `json:"name"`
+		}
+		if json.Unmarshal(a.Country, &name) == nil {
+			country = name.Name
+		}
+		var parts []string
+		for _, part := range []string{a.Locality, a.Region, country} {
+			if part = collapseSpace(part); part != "" {
+				parts = append(parts, part)
+			}
+		}
+		if len(parts) > 0 {
+			locations = append(locations, strings.Join(parts, ", "))
+		}
+	}
+	return strings.Join(locations, " / ")
+}
+
+// salary returns the posting's yearly pay range in dollars, or zeros if it
+// gives none. As with ParseSalary, hourly and other rates are skipped.
+func (p jsonldPosting) salary() (min, max int) {
+	s := p.BaseSalary
+	if s == nil || (s.Currency != "" && !strings.EqualFold(s.Currency, "USD")) {
+		return 0, 0
+	}
+	if unit := strings.ToUpper(s.Value.UnitText); unit != "" && unit != "YEAR" {
+		return 0, 0
+	}
+	lo, hi := s.Value.MinValue, s.Value.MaxValue
+	if lo == 0 && hi == 0 {
+		lo, hi = s.Value.Value, s.Value.Value
+	}
+	if hi < lo {
+		hi = lo
+	}
+	return int(math.Round(lo)), int(math.Round(hi))
+}
+
+// textOrList decodes a JSON-LD value that is either a string or a list of
+// strings.
+func textOrList(raw json.RawMessage) []string {
+	var one string
+	if json.Unmarshal(raw, &one) == nil {
+		return []string{one}
+	}
+	var many []string
+	json.Unmarshal(raw, &many)
+	return many
+}
@@ -0,0 +1,127 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const leverAPI = "https://api.lever.co/v0/postings/"
+
+// LeverSource lists jobs through a Lever job site's public postings API.
+type LeverSource struct {
+	Company string
+	// Site is the company's Lever name, as in jobs.lever.co/<site>.
+	Site string
+	// Departments and Offices restrict the jobs returned, matching the
+	// posting's team or department and any of its locations. Names are
+	// compared case-insensitively; empty means no restriction.
+	Departments []string
+	Offices     []string
+
+	Fetcher Fetcher
+}
+
+func init() {
+	Register("lever", newLeverSource)
+}
+
+func newLeverSource(cfg SourceConfig, env Env) (Source, error) {
+	if cfg.Board == "" {
+		return nil, errors.New("board must be set")
+	}
+	name := cfg.Name
+	if name == "" {
+		name = cfg.Board
+	}
+	return &LeverSource{
+		Company:     name,
+		Site:        cfg.Board,
+		Departments: cfg.Departments,
+		Offices:     cfg.Offices,
+		Fetcher:     env.Fetcher,
+	}, nil
+}
+
+// Name implements Source.
+func (s *LeverSource) Name() string {
+	return s.Company
+}
+
+type leverPosting struct {
+	Text       string `json:"text"`
+	HostedURL  string `json:"hostedUrl"`
+	Categories struct {
+		Location     string   `json:"location"`
+		AllLocations []string `json:"allLocations"`
+		Team         string   `json:"team"`
+		Department   string   `json:"department"`
+	} `json:"categories"`
+	DescriptionPlain string `json:"descriptionPlain"`
+	// Lists are the requirements and responsibilities sections; their
+	// content is HTML.
+	Lists []struct {
+		Text    string `json:"text"`
+		Content string `json:"content"`
+	} `json:"lists"`
+	AdditionalPlain string `json:"additionalPlain"`
+	// CreatedAt is when the posting was created, in milliseconds since the
+	// epoch.
+	CreatedAt int64 `json:"createdAt"`
+}
+
+// Scrape implements Source.
+func (s *LeverSource) Scrape(ctx context.Context) ([]JobPosting, error) {
+	apiURL := leverAPI + url.PathEscape(s.Site) + "?mode=json"
+	start := time.Now()
+	var postings []leverPosting
+	if err := fetchJSON(ctx, s.Fetcher, apiURL, &postings); err != nil {
+		return nil, err
+	}
+
+	var jobs []JobPosting
+	for _, p := range postings {
+		c := p.Categories
+		locations := c.AllLocations
+		if len(locations) == 0 && c.Location != "" {
+			locations = []string{c.Location}
+		}
+		if !matchesAny(s.Departments, []string{c.Team, c.Department}) || !matchesAny(s.Offices, locations) {
+			continue
+		}
+
+		team := c.Team
+		if team == "" {
+			team = c.Department
+		}
+		job := JobPosting{
+			Company:     s.Company,
+			Title:       strings.TrimSpace(p.Text),
+			URL:         p.HostedURL,
+			Location:    strings.Join(locations, " / "),
+			Team:        team,
+			Description: p.description(),
+		}
+		if p.CreatedAt > 0 {
+			t := time.UnixMilli(p.CreatedAt).UTC()
+			job.PostedAt = &t
+		}
+		jobs = append(jobs, job)
+	}
+	slog.Info("fetched Lever postings", "source", s.Company, "url", apiURL,
+		"duration", time.Since(start), "listed", len(postings), "jobs_found", len(jobs))
+	return jobs, nil
+}
+
+// description joins the posting's sections into one text.
+func (p leverPosting) description() string {
+	parts := []string{p.DescriptionPlain}
+	for _, l := range p.Lists {
+		parts = append(parts, l.Text+"\n"+htmlToText(l.Content))
+	}
+	parts = append(parts, p.AdditionalPlain)
+	return cleanText(strings.Join(parts, "\n\n"))
+}
@@ -0,0 +1,54 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+)
+
+// MergedSource scrapes several sources for one company, such as the same
+// careers site filtered in different ways, and returns each job once.
+type MergedSource struct {
+	Company string
+	Sources []Source
+}
+
+// Name implements Source.
+func (s *MergedSource) Name() string {
+	return s.Company
+}
+
+// Scrape implements Source. A part with no listings is only reported as a
+// *SelectorError when every part came back empty, since one filter
+// combination can legitimately have no jobs.
+func (s *MergedSource) Scrape(ctx context.Context) ([]JobPosting, error) {
+	var jobs []JobPosting
+	var errs, empty []error
+	seen := map[string]bool{}
+	for _, src := range s.Sources {
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, err)
+			break
+		}
+		found, err := src.Scrape(ctx)
+		var selErr *SelectorError
+		if errors.As(err, &selErr) && len(found) == 0 {
+			empty = append(empty, err)
+		} else if err != nil {
+			errs = append(errs, err)
+		}
+		for _, job := range found {
+			if seen[job.Key()] {
+				continue
+			}
+			seen[job.Key()] = true
+			jobs = append(jobs, job)
+		}
+	}
+	if len(jobs) == 0 {
+		errs = append(errs, empty...)
+	} else if len(empty) > 0 {
+		slog.Debug("some listings were empty", "source", s.Company, "empty", len(empty), "of", len(s.Sources))
+	}
+	return jobs, errors.Join(errs...)
+}
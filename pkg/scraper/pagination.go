@@ -0,0 +1,149 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Page is one parsed list page.
+type Page struct {
+	Jobs []JobPosting
+	// TotalPages is the page count read from the pagination element, or 0
+	// if the page doesn't show one.
+	TotalPages int
+	// Next is the URL of the following page when the page links to it.
+	Next string
+	// Last is set when the pagination shows there is no following page.
+	// With neither Next nor Last set, the parser couldn't tell.
+	Last bool
+}
+
+// relNext matches standard rel=next links, used when no next selector is
+// configured.
+var relNext = SelectorList{"a[rel~='next']", "link[rel~='next']"}
+
+// PageParser is a Parser that also reads the pagination controls.
+type PageParser interface {
+	ParsePage(r io.Reader) (Page, error)
+}
+
+// parsePage uses p's ParsePage when it has one.
+func parsePage(p Parser, r io.Reader) (Page, error) {
+	if pp, ok := p.(PageParser); ok {
+		return pp.ParsePage(r)
+	}
+	jobs, err := p.Parse(r)
+	return Page{Jobs: jobs}, err
+}
+
+// pageCount returns the largest page number among the texts of the
+// pagination links, or 0 if there is none.
+func pageCount(links *goquery.Selection) int {
+	total := 0
+	links.Each(func(i int, s *goquery.Selection) {
+		if n, err := strconv.Atoi(strings.TrimSpace(s.Text())); err == nil && n > total {
+			total = n
+		}
+	})
+	return total
+}
+
+// pacer spaces requests at least interval apart, across goroutines. With
+// jitter, each gap is randomized by up to that fraction.
+type pacer struct {
+	mu       sync.Mutex
+	interval time.Duration
+	jitter   float64
+	next     time.Time
+}
+
+func newPacer(interval time.Duration) *pacer {
+	return &pacer{interval: interval}
+}
+
+// setInterval changes the spacing for the following requests.
+func (p *pacer) setInterval(d time.Duration) {
+	p.mu.Lock()
+	p.interval = d
+	p.mu.Unlock()
+}
+
+// wait blocks until the caller may send its request, or until ctx is done.
+func (p *pacer) wait(ctx context.Context) error {
+	p.mu.Lock()
+	if p.interval <= 0 {
+		p.mu.Unlock()
+		return ctx.Err()
+	}
+	now := time.Now()
+	start := p.next
+	if start.Before(now) {
+		start = now
+	}
+	gap := p.interval
+	if p.jitter > 0 {
+		gap += time.Duration((rand.Float64()*2 - 1) * p.jitter * float64(gap))
+	}
+	p.next = start.Add(gap)
+	p.mu.Unlock()
+	return sleepContext(ctx, start.Sub(now))
+}
+
+// scrapePages fetches pages first through last with at most s.Concurrency
+// requests in flight and returns their jobs in page order. Pages that fail
+// are skipped and reported together.
+func (s *HTMLSource) scrapePages(ctx context.Context, first, last int, pace *pacer) ([]JobPosting, error) {
+	pages := make([][]JobPosting, last-first+1)
+	var (
+		mu   sync.Mutex
+		errs []error
+		wg   sync.WaitGroup
+	)
+	numbers := make(chan int)
+	for range s.Concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for page := range numbers {
+				url := s.pageURL(page)
+				p, err := s.scrapePage(ctx, page, url, pace)
+				if err != nil {
+					slog.Warn("skipping page", "source", s.Company, "page", page, "url", url, "err", err)
+					mu.Lock()
+					errs = append(errs, fmt.Errorf("page %d: %w", page, err))
+					mu.Unlock()
+					continue
+				}
+				pages[page-first] = p.Jobs
+			}
+		}()
+	}
+
+	for page := first; page <= last && ctx.Err() == nil; page++ {
+		select {
+		case numbers <- page:
+		case <-ctx.Done():
+		}
+	}
+	close(numbers)
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		errs = append(errs, err)
+	}
+
+	var jobs []JobPosting
+	for _, p := range pages {
+		jobs = append(jobs, p...)
+	}
+	return jobs, errors.Join(errs...)
+}
@@ -0,0 +1,164 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// robotsAgent is the product token looked up in robots.txt files.
+const robotsAgent = "jobwatch"
+
+// robotsTTL is how long a host's robots.txt is trusted before it is
+// fetched again, for long-running processes.
+const robotsTTL = 24 * time.Hour
+
+// robotsRetryTTL is how long a robots.txt that couldn't be read stands in
+// before it is tried again.
+const robotsRetryTTL = 10 * time.Minute
+
+// Politeness configures how gently hosts are crawled.
+type Politeness struct {
+	// Robots makes every request honor the host's robots.txt: disallowed
+	// URLs aren't fetched and a Crawl-delay raises Delay for that host.
+	Robots bool `yaml:"robots"`
+	// Delay is the minimum time between two requests to the same host.
+	Delay time.Duration `yaml:"delay"`
+	// Jitter randomizes each delay by up to this fraction (0.2 = ±20%).
+	Jitter float64 `yaml:"jitter"`
+}
+
+// DefaultPoliteness honors robots.txt and spaces requests to a host half a
+// second apart.
+func DefaultPoliteness() Politeness {
+	return Politeness{Robots: true, Delay: 500 * time.Millisecond, Jitter: 0.2}
+}
+
+// RobotsError is returned for a URL the host's robots.txt disallows.
+type RobotsError struct {
+	URL string
+}
+
+func (e *RobotsError) Error() string {
+	return fmt.Sprintf("%s is disallowed by robots.txt", e.URL)
+}
+
+// Hosts keeps what a Politeness needs to know about each host: its
+// robots.txt and when it may be sent the next request. Share one between
+// every source that may visit the same host.
+type Hosts struct {
+	Politeness Politeness
+
+	mu    sync.Mutex
+	hosts map[string]*politeHost
+}
+
+// NewHosts returns an empty Hosts applying p.
+func NewHosts(p Politeness) *Hosts {
+	return &Hosts{Politeness: p}
+}
+
+type politeHost struct {
+	once   sync.Once
+	robots *robots
+	pace   *pacer
+	// expires is when robots.txt should be fetched again.
+	expires time.Time
+}
+
+// PoliteFetcher applies Hosts' Politeness to the wrapped Fetcher, which is
+// also used to read robots.txt files, retrying as Retry says.
+type PoliteFetcher struct {
+	Fetcher Fetcher
+	Hosts   *Hosts
+	Retry   RetryPolicy
+}
+
+// Fetch implements Fetcher.
+func (f *PoliteFetcher) Fetch(ctx context.Context, rawURL string) (io.ReadCloser, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	h := f.host(ctx, u)
+	if h.robots != nil && !h.robots.allowed(u.RequestURI()) {
+		return nil, &RobotsError{URL: rawURL}
+	}
+	if err := h.pace.wait(ctx); err != nil {
+		return nil, err
+	}
+	return f.Fetcher.Fetch(ctx, rawURL)
+}
+
+// host returns the state of u's host, fetching its robots.txt first if
+// needed.
+func (f *PoliteFetcher) host(ctx context.Context, u *url.URL) *politeHost {
+	p := f.Hosts.Politeness
+	key := u.Scheme + "://" + u.Host
+	f.Hosts.mu.Lock()
+	if f.Hosts.hosts == nil {
+		f.Hosts.hosts = map[string]*politeHost{}
+	}
+	h := f.Hosts.hosts[key]
+	if h == nil || time.Now().After(h.expires) {
+		h = &politeHost{pace: newPacer(p.Delay), expires: time.Now().Add(robotsTTL)}
+		h.pace.jitter = p.Jitter
+		f.Hosts.hosts[key] = h
+	}
+	f.Hosts.mu.Unlock()
+
+	if p.Robots {
+		h.once.Do(func() {
+			var ttl time.Duration
+			h.robots, ttl = f.loadRobots(ctx, key, h.pace)
+			if ttl < robotsTTL {
+				f.Hosts.mu.Lock()
+				h.expires = time.Now().Add(ttl)
+				f.Hosts.mu.Unlock()
+			}
+			if h.robots.crawlDelay > p.Delay {
+				slog.Info("honoring robots.txt crawl-delay", "host", u.Host, "delay", h.robots.crawlDelay)
+				h.pace.setInterval(h.robots.crawlDelay)
+			}
+		})
+	}
+	return h
+}
+
+// loadRobots fetches and parses origin's robots.txt, and returns how long
+// to go by it. A missing file allows everything. As RFC 9309 asks, a
+// server error disallows everything, but only until it is tried again; so
+// that an unreliable network doesn't stop a crawl, a file that can't be
+// fetched at all allows everything for that long.
+func (f *PoliteFetcher) loadRobots(ctx context.Context, origin string, pace *pacer) (*robots, time.Duration) {
+	if pace.wait(ctx) != nil {
+		return disallowAll, 0
+	}
+	fetcher := f.Fetcher
+	if f.Retry.Attempts > 1 {
+		fetcher = &RetryFetcher{Fetcher: fetcher, Policy: f.Retry}
+	}
+	body, err := fetcher.Fetch(ctx, origin+"/robots.txt")
+	if err != nil {
+		if ctx.Err() != nil {
+			return disallowAll, 0
+		}
+		var statusErr *StatusError
+		if !errors.As(err, &statusErr) {
+			slog.Warn("robots.txt unreachable; crawling the host regardless", "host", origin, "err", err)
+			return allowAll, robotsRetryTTL
+		}
+		if statusErr.StatusCode >= 400 && statusErr.StatusCode < 500 {
+			return allowAll, robotsTTL
+		}
+		slog.Warn("robots.txt unavailable; not crawling the host", "host", origin, "err", err)
+		return disallowAll, robotsRetryTTL
+	}
+	defer body.Close()
+	return parseRobots(io.LimitReader(body, 500<<10), robotsAgent), robotsTTL
+}
@@ -0,0 +1,47 @@
+package scraper
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// postKey is the context key of a request body; see withPost.
+type postKey struct{}
+
+type postBody struct {
+	contentType string
+	body        []byte
+}
+
+// withPost makes the fetches made with the returned context POST body
+// instead of performing a GET, for the few APIs that list jobs that way.
+// Fetchers that merely wrap another pass the context on unchanged, so the
+// request still gets its retries, pacing and archiving; HTTPFetcher sends
+// it and skips its cache.
+func withPost(ctx context.Context, contentType string, body []byte) context.Context {
+	return context.WithValue(ctx, postKey{}, postBody{contentType: contentType, body: body})
+}
+
+func postFrom(ctx context.Context) (postBody, bool) {
+	p, ok := ctx.Value(postKey{}).(postBody)
+	return p, ok
+}
+
+// requestKey identifies the request for url made with ctx in an archive:
+// the URL, followed by the body of a POST.
+func requestKey(ctx context.Context, url string) string {
+	if p, ok := postFrom(ctx); ok {
+		return url + " " + string(p.body)
+	}
+	return url
+}
+
+// postJSON posts req as JSON to url and decodes the JSON response into v.
+func postJSON(ctx context.Context, f Fetcher, url string, req, v any) error {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("encoding: %w", err)
+	}
+	return fetchJSON(withPost(ctx, "application/json", body), f, url, v)
+}
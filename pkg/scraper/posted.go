@@ -0,0 +1,109 @@
+package scraper
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// postedAgo matches a relative posting date, as in "Posted 3 days ago" or
+// "30+ days ago".
+var postedAgo = regexp.MustCompile(`(?i)(\d+)\+?\s*(minute|hour|day|week|month|year)s?\s+ago`)
+
+// isoDate finds an ISO 8601 date within other text.
+var isoDate = regexp.MustCompile(`\d{4}-\d{2}-\d{2}`)
+
+// postedLayouts are the absolute date formats careers pages use, tried in
+// order after RFC 3339.
+var postedLayouts = []string{
+	"2006-01-02T15:04:05",
+	time.DateOnly,
+	"January 2, 2006",
+	"Jan 2, 2006",
+	"Jan. 2, 2006",
+	"2 January 2006",
+	"2 Jan 2006",
+	"01/02/2006",
+}
+
+// ParsePosted reads a posting date as careers pages show it: an ISO 8601
+// date or time, a written-out date such as "March 3, 2025", or a relative
+// one such as "Posted 3 days ago" or "today", counted back from now. A
+// label before the date, like "Posted on", is ignored.
+func ParsePosted(s string, now time.Time) (time.Time, bool) {
+	s = collapseSpace(s)
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, true
+	}
+	lower := strings.ToLower(s)
+	switch {
+	case strings.Contains(lower, "just posted"), strings.Contains(lower, "today"):
+		return now, true
+	case strings.Contains(lower, "yesterday"):
+		return now.AddDate(0, 0, -1), true
+	}
+	if m := postedAgo.FindStringSubmatch(s); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		switch strings.ToLower(m[2]) {
+		case "minute":
+			return now.Add(-time.Duration(n) * time.Minute), true
+		case "hour":
+			return now.Add(-time.Duration(n) * time.Hour), true
+		case "day":
+			return now.AddDate(0, 0, -n), true
+		case "week":
+			return now.AddDate(0, 0, -7*n), true
+		case "month":
+			return now.AddDate(0, -n, 0), true
+		default:
+			return now.AddDate(-n, 0, 0), true
+		}
+	}
+
+	// Try the text after each label in turn: "Posted on: March 3, 2025".
+	candidates := []string{s}
+	for _, label := range []string{":", "posted on ", "posted ", "published "} {
+		if i := strings.LastIndex(lower, label); i >= 0 {
+			candidates = append(candidates, strings.TrimSpace(s[i+len(label):]))
+		}
+	}
+	for _, c := range candidates {
+		for _, layout := range postedLayouts {
+			if t, err := time.Parse(layout, c); err == nil {
+				return t, true
+			}
+		}
+	}
+	if d := isoDate.FindString(s); d != "" {
+		if t, err := time.Parse(time.DateOnly, d); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// Age says how long ago the job was posted, e.g. "posted 3 days ago", or
+// returns "" if the posting date isn't known.
+func (j JobPosting) Age() string {
+	if j.PostedAt == nil {
+		return ""
+	}
+	return postedAge(*j.PostedAt, time.Now())
+}
+
+func postedAge(posted, now time.Time) string {
+	days := int(now.Sub(posted).Hours() / 24)
+	switch {
+	case days <= 0:
+		return "posted today"
+	case days == 1:
+		return "posted yesterday"
+	case days < 14:
+		return "posted " + strconv.Itoa(days) + " days ago"
+	case days < 60:
+		return "posted " + strconv.Itoa(days/7) + " weeks ago"
+	default:
+		return "posted " + strconv.Itoa(days/30) + " months ago"
+	}
+}
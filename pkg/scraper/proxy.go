@@ -0,0 +1,37 @@
+package scraper
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// proxyClient returns an HTTP client sending requests through proxy: an
+// http, https, socks5 or socks5h URL, or "direct" to ignore the
+// HTTP_PROXY, HTTPS_PROXY and NO_PROXY variables. For an empty proxy it
+// returns nil, meaning http.DefaultClient, which honors those variables.
+func proxyClient(proxy string) (*http.Client, error) {
+	if proxy == "" {
+		return nil, nil
+	}
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	if proxy == "direct" {
+		t.Proxy = nil
+		return &http.Client{Transport: t}, nil
+	}
+
+	u, err := url.Parse(proxy)
+	if err != nil {
+		return nil, fmt.Errorf("proxy: %w", err)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("proxy: unsupported scheme %q (want http, https, socks5 or socks5h)", u.Scheme)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("proxy: %q has no host", proxy)
+	}
+	t.Proxy = http.ProxyURL(u)
+	return &http.Client{Transport: t}, nil
+}
@@ -0,0 +1,127 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// recruiteePublished is the layout of the careers API's dates.
+const recruiteePublished = "2006-01-02 15:04:05 MST"
+
+// RecruiteeSource lists jobs through a Recruitee careers site's public
+// API.
+type RecruiteeSource struct {
+	Company string
+	// Board is the company's Recruitee name, as in <board>.recruitee.com.
+	Board string
+	// Departments and Offices restrict the jobs returned, matching the
+	// offer's department and any of its cities or countries. Names are
+	// compared case-insensitively; empty means no restriction.
+	Departments []string
+	Offices     []string
+
+	Fetcher Fetcher
+}
+
+func init() {
+	Register("recruitee", newRecruiteeSource)
+}
+
+func newRecruiteeSource(cfg SourceConfig, env Env) (Source, error) {
+	if cfg.Board == "" {
+		return nil, errors.New("board must be set")
+	}
+	name := cfg.Name
+	if name == "" {
+		name = cfg.Board
+	}
+	return &RecruiteeSource{
+		Company:     name,
+		Board:       cfg.Board,
+		Departments: cfg.Departments,
+		Offices:     cfg.Offices,
+		Fetcher:     env.Fetcher,
+	}, nil
+}
+
+// recruiteeSite returns the careers site of board.
+func recruiteeSite(board string) string {
+	return "https://" + url.PathEscape(board) + ".recruitee.com/"
+}
+
+// Name implements Source.
+func (s *RecruiteeSource) Name() string {
+	return s.Company
+}
+
+type recruiteeOffers struct {
+	Offers []struct {
+		Title      string `json:"title"`
+		CareersURL string `json:"careers_url"`
+		Location   string `json:"location"`
+		City       string `json:"city"`
+		Country    string `json:"country"`
+		Remote     bool   `json:"remote"`
+		Locations  []struct {
+			City    string `json:"city"`
+			Country string `json:"country"`
+		} `json:"locations"`
+		Department string `json:"department"`
+		// Description and Requirements are HTML.
+		Description  string `json:"description"`
+		Requirements string `json:"requirements"`
+		PublishedAt  string `json:"published_at"`
+		Status       string `json:"status"`
+	} `json:"offers"`
+}
+
+// Scrape implements Source. Only published offers are kept.
+func (s *RecruiteeSource) Scrape(ctx context.Context) ([]JobPosting, error) {
+	apiURL := recruiteeSite(s.Board) + "api/offers/"
+	start := time.Now()
+	var resp recruiteeOffers
+	if err := fetchJSON(ctx, s.Fetcher, apiURL, &resp); err != nil {
+		return nil, err
+	}
+
+	var jobs []JobPosting
+	for _, o := range resp.Offers {
+		if o.Status != "" && o.Status != "published" {
+			continue
+		}
+		places := []string{o.Location, o.City, o.Country}
+		for _, l := range o.Locations {
+			places = append(places, l.City, l.Country)
+		}
+		if o.Remote {
+			places = append(places, "Remote")
+		}
+		if !matchesAny(s.Departments, []string{o.Department}) || !matchesAny(s.Offices, places) {
+			continue
+		}
+
+		location := o.Location
+		if o.Remote && !strings.Contains(strings.ToLower(location), "remote") {
+			location = strings.TrimSuffix("Remote / "+location, " / ")
+		}
+		job := JobPosting{
+			Company:     s.Company,
+			Title:       strings.TrimSpace(o.Title),
+			URL:         o.CareersURL,
+			Location:    location,
+			Team:        o.Department,
+			Description: cleanText(htmlToText(o.Description) + "\n\n" + htmlToText(o.Requirements)),
+		}
+		if t, err := time.Parse(recruiteePublished, o.PublishedAt); err == nil {
+			job.PostedAt = &t
+		}
+		jobs = append(jobs, job)
+	}
+	slog.Info("fetched Recruitee offers", "source", s.Company, "url", apiURL,
+		"duration", time.Since(start), "listed", len(resp.Offers), "jobs_found", len(jobs))
+	return jobs, nil
+}
@@ -0,0 +1,150 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// StatusError is returned by HTTPFetcher for non-200 responses.
+type StatusError struct {
+	StatusCode int
+	// RetryAfter is the delay requested by the server's Retry-After header,
+	// or zero if it didn't send one.
+	RetryAfter time.Duration
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("non-200 HTTP status: %d", e.StatusCode)
+}
+
+// Temporary reports whether the request is worth retrying.
+func (e *StatusError) Temporary() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
+
+// parseRetryAfter understands both forms of the Retry-After header: a number
+// of seconds or an HTTP date.
+func parseRetryAfter(v string, now time.Time) time.Duration {
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+	return 0
+}
+
+// RetryPolicy controls how failed fetches are retried.
+type RetryPolicy struct {
+	// Attempts is the total number of tries, including the first one.
+	Attempts int `yaml:"attempts"`
+	// InitialBackoff is the delay before the first retry; it doubles after
+	// every failed attempt up to MaxBackoff.
+	InitialBackoff time.Duration `yaml:"initial_backoff"`
+	MaxBackoff     time.Duration `yaml:"max_backoff"`
+	// Jitter randomizes each delay by up to this fraction (0.2 = ±20%).
+	Jitter float64 `yaml:"jitter"`
+}
+
+// DefaultRetryPolicy retries a few times over roughly half a minute.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		Attempts:       4,
+		InitialBackoff: time.Second,
+		MaxBackoff:     30 * time.Second,
+		Jitter:         0.2,
+	}
+}
+
+// backoff returns the delay before retry number n (starting at 1). A
+// server-provided Retry-After is honored, capped at MaxBackoff.
+func (p RetryPolicy) backoff(n int, retryAfter time.Duration) time.Duration {
+	if retryAfter > 0 {
+		return min(retryAfter, p.MaxBackoff)
+	}
+
+	d := p.InitialBackoff << (n - 1)
+	if d <= 0 || d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+	if p.Jitter > 0 {
+		d += time.Duration((rand.Float64()*2 - 1) * p.Jitter * float64(d))
+	}
+	return d
+}
+
+// RetryFetcher retries transient failures of the wrapped Fetcher: network
+// errors, timeouts, 429s and 5xx responses. It gives up as soon as the
+// caller's context is done.
+type RetryFetcher struct {
+	Fetcher Fetcher
+	Policy  RetryPolicy
+
+	sleep func(context.Context, time.Duration) error
+}
+
+// Fetch implements Fetcher.
+func (f *RetryFetcher) Fetch(ctx context.Context, url string) (io.ReadCloser, error) {
+	sleep := f.sleep
+	if sleep == nil {
+		sleep = sleepContext
+	}
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		var body io.ReadCloser
+		body, err = f.Fetcher.Fetch(ctx, url)
+		if err == nil {
+			return body, nil
+		}
+		if ctx.Err() != nil {
+			return nil, err
+		}
+
+		var robotsErr *RobotsError
+		if errors.As(err, &robotsErr) {
+			return nil, err
+		}
+		var statusErr *StatusError
+		var retryAfter time.Duration
+		if errors.As(err, &statusErr) {
+			if !statusErr.Temporary() {
+				return nil, err
+			}
+			retryAfter = statusErr.RetryAfter
+		}
+		if attempt >= f.Policy.Attempts {
+			break
+		}
+
+		delay := f.Policy.backoff(attempt, retryAfter)
+		slog.Warn("fetch failed; retrying", "url", url, "attempt", attempt, "attempts", f.Policy.Attempts,
+			"err", err, "delay", delay.Round(time.Millisecond))
+		if err := sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+	return nil, fmt.Errorf("giving up after %d attempts: %w", f.Policy.Attempts, err)
+}
+
+// sleepContext waits for d, or returns ctx's error if ctx is done first.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,137 @@
+package scraper
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// robots holds the rules of a robots.txt file that apply to one user agent.
+type robots struct {
+	rules []robotsRule
+	// crawlDelay is the Crawl-delay the file asks for, or 0.
+	crawlDelay time.Duration
+}
+
+type robotsRule struct {
+	allow   bool
+	pattern string
+}
+
+// allowAll and disallowAll stand in for missing and unreachable files.
+var (
+	allowAll    = &robots{}
+	disallowAll = &robots{rules: []robotsRule{{allow: false, pattern: "/"}}}
+)
+
+// parseRobots reads a robots.txt file and keeps the groups for agent, or
+// the "*" groups if none names it. Agents match case-insensitively when
+// the group's user-agent is contained in agent's product token.
+func parseRobots(r io.Reader, agent string) *robots {
+	agent = strings.ToLower(agent)
+
+	var named, star robots
+	var matchNamed, matchStar, inRules bool
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			// A user-agent line after rules starts a new group.
+			if inRules {
+				matchNamed, matchStar, inRules = false, false, false
+			}
+			ua := strings.ToLower(value)
+			if ua == "*" {
+				matchStar = true
+			} else if ua != "" && strings.Contains(agent, ua) {
+				matchNamed = true
+			}
+		case "allow", "disallow":
+			inRules = true
+			if value == "" {
+				// "Disallow:" with no path allows everything.
+				continue
+			}
+			rule := robotsRule{allow: key == "allow", pattern: value}
+			if matchNamed {
+				named.rules = append(named.rules, rule)
+			}
+			if matchStar {
+				star.rules = append(star.rules, rule)
+			}
+		case "crawl-delay":
+			inRules = true
+			secs, err := strconv.ParseFloat(value, 64)
+			if err != nil || secs < 0 {
+				continue
+			}
+			d := time.Duration(secs * float64(time.Second))
+			if matchNamed {
+				named.crawlDelay = d
+			}
+			if matchStar {
+				star.crawlDelay = d
+			}
+		}
+	}
+
+	if len(named.rules) > 0 || named.crawlDelay > 0 {
+		return &named
+	}
+	return &star
+}
+
+// allowed reports whether path (with its query) may be fetched. The longest
+// matching rule wins; on a tie, allow does.
+func (r *robots) allowed(path string) bool {
+	best, allow := -1, true
+	for _, rule := range r.rules {
+		if !robotsMatch(rule.pattern, path) {
+			continue
+		}
+		n := len(rule.pattern)
+		if n > best || (n == best && rule.allow) {
+			best, allow = n, rule.allow
+		}
+	}
+	return allow
+}
+
+// robotsMatch reports whether path starts with pattern, where "*" matches
+// any run of characters and a trailing "$" anchors the end.
+func robotsMatch(pattern, path string) bool {
+	anchored := strings.HasSuffix(pattern, "$")
+	pattern = strings.TrimSuffix(pattern, "$")
+
+	parts := strings.Split(pattern, "*")
+	if !strings.HasPrefix(path, parts[0]) {
+		return false
+	}
+	rest := path[len(parts[0]):]
+	for i, part := range parts[1:] {
+		last := i == len(parts)-2
+		if last && anchored {
+			return strings.HasSuffix(rest, part)
+		}
+		j := strings.Index(rest, part)
+		if j < 0 {
+			return false
+		}
+		rest = rest[j+len(part):]
+	}
+	// Only a pattern without wildcards gets here anchored.
+	return !anchored || rest == ""
+}
@@ -0,0 +1,75 @@
+package scraper
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// salaryRange matches a pay range such as "$150,000–$190,000",
+// "$150,000 - $190,000 USD" or "$150K to $190K".
+var salaryRange = regexp.MustCompile(`(?i)\$\s?(\d{1,3}(?:,\d{3})+|\d+(?:\.\d+)?)\s?(k)?\s*(?:-|–|—|to)\s*\$?\s?(\d{1,3}(?:,\d{3})+|\d+(?:\.\d+)?)\s?(k)?`)
+
+// hourly matches what follows an hourly rate rather than a yearly salary.
+var hourly = regexp.MustCompile(`(?i)^\s*(?:usd)?\s*(?:/|per|an|a)\s*(?:hour|hr)`)
+
+// minSalary is the smallest amount taken to be a yearly salary, so that
+// other dollar ranges in a description are ignored.
+const minSalary = 10000
+
+// ParseSalary returns the first yearly pay range in a job description, in
+// dollars, or zeros if it has none. Hourly rates are skipped.
+func ParseSalary(text string) (min, max int) {
+	for _, m := range salaryRange.FindAllStringSubmatchIndex(text, -1) {
+		if hourly.MatchString(text[m[1]:]) {
+			continue
+		}
+		lo := salaryAmount(text[m[2]:m[3]], m[4] >= 0)
+		hi := salaryAmount(text[m[6]:m[7]], m[8] >= 0)
+		// "$150–190K" puts the unit on the upper bound only.
+		if m[4] < 0 && m[8] >= 0 && lo < 1000 {
+			lo *= 1000
+		}
+		if lo < minSalary || hi < lo {
+			continue
+		}
+		return lo, hi
+	}
+	return 0, 0
+}
+
+func salaryAmount(s string, thousands bool) int {
+	f, err := strconv.ParseFloat(strings.ReplaceAll(s, ",", ""), 64)
+	if err != nil {
+		return 0
+	}
+	if thousands {
+		f *= 1000
+	}
+	return int(f)
+}
+
+// Salary formats the job's pay range, e.g. "$150,000–$190,000", or returns
+// "" if it isn't known.
+func (j JobPosting) Salary() string {
+	switch {
+	case j.SalaryMax == 0:
+		return ""
+	case j.SalaryMin == j.SalaryMax:
+		return dollars(j.SalaryMin)
+	}
+	return dollars(j.SalaryMin) + "–" + dollars(j.SalaryMax)
+}
+
+// dollars formats n with thousands separators.
+func dollars(n int) string {
+	s := strconv.Itoa(n)
+	var b strings.Builder
+	for i, c := range s {
+		if i > 0 && (len(s)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(c)
+	}
+	return "$" + b.String()
+}
@@ -0,0 +1,182 @@
+// Package scraper fetches and parses job listings from careers pages.
+package scraper
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// JobPosting holds basic info for a job.
+type JobPosting struct {
+	// Company is the name of the source the posting came from.
+	Company string `json:"company"`
+	Title   string `json:"title"`
+	URL     string `json:"url"`
+	// Location is empty when the source doesn't expose it.
+	Location string `json:"location"`
+	// Team and Description come from the job's detail page when the
+	// source knows how to read it.
+	Team        string `json:"team"`
+	Description string `json:"description"`
+	// Level is the seniority name assigned by the level package, e.g.
+	// "mid", or empty if the job hasn't been classified.
+	Level string `json:"level"`
+	// Requisition is the employer's ID for the opening, which the postings
+	// of one opening in several locations share, when the source shows it.
+	Requisition string `json:"requisition,omitempty"`
+	// SalaryMin and SalaryMax are the yearly pay range in dollars, from the
+	// job page's structured data or found in the description by
+	// ParseSalary, or zero if none was given.
+	SalaryMin int `json:"salary_min,omitempty"`
+	SalaryMax int `json:"salary_max,omitempty"`
+	// PostedAt is when the employer published the posting, from the job
+	// board's API or the detail page, or nil if it isn't known.
+	PostedAt *time.Time `json:"posted_at,omitempty"`
+	// Score is how well the job fits the configured interests profile, set
+	// by the score package when the job is scraped, for the filters, and
+	// again just before a digest goes out.
+	Score int `json:"score,omitempty"`
+	// Tags are the technologies the posting mentions, found by the tags
+	// package when the job is scraped.
+	Tags []string `json:"tags,omitempty"`
+	// Fit is how well the job fits the configured resume, from 0 to 100,
+	// set by the fit package when the job is scraped, or 0 if it wasn't
+	// rated.
+	Fit int `json:"fit,omitempty"`
+	// Interest is how likely the job is to interest you, from 0 to 100, as
+	// the bayes package learned from your feedback, set just before a
+	// digest goes out.
+	Interest int `json:"interest,omitempty"`
+	// Summary is what a language model made of the description, added by
+	// the summary package just before a digest goes out, or nil.
+	Summary *Summary `json:"summary,omitempty"`
+}
+
+// Summary is a language model's take on a job posting.
+type Summary struct {
+	// Text sums the job up in two sentences.
+	Text string `json:"text"`
+	// Level is the seniority the model reads in the posting, a level
+	// package name such as "senior", or empty.
+	Level string `json:"level,omitempty"`
+	// Remote is "remote", "hybrid" or "onsite", or empty.
+	Remote string `json:"remote,omitempty"`
+}
+
+// Key identifies the posting across runs: its host and the job ID in its
+// URL, so that the link can change shape without the job looking new, or
+// the canonical URL when it has no ID.
+func (j JobPosting) Key() string {
+	id := JobID(j.URL)
+	if id == "" {
+		return CanonicalURL(j.URL)
+	}
+	host := ""
+	if u, err := url.Parse(j.URL); err == nil {
+		host = strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	}
+	return host + "#" + id
+}
+
+// Fetcher retrieves the raw contents of a page. Cancelling ctx abandons the
+// request, including reading the body.
+type Fetcher interface {
+	Fetch(ctx context.Context, url string) (io.ReadCloser, error)
+}
+
+// Parser extracts job postings from a page.
+type Parser interface {
+	Parse(r io.Reader) ([]JobPosting, error)
+}
+
+// DefaultUserAgent identifies the scraper to the sites it visits.
+const DefaultUserAgent = "jobwatch/1.0 (+https://github.com/hunterheston/airbnb)"
+
+// HTTPFetcher fetches pages over HTTP.
+type HTTPFetcher struct {
+	Client *http.Client
+	// Header is added to every request. Its User-Agent defaults to
+	// DefaultUserAgent.
+	Header http.Header
+	// Cache, if set, makes requests conditional on the cached copy having
+	// changed; see NotModified.
+	Cache *Cache
+	// Timeout, if set, bounds each request, reading the body included.
+	Timeout time.Duration
+}
+
+// Fetch performs a GET request, or the POST ctx asks for (see withPost),
+// and returns the response body. Non-200 responses are reported as a
+// *StatusError.
+func (f *HTTPFetcher) Fetch(ctx context.Context, url string) (io.ReadCloser, error) {
+	client := f.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	cancel := context.CancelFunc(func() {})
+	if f.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, f.Timeout)
+	}
+	post, isPost := postFrom(ctx)
+	method, body := http.MethodGet, io.Reader(nil)
+	if isPost {
+		method, body = http.MethodPost, bytes.NewReader(post.body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	for name, values := range f.Header {
+		req.Header[name] = values
+	}
+	if req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", DefaultUserAgent)
+	}
+	if isPost {
+		req.Header.Set("Content-Type", post.contentType)
+	}
+	// POST responses aren't cached: they may differ by body at one URL.
+	if f.Cache != nil && !isPost {
+		// cachedFetch reads the whole body before returning.
+		defer cancel()
+		return f.cachedFetch(client, req)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		cancel()
+		return nil, statusError(resp)
+	}
+	return &cancelBody{ReadCloser: resp.Body, cancel: cancel}, nil
+}
+
+// cancelBody releases the request's timeout once the body is closed.
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+func statusError(resp *http.Response) *StatusError {
+	return &StatusError{
+		StatusCode: resp.StatusCode,
+		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+	}
+}
@@ -0,0 +1,58 @@
+package scraper
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"gopkg.in/yaml.v3"
+)
+
+// SelectorList is an ordered list of fallback CSS selectors: the first one
+// that matches anything wins. In the config file it may be written as a
+// single string or a list, so a minor site redesign can be handled by adding
+// the new selector in front of the old one.
+type SelectorList []string
+
+// UnmarshalYAML accepts a string or a list of strings.
+func (l *SelectorList) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		*l = SelectorList{value.Value}
+		return nil
+	}
+	var list []string
+	if err := value.Decode(&list); err != nil {
+		return err
+	}
+	*l = list
+	return nil
+}
+
+func (l SelectorList) String() string {
+	return strings.Join(l, " | ")
+}
+
+// find returns the matches of the first selector that matches anything
+// within s, or an empty selection.
+func (l SelectorList) find(s *goquery.Selection) *goquery.Selection {
+	for _, sel := range l {
+		if m := s.Find(sel); m.Length() > 0 {
+			return m
+		}
+	}
+	return s.Slice(0, 0)
+}
+
+// SelectorError reports that none of a source's selectors matched, which
+// usually means the site's markup changed.
+type SelectorError struct {
+	URL string
+	// Field is the selector that failed, e.g. "item" or "link".
+	Field     string
+	Selectors SelectorList
+}
+
+func (e *SelectorError) Error() string {
+	return fmt.Sprintf("no %s selector matched on %s (tried %s); the page layout may have changed",
+		e.Field, e.URL, e.Selectors)
+}
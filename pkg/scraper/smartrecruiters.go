@@ -0,0 +1,200 @@
+package scraper
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	smartRecruitersAPI  = "https://api.smartrecruiters.com/v1/companies/"
+	smartRecruitersJobs = "https://jobs.smartrecruiters.com/"
+)
+
+// smartRecruitersPageSize is the most postings the API returns at once.
+const smartRecruitersPageSize = 100
+
+// SmartRecruitersSource lists jobs through the SmartRecruiters public
+// posting API, then reads each job's description from it.
+type SmartRecruitersSource struct {
+	Company string
+	// Board is the company identifier, as in
+	// jobs.smartrecruiters.com/<board>.
+	Board string
+	// Departments and Offices restrict the jobs returned, matching the
+	// posting's department or function and its city, region or country.
+	// Names are compared case-insensitively; empty means no restriction.
+	Departments []string
+	Offices     []string
+
+	Fetcher       Fetcher
+	DetailWorkers int
+}
+
+func init() {
+	Register("smartrecruiters", newSmartRecruitersSource)
+}
+
+func newSmartRecruitersSource(cfg SourceConfig, env Env) (Source, error) {
+	if cfg.Board == "" {
+		return nil, errors.New("board must be set")
+	}
+	name := cfg.Name
+	if name == "" {
+		name = cfg.Board
+	}
+	return &SmartRecruitersSource{
+		Company:       name,
+		Board:         cfg.Board,
+		Departments:   cfg.Departments,
+		Offices:       cfg.Offices,
+		Fetcher:       env.Fetcher,
+		DetailWorkers: env.DetailWorkers,
+	}, nil
+}
+
+// Name implements Source.
+func (s *SmartRecruitersSource) Name() string {
+	return s.Company
+}
+
+type smartRecruitersPostings struct {
+	TotalFound int `json:"totalFound"`
+	Content    []struct {
+		ID           string `json:"id"`
+		Name         string `json:"name"`
+		RefNumber    string `json:"refNumber"`
+		ReleasedDate string `json:"releasedDate"`
+		Location     struct {
+			City    string `json:"city"`
+			Region  string `json:"region"`
+			Country string `json:"country"`
+			Remote  bool   `json:"remote"`
+		} `json:"location"`
+		Department struct {
+			Label string `json:"label"`
+		} `json:"department"`
+		Function struct {
+			Label string `json:"label"`
+		} `json:"function"`
+	} `json:"content"`
+}
+
+// Scrape implements Source. Postings are listed 100 at a time; if a later
+// page fails, the jobs listed so far are returned with the error.
+func (s *SmartRecruitersSource) Scrape(ctx context.Context) ([]JobPosting, error) {
+	start := time.Now()
+	apiURL := smartRecruitersAPI + url.PathEscape(s.Board) + "/postings"
+	site := smartRecruitersJobs + url.PathEscape(s.Board)
+	var jobs []JobPosting
+	var errs []error
+	total := 0
+	for offset := 0; offset == 0 || offset < total; offset += smartRecruitersPageSize {
+		var resp smartRecruitersPostings
+		pageURL := apiURL + "?limit=" + strconv.Itoa(smartRecruitersPageSize) + "&offset=" + strconv.Itoa(offset)
+		if err := fetchJSON(ctx, s.Fetcher, pageURL, &resp); err != nil {
+			if offset == 0 {
+				return nil, err
+			}
+			errs = append(errs, fmt.Errorf("postings from %d: %w", offset, err))
+			break
+		}
+		total = resp.TotalFound
+		if len(resp.Content) == 0 {
+			break
+		}
+		for _, p := range resp.Content {
+			l := p.Location
+			var parts []string
+			for _, part := range []string{l.City, l.Region, strings.ToUpper(l.Country)} {
+				if part != "" {
+					parts = append(parts, part)
+				}
+			}
+			location := strings.Join(parts, ", ")
+			places := []string{l.City, l.Region, l.Country, location}
+			if l.Remote {
+				location = strings.TrimSuffix("Remote / "+location, " / ")
+				places = append(places, "Remote")
+			}
+			if !matchesAny(s.Departments, []string{p.Department.Label, p.Function.Label}) || !matchesAny(s.Offices, places) {
+				continue
+			}
+
+			team := p.Department.Label
+			if team == "" {
+				team = p.Function.Label
+			}
+			job := JobPosting{
+				Company:     s.Company,
+				Title:       strings.TrimSpace(p.Name),
+				URL:         site + "/" + url.PathEscape(p.ID),
+				Location:    location,
+				Team:        team,
+				Requisition: p.RefNumber,
+			}
+			if t, err := time.Parse(time.RFC3339, p.ReleasedDate); err == nil {
+				job.PostedAt = &t
+			}
+			jobs = append(jobs, job)
+		}
+	}
+	slog.Info("fetched SmartRecruiters postings", "source", s.Company, "url", apiURL,
+		"duration", time.Since(start), "listed", total, "jobs_found", len(jobs))
+
+	if len(jobs) > 0 && ctx.Err() == nil {
+		start := time.Now()
+		detail := &apiDetailFetcher{Fetcher: s.Fetcher, Site: site, API: apiURL}
+		err := enrich(ctx, jobs, detail, smartRecruitersDetailParser{}, s.DetailWorkers)
+		slog.Info("fetched job details", "source", s.Company, "count", len(jobs), "duration", time.Since(start))
+		if err != nil {
+			slog.Warn("some job details failed", "source", s.Company, "err", err)
+			errs = append(errs, err)
+		}
+	}
+	return jobs, errors.Join(errs...)
+}
+
+type smartRecruitersPosting struct {
+	JobAd struct {
+		// Sections hold HTML.
+		Sections struct {
+			JobDescription        smartRecruitersSection `json:"jobDescription"`
+			Qualifications        smartRecruitersSection `json:"qualifications"`
+			AdditionalInformation smartRecruitersSection `json:"additionalInformation"`
+		} `json:"sections"`
+	} `json:"jobAd"`
+}
+
+type smartRecruitersSection struct {
+	Title string `json:"title"`
+	Text  string `json:"text"`
+}
+
+// smartRecruitersDetailParser reads the description from the API's record
+// of a posting, leaving out the company blurb every posting repeats.
+type smartRecruitersDetailParser struct{}
+
+// ParseDetail implements DetailParser.
+func (smartRecruitersDetailParser) ParseDetail(r io.Reader, job *JobPosting) error {
+	var p smartRecruitersPosting
+	if err := json.NewDecoder(r).Decode(&p); err != nil {
+		return fmt.Errorf("decoding %s: %w", job.URL, err)
+	}
+	sections := p.JobAd.Sections
+	var parts []string
+	for _, section := range []smartRecruitersSection{sections.JobDescription, sections.Qualifications, sections.AdditionalInformation} {
+		if text := htmlToText(section.Text); text != "" {
+			parts = append(parts, strings.TrimSpace(section.Title+"\n"+text))
+		}
+	}
+	job.Description = cleanText(strings.Join(parts, "\n\n"))
+	return nil
+}
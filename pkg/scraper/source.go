@@ -0,0 +1,355 @@
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/hunterheston/airbnb/pkg/location"
+	"github.com/hunterheston/airbnb/pkg/trace"
+)
+
+// Source produces the job postings of one company. Like HTMLSource.Scrape,
+// it may return both jobs and an error when only part of the scrape failed,
+// which includes ctx being done partway.
+type Source interface {
+	Name() string
+	Scrape(ctx context.Context) ([]JobPosting, error)
+}
+
+// Incomplete is implemented by sources that only ever list some of the
+// open jobs, such as feeds of the latest postings. A job missing from
+// their listing may well still be open.
+type Incomplete interface {
+	Incomplete() bool
+}
+
+// SourceConfig describes one source in the config file.
+type SourceConfig struct {
+	// Name is the company name shown in the digest.
+	Name string `yaml:"name"`
+	// Type selects the registered implementation, e.g. "airbnb", "html",
+	// "greenhouse", "lever", "ashby", "workday", "smartrecruiters",
+	// "recruitee", "feed", "export" or "plugin".
+	Type string `yaml:"type"`
+	// URL is the list page URL for "html" sources; see HTMLSource.BaseURL.
+	// For "workday" sources it is the career site, as in
+	// https://acme.wd5.myworkdayjobs.com/External, for "feed" sources the
+	// RSS or Atom feed, and for "export" sources an export file to fetch.
+	URL string `yaml:"url"`
+	// BrowseURL is the human-facing job list linked from the digest.
+	BrowseURL string    `yaml:"browse_url"`
+	Selectors Selectors `yaml:"selectors"`
+	PageSize  int       `yaml:"page_size"`
+	// Concurrency and Delay control how list pages are fetched; see
+	// HTMLSource.
+	Concurrency int           `yaml:"concurrency"`
+	Delay       time.Duration `yaml:"delay"`
+	// Detail selects fields on each job's own page. Leave empty to skip
+	// fetching detail pages.
+	Detail DetailSelectors `yaml:"detail"`
+
+	// Department and Office are a single careers.airbnb.com department
+	// and office, added to Departments and Offices.
+	Department string `yaml:"department"`
+	Office     string `yaml:"office"`
+
+	// Board is the Greenhouse board token, Lever site, Ashby job board or
+	// SmartRecruiters company name: the last part of the company's
+	// boards.greenhouse.io, jobs.lever.co, jobs.ashbyhq.com or
+	// jobs.smartrecruiters.com URL. For Recruitee it is the first part of
+	// <board>.recruitee.com. Departments and Offices
+	// restrict the jobs of those sources by name. For "airbnb" sources
+	// they are the _departments and _offices slugs of careers.airbnb.com,
+	// e.g. "engineering" and "united-states"; every pair is scraped and
+	// the results merged.
+	Board       string   `yaml:"board"`
+	Departments []string `yaml:"departments"`
+	Offices     []string `yaml:"offices"`
+
+	// UserAgent replaces the default User-Agent header for this source.
+	// Headers and Cookies are added to its every request, e.g. to get past
+	// a CDN that blocks unknown clients.
+	UserAgent string            `yaml:"user_agent"`
+	Headers   map[string]string `yaml:"headers"`
+	Cookies   map[string]string `yaml:"cookies"`
+	// Proxy routes this source's requests through an http://, https://,
+	// socks5:// or socks5h:// proxy, or "direct" to ignore the proxy
+	// environment variables. It defaults to the top-level proxy setting.
+	Proxy string `yaml:"proxy"`
+	// Renderer is "http" (the default) to fetch pages as they are served,
+	// or "browser" to render them in headless Chrome first, for pages whose
+	// listings are injected by JavaScript; see BrowserFetcher. WaitFor is
+	// the CSS selector a rendered page must contain before it is read.
+	Renderer string `yaml:"renderer"`
+	WaitFor  string `yaml:"wait_for"`
+
+	// Path is the CSV or JSON file an "export" source reads, when it isn't
+	// fetched from URL. TitlePattern splits the job titles of "feed" and
+	// "export" sources into fields; see parseTitlePattern.
+	Path         string `yaml:"path"`
+	TitlePattern string `yaml:"title_pattern"`
+
+	// Plugin names the executable a "plugin" source runs, and Options are
+	// passed to it as they are; see the plugin package.
+	Plugin  string         `yaml:"plugin"`
+	Options map[string]any `yaml:"options"`
+
+	// Fallback is scraped instead when this source fails outright, e.g. an
+	// HTML scraper behind an API source.
+	Fallback *SourceConfig `yaml:"fallback"`
+}
+
+// boardURLs are where the job board sources' boards are browsed, by type.
+var boardURLs = map[string]string{
+	"greenhouse":      "https://boards.greenhouse.io/",
+	"lever":           "https://jobs.lever.co/",
+	"ashby":           "https://jobs.ashbyhq.com/",
+	"smartrecruiters": "https://jobs.smartrecruiters.com/",
+}
+
+// Link returns the name and browse URL the digest should point at.
+func (c SourceConfig) Link() (name, url string) {
+	switch c.Type {
+	case "airbnb":
+		c = airbnbDefaults(c)
+	case "greenhouse", "lever", "ashby", "smartrecruiters", "recruitee":
+		if c.Name == "" {
+			c.Name = c.Board
+		}
+		if c.BrowseURL == "" && c.Board != "" {
+			if c.Type == "recruitee" {
+				c.BrowseURL = recruiteeSite(c.Board)
+			} else {
+				c.BrowseURL = boardURLs[c.Type] + c.Board
+			}
+		}
+	case "workday":
+		if c.Name == "" {
+			if _, tenant, err := workdayAPI(c.URL); err == nil {
+				c.Name = tenant
+			}
+		}
+		if c.BrowseURL == "" {
+			c.BrowseURL = c.URL
+		}
+	}
+	if c.BrowseURL == "" && c.Fallback != nil {
+		_, fallbackURL := c.Fallback.Link()
+		c.BrowseURL = fallbackURL
+	}
+	return c.Name, c.BrowseURL
+}
+
+// header returns the request headers for the source, with userAgent as the
+// User-Agent unless the source sets its own.
+func (c SourceConfig) header(userAgent string) http.Header {
+	h := http.Header{}
+	for name, value := range c.Headers {
+		h.Set(name, value)
+	}
+	if c.UserAgent != "" {
+		userAgent = c.UserAgent
+	}
+	if userAgent != "" && h.Get("User-Agent") == "" {
+		h.Set("User-Agent", userAgent)
+	}
+	if len(c.Cookies) > 0 {
+		names := make([]string, 0, len(c.Cookies))
+		for name := range c.Cookies {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		cookies := make([]string, len(names))
+		for i, name := range names {
+			cookies[i] = (&http.Cookie{Name: name, Value: c.Cookies[name]}).String()
+		}
+		h.Set("Cookie", strings.Join(cookies, "; "))
+	}
+	return h
+}
+
+// Options are the settings shared by every source.
+type Options struct {
+	Retry RetryPolicy
+	// DetailWorkers bounds how many detail pages a source fetches at once.
+	DetailWorkers int
+	// Cache, if set, is shared by every source's HTTP requests.
+	Cache *Cache
+	// Hosts, if set, paces every source's requests; see PoliteFetcher.
+	Hosts *Hosts
+	// UserAgent is sent by sources that don't set their own, instead of
+	// DefaultUserAgent.
+	UserAgent string
+	// Proxy is used by sources that don't set their own; see
+	// SourceConfig.Proxy. When empty, HTTP_PROXY and friends apply.
+	Proxy string
+	// Timeout bounds each HTTP request; see HTTPFetcher.Timeout.
+	Timeout time.Duration
+	// Archive, if set, keeps a copy of every page fetched.
+	Archive *Archive
+	// Replay, if set, is an archive directory whose pages are parsed
+	// instead of fetching anything.
+	Replay string
+	// Stats, if set, counts the pages every source fetches.
+	Stats *Stats
+	// Factories add source types to the registered ones, or replace them,
+	// for types that depend on the config, such as plugins.
+	Factories map[string]Factory
+}
+
+// Env carries what a source needs from the program around it.
+type Env struct {
+	// Fetcher is what the source should use for HTTP requests.
+	Fetcher       Fetcher
+	DetailWorkers int
+	// Cache is the HTTP cache behind Fetcher, or nil. Sources can use it to
+	// skip parsing pages that haven't changed.
+	Cache *Cache
+}
+
+// Factory builds a Source from its config.
+type Factory func(cfg SourceConfig, env Env) (Source, error)
+
+var registry = map[string]Factory{}
+
+// Register makes a source type available to NewSource. It panics if the
+// type is registered twice.
+func Register(typ string, f Factory) {
+	if _, dup := registry[typ]; dup {
+		panic("scraper: source type registered twice: " + typ)
+	}
+	registry[typ] = f
+}
+
+// Types returns the registered source types in sorted order.
+func Types() []string {
+	var types []string
+	for t := range registry {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// NewSource builds the source described by cfg.
+func NewSource(cfg SourceConfig, opts Options) (Source, error) {
+	factory, ok := opts.Factories[cfg.Type]
+	if !ok {
+		factory, ok = registry[cfg.Type]
+	}
+	if !ok {
+		return nil, fmt.Errorf("unknown source type %q (known: %v)", cfg.Type, Types())
+	}
+	proxy := cfg.Proxy
+	if proxy == "" {
+		proxy = opts.Proxy
+	}
+	client, err := proxyClient(proxy)
+	if err != nil {
+		return nil, fmt.Errorf("source %q: %w", cfg.Name, err)
+	}
+	cache := opts.Cache
+	var base Fetcher
+	switch cfg.Renderer {
+	case "", RendererHTTP:
+		base = &HTTPFetcher{Client: client, Header: cfg.header(opts.UserAgent), Cache: cache, Timeout: opts.Timeout}
+	case RendererBrowser:
+		if proxy == "direct" {
+			proxy = ""
+		}
+		base = &BrowserFetcher{Header: cfg.header(opts.UserAgent), Proxy: proxy, WaitFor: cfg.WaitFor, Timeout: opts.Timeout}
+		// Rendered pages can't be fetched conditionally.
+		cache = nil
+	default:
+		return nil, fmt.Errorf("source %q: unknown renderer %q (want %s or %s)", cfg.Name, cfg.Renderer, RendererHTTP, RendererBrowser)
+	}
+	base = &tracingFetcher{Fetcher: base}
+	if opts.Hosts != nil {
+		base = &PoliteFetcher{Fetcher: base, Hosts: opts.Hosts, Retry: opts.Retry}
+	}
+	if opts.Archive != nil {
+		base = &ArchiveFetcher{Fetcher: base, Archive: opts.Archive}
+	}
+	env := Env{
+		Fetcher:       &RetryFetcher{Fetcher: base, Policy: opts.Retry},
+		DetailWorkers: opts.DetailWorkers,
+		Cache:         cache,
+	}
+	if opts.Replay != "" {
+		env = Env{Fetcher: &ReplayFetcher{Dir: opts.Replay}, DetailWorkers: opts.DetailWorkers}
+	}
+	if opts.Stats != nil {
+		env.Fetcher = &countingFetcher{Fetcher: env.Fetcher, Stats: opts.Stats}
+	}
+	src, err := factory(cfg, env)
+	if err != nil {
+		return nil, fmt.Errorf("source %q: %w", cfg.Name, err)
+	}
+
+	if cfg.Fallback != nil {
+		fallbackCfg := *cfg.Fallback
+		if fallbackCfg.Name == "" {
+			fallbackCfg.Name = src.Name()
+		}
+		// The fallback is usually reached through the same network.
+		if fallbackCfg.Proxy == "" {
+			fallbackCfg.Proxy = cfg.Proxy
+		}
+		fallback, err := NewSource(fallbackCfg, opts)
+		if err != nil {
+			return nil, fmt.Errorf("source %q fallback: %w", cfg.Name, err)
+		}
+		src = &FallbackSource{Primary: src, Fallback: fallback}
+	}
+	return src, nil
+}
+
+// Result is the outcome of scraping one source.
+type Result struct {
+	Source string
+	Jobs   []JobPosting
+	// Err is non-nil if the scrape failed or was incomplete; Jobs then
+	// holds whatever was scraped.
+	Err error
+	// Incomplete is set for sources that never list every open job; see
+	// the Incomplete interface.
+	Incomplete bool
+}
+
+// ScrapeAll scrapes every source in turn. A failing source doesn't stop the
+// others, but ctx being done does: the sources not scraped yet get its error
+// as their result. Locations are rewritten with location.Normalize so they
+// read the same whatever the source.
+func ScrapeAll(ctx context.Context, sources []Source) []Result {
+	var results []Result
+	for _, src := range sources {
+		if err := ctx.Err(); err != nil {
+			results = append(results, Result{Source: src.Name(), Err: err})
+			continue
+		}
+		slog.Info("scraping source", "source", src.Name())
+		start := time.Now()
+		srcCtx, span := trace.Start(ctx, "source", attribute.String("source", src.Name()))
+		jobs, err := src.Scrape(srcCtx)
+		span.SetAttributes(attribute.Int("jobs_found", len(jobs)))
+		trace.SetError(span, err)
+		span.End()
+		slog.Info("scraped source", "source", src.Name(), "duration", time.Since(start), "jobs_found", len(jobs))
+		for i := range jobs {
+			jobs[i].Location = location.Normalize(jobs[i].Location)
+		}
+		res := Result{Source: src.Name(), Jobs: jobs, Err: err}
+		if inc, ok := src.(Incomplete); ok {
+			res.Incomplete = inc.Incomplete()
+		}
+		results = append(results, res)
+	}
+	return results
+}
@@ -0,0 +1,33 @@
+package scraper
+
+import (
+	"context"
+	"io"
+	"sync/atomic"
+)
+
+// Stats counts the pages the sources fetched in one scrape, listing and
+// detail pages alike, cached ones included. It is safe for concurrent use.
+type Stats struct {
+	pages atomic.Int64
+}
+
+// Pages returns how many pages were fetched.
+func (s *Stats) Pages() int {
+	return int(s.pages.Load())
+}
+
+// countingFetcher counts each page Fetcher returns in Stats. It sits
+// outside RetryFetcher so that a page fetched after retries counts once.
+type countingFetcher struct {
+	Fetcher Fetcher
+	Stats   *Stats
+}
+
+func (f *countingFetcher) Fetch(ctx context.Context, url string) (io.ReadCloser, error) {
+	body, err := f.Fetcher.Fetch(ctx, url)
+	if err == nil {
+		f.Stats.pages.Add(1)
+	}
+	return body, err
+}
@@ -0,0 +1,29 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/hunterheston/airbnb/pkg/trace"
+)
+
+// tracingFetcher times each request Fetcher makes, retries apart, up to
+// the response's headers; reading the body counts as parsing.
+type tracingFetcher struct {
+	Fetcher Fetcher
+}
+
+func (f *tracingFetcher) Fetch(ctx context.Context, url string) (io.ReadCloser, error) {
+	ctx, span := trace.StartClient(ctx, "fetch", attribute.String("url.full", url))
+	defer span.End()
+	body, err := f.Fetcher.Fetch(ctx, url)
+	var status *StatusError
+	if errors.As(err, &status) {
+		span.SetAttributes(attribute.Int("http.response.status_code", status.StatusCode))
+	}
+	trace.SetError(span, err)
+	return body, err
+}
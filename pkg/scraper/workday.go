@@ -0,0 +1,221 @@
+package scraper
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// workdayPageSize is how many jobs a Workday site returns per request at
+// most.
+const workdayPageSize = 20
+
+// workdayLocale matches the optional language part of a career site path,
+// as in /en-US/External.
+var workdayLocale = regexp.MustCompile(`^[a-z]{2}-[A-Z]{2}$`)
+
+// WorkdaySource lists jobs through the JSON ("cxs") API behind a
+// Workday-hosted career site, such as
+// https://acme.wd5.myworkdayjobs.com/en-US/External, then reads each job's
+// description from the same API.
+type WorkdaySource struct {
+	Company string
+	// SiteURL is the career site as browsed, locale optional.
+	SiteURL string
+	// Offices restricts the jobs returned to those with any of these
+	// locations, as Workday spells them, compared case-insensitively;
+	// empty means no restriction.
+	Offices []string
+
+	Fetcher       Fetcher
+	DetailWorkers int
+
+	// api is the site's cxs API root, to which a job's path is appended.
+	api string
+}
+
+func init() {
+	Register("workday", newWorkdaySource)
+}
+
+func newWorkdaySource(cfg SourceConfig, env Env) (Source, error) {
+	if cfg.URL == "" {
+		return nil, errors.New("url must be set to the career site, e.g. https://acme.wd5.myworkdayjobs.com/External")
+	}
+	if len(cfg.Departments) > 0 {
+		return nil, errors.New("departments are not supported; filter on the job titles instead")
+	}
+	api, tenant, err := workdayAPI(cfg.URL)
+	if err != nil {
+		return nil, err
+	}
+	name := cfg.Name
+	if name == "" {
+		name = tenant
+	}
+	return &WorkdaySource{
+		Company:       name,
+		SiteURL:       strings.TrimSuffix(cfg.URL, "/"),
+		Offices:       cfg.Offices,
+		Fetcher:       env.Fetcher,
+		DetailWorkers: env.DetailWorkers,
+		api:           api,
+	}, nil
+}
+
+// workdayAPI returns the cxs API root of the career site at siteURL and the
+// company's Workday tenant, the first label of its host name.
+func workdayAPI(siteURL string) (api, tenant string, err error) {
+	u, err := url.Parse(siteURL)
+	if err != nil || u.Host == "" {
+		return "", "", fmt.Errorf("url %q is not a career site URL", siteURL)
+	}
+	tenant, _, _ = strings.Cut(u.Hostname(), ".")
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(parts) > 0 && workdayLocale.MatchString(parts[0]) {
+		parts = parts[1:]
+	}
+	if len(parts) != 1 || parts[0] == "" {
+		return "", "", fmt.Errorf("url %q should end in the career site's name, as in https://acme.wd5.myworkdayjobs.com/External", siteURL)
+	}
+	return u.Scheme + "://" + u.Host + "/wday/cxs/" + url.PathEscape(tenant) + "/" + parts[0], tenant, nil
+}
+
+// Name implements Source.
+func (s *WorkdaySource) Name() string {
+	return s.Company
+}
+
+type workdayQuery struct {
+	AppliedFacets map[string][]string `json:"appliedFacets"`
+	Limit         int                 `json:"limit"`
+	Offset        int                 `json:"offset"`
+	SearchText    string              `json:"searchText"`
+}
+
+type workdayJobs struct {
+	// Total is only set on the first page.
+	Total       int `json:"total"`
+	JobPostings []struct {
+		Title string `json:"title"`
+		// ExternalPath is the job's path below the site, as in
+		// /job/Remote-USA/Software-Engineer_JR-0001.
+		ExternalPath  string   `json:"externalPath"`
+		LocationsText string   `json:"locationsText"`
+		PostedOn      string   `json:"postedOn"`
+		BulletFields  []string `json:"bulletFields"`
+	} `json:"jobPostings"`
+}
+
+// Scrape implements Source. Jobs are listed 20 at a time; if a later page
+// fails, the jobs listed so far are returned with the error.
+func (s *WorkdaySource) Scrape(ctx context.Context) ([]JobPosting, error) {
+	start := time.Now()
+	listURL := s.api + "/jobs"
+	var jobs []JobPosting
+	var errs []error
+	total := 0
+	for offset := 0; offset == 0 || offset < total; offset += workdayPageSize {
+		var resp workdayJobs
+		q := workdayQuery{AppliedFacets: map[string][]string{}, Limit: workdayPageSize, Offset: offset}
+		if err := postJSON(ctx, s.Fetcher, listURL, q, &resp); err != nil {
+			if offset == 0 {
+				return nil, err
+			}
+			errs = append(errs, fmt.Errorf("jobs from %d: %w", offset, err))
+			break
+		}
+		if offset == 0 {
+			total = resp.Total
+		}
+		if len(resp.JobPostings) == 0 {
+			break
+		}
+		now := time.Now()
+		for _, j := range resp.JobPostings {
+			job := JobPosting{
+				Company:  s.Company,
+				Title:    strings.TrimSpace(j.Title),
+				URL:      s.SiteURL + j.ExternalPath,
+				Location: j.LocationsText,
+			}
+			if t, ok := ParsePosted(j.PostedOn, now); ok {
+				job.PostedAt = &t
+			}
+			if len(j.BulletFields) > 0 {
+				job.Requisition = j.BulletFields[0]
+			}
+			jobs = append(jobs, job)
+		}
+	}
+	slog.Info("fetched Workday job list", "source", s.Company, "url", listURL,
+		"duration", time.Since(start), "listed", total, "jobs_found", len(jobs))
+
+	// The list only says "3 Locations" for jobs in several places, so the
+	// offices are matched once the details are in.
+	if len(jobs) > 0 && ctx.Err() == nil {
+		start := time.Now()
+		detail := &apiDetailFetcher{Fetcher: s.Fetcher, Site: s.SiteURL, API: s.api}
+		err := enrich(ctx, jobs, detail, workdayDetailParser{}, s.DetailWorkers)
+		slog.Info("fetched job details", "source", s.Company, "count", len(jobs), "duration", time.Since(start))
+		if err != nil {
+			slog.Warn("some job details failed", "source", s.Company, "err", err)
+			errs = append(errs, err)
+		}
+	}
+	if len(s.Offices) > 0 {
+		matched := jobs[:0]
+		for _, job := range jobs {
+			if matchesAny(s.Offices, strings.Split(job.Location, " / ")) {
+				matched = append(matched, job)
+			}
+		}
+		jobs = matched
+	}
+	return jobs, errors.Join(errs...)
+}
+
+type workdayDetail struct {
+	JobPostingInfo struct {
+		Title               string   `json:"title"`
+		JobDescription      string   `json:"jobDescription"`
+		Location            string   `json:"location"`
+		AdditionalLocations []string `json:"additionalLocations"`
+		// StartDate is the posting date, as 2006-01-02.
+		StartDate string `json:"startDate"`
+		JobReqID  string `json:"jobReqId"`
+	} `json:"jobPostingInfo"`
+}
+
+// workdayDetailParser reads the API's record of a job.
+type workdayDetailParser struct{}
+
+// ParseDetail implements DetailParser.
+func (workdayDetailParser) ParseDetail(r io.Reader, job *JobPosting) error {
+	var d workdayDetail
+	if err := json.NewDecoder(r).Decode(&d); err != nil {
+		return fmt.Errorf("decoding %s: %w", job.URL, err)
+	}
+	info := d.JobPostingInfo
+	if title := strings.TrimSpace(info.Title); title != "" {
+		job.Title = title
+	}
+	job.Description = htmlToText(info.JobDescription)
+	if info.Location != "" {
+		job.Location = strings.Join(append([]string{info.Location}, info.AdditionalLocations...), " / ")
+	}
+	if t, err := time.Parse(time.DateOnly, info.StartDate); err == nil {
+		job.PostedAt = &t
+	}
+	if info.JobReqID != "" {
+		job.Requisition = info.JobReqID
+	}
+	return nil
+}
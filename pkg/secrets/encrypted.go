@@ -0,0 +1,168 @@
+package secrets
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"github.com/getsops/sops/v3/cmd/sops/formats"
+	"github.com/getsops/sops/v3/decrypt"
+	"gopkg.in/yaml.v3"
+)
+
+// KeyFile returns the age identity file encrypted config values are
+// decrypted with: JOBWATCH_AGE_KEY_FILE, else SOPS_AGE_KEY_FILE, else the
+// file SOPS reads by default, e.g. ~/.config/sops/age/keys.txt on Linux.
+func KeyFile() (string, error) {
+	for _, env := range []string{"JOBWATCH_AGE_KEY_FILE", "SOPS_AGE_KEY_FILE"} {
+		if path := os.Getenv(env); path != "" {
+			return path, nil
+		}
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "sops", "age", "keys.txt"), nil
+}
+
+// Identities returns the age identities in KeyFile, or in SOPS_AGE_KEY if
+// that holds them instead.
+func Identities() ([]age.Identity, error) {
+	if keys := os.Getenv("SOPS_AGE_KEY"); keys != "" {
+		return age.ParseIdentities(strings.NewReader(keys))
+	}
+	path, err := KeyFile()
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading age key file: %w", err)
+	}
+	defer f.Close()
+	ids, err := age.ParseIdentities(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return ids, nil
+}
+
+// Decrypt decrypts the encrypted values in doc, a parsed config file, in
+// place: values that are ASCII-armored age files, as "jobwatch secrets
+// encrypt" or "age -a" write them, are replaced with their plaintext. The
+// identities are only read when there is something to decrypt. A whole
+// file encrypted with "sops" is decrypted with DecryptSOPS instead.
+func Decrypt(doc *yaml.Node) error {
+	var ids []age.Identity
+	identities := func() ([]age.Identity, error) {
+		if ids != nil {
+			return ids, nil
+		}
+		var err error
+		ids, err = Identities()
+		return ids, err
+	}
+	return decryptAge(doc, identities)
+}
+
+// IsSOPS reports whether doc, a parsed config file, was encrypted with
+// "sops", which leaves its metadata under a top-level sops key.
+func IsSOPS(doc *yaml.Node) bool {
+	root := doc
+	if root.Kind == yaml.DocumentNode && len(root.Content) > 0 {
+		root = root.Content[0]
+	}
+	if root.Kind != yaml.MappingNode {
+		return false
+	}
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value == "sops" {
+			return true
+		}
+	}
+	return false
+}
+
+// DecryptSOPS decrypts data, a YAML file encrypted with "sops", the way
+// sops does: the data key is recovered from any of the file's key groups
+// (age, PGP or a cloud KMS, with their usual credentials), and the file is
+// rejected unless its MAC matches. Age identities are read as sops reads
+// them, and from JOBWATCH_AGE_KEY_FILE too.
+func DecryptSOPS(data []byte) ([]byte, error) {
+	if path := os.Getenv("JOBWATCH_AGE_KEY_FILE"); path != "" && os.Getenv("SOPS_AGE_KEY_FILE") == "" {
+		os.Setenv("SOPS_AGE_KEY_FILE", path)
+	}
+	plain, err := decrypt.DataWithFormat(data, formats.Yaml)
+	if err != nil {
+		return nil, fmt.Errorf("sops: %w", err)
+	}
+	return plain, nil
+}
+
+// decryptAge replaces the age files among n's values with their plaintext.
+func decryptAge(n *yaml.Node, identities func() ([]age.Identity, error)) error {
+	if n.Kind == yaml.ScalarNode && isArmored(n.Value) {
+		ids, err := identities()
+		if err != nil {
+			return err
+		}
+		plain, err := decryptArmored(n.Value, ids)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", n.Line, err)
+		}
+		n.Value, n.Tag, n.Style = string(plain), "!!str", yaml.DoubleQuotedStyle
+		return nil
+	}
+	for _, c := range n.Content {
+		if err := decryptAge(c, identities); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Encrypt encrypts plaintext to recipients as an ASCII-armored age file,
+// which Decrypt decrypts when it is a value of the config.
+func Encrypt(plaintext []byte, recipients ...age.Recipient) ([]byte, error) {
+	var b bytes.Buffer
+	a := armor.NewWriter(&b)
+	w, err := age.Encrypt(a, recipients...)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(plaintext); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	if err := a.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// isArmored reports whether s is an ASCII-armored age file.
+func isArmored(s string) bool {
+	return strings.HasPrefix(strings.TrimSpace(s), armor.Header)
+}
+
+// decryptArmored decrypts the ASCII-armored age file s with whichever of
+// ids it was encrypted to.
+func decryptArmored(s string, ids []age.Identity) ([]byte, error) {
+	r, err := age.Decrypt(armor.NewReader(strings.NewReader(strings.TrimSpace(s)+"\n")), ids...)
+	if err != nil {
+		return nil, fmt.Errorf("age: %w", err)
+	}
+	plain, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("age: %w", err)
+	}
+	return plain, nil
+}
@@ -0,0 +1,84 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// KeyringService is the service the secrets are filed under in the
+// keyring.
+const KeyringService = "jobwatch"
+
+// Keyring keeps secrets in the OS keyring: the login keychain on macOS,
+// through the security tool, and the Secret Service (GNOME Keyring,
+// KWallet) on Linux, through secret-tool from libsecret.
+type Keyring struct {
+	Service string
+}
+
+// Get implements Store.
+func (k *Keyring) Get(ctx context.Context, name string) (string, error) {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.CommandContext(ctx, "security", "find-generic-password", "-s", k.Service, "-a", name, "-w")
+	case "linux", "freebsd", "openbsd", "netbsd":
+		cmd = exec.CommandContext(ctx, "secret-tool", "lookup", "service", k.Service, "account", name)
+	default:
+		return "", unsupported()
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		var exit *exec.ExitError
+		if errors.As(err, &exit) {
+			// Both tools exit non-zero for a missing item.
+			return "", ErrNotFound
+		}
+		return "", fmt.Errorf("keyring: %w", err)
+	}
+	v := strings.TrimSuffix(stdout.String(), "\n")
+	if v == "" {
+		return "", ErrNotFound
+	}
+	return v, nil
+}
+
+// Set implements Store. The value goes to the tool on its standard input,
+// so that it never shows in the process list.
+func (k *Keyring) Set(ctx context.Context, name, value string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.CommandContext(ctx, "security", "-i")
+		cmd.Stdin = strings.NewReader(fmt.Sprintf("add-generic-password -U -s %s -a %s -w %s\n",
+			securityQuote(k.Service), securityQuote(name), securityQuote(value)))
+	case "linux", "freebsd", "openbsd", "netbsd":
+		cmd = exec.CommandContext(ctx, "secret-tool", "store", "--label", k.Service+" "+name,
+			"service", k.Service, "account", name)
+		cmd.Stdin = strings.NewReader(value)
+	default:
+		return unsupported()
+	}
+	if out, err := cmd.CombinedOutput(); err != nil {
+		if out = bytes.TrimSpace(out); len(out) > 0 {
+			return fmt.Errorf("keyring: %w: %s", err, out)
+		}
+		return fmt.Errorf("keyring: %w", err)
+	}
+	return nil
+}
+
+// securityQuote quotes s for a command line of "security -i".
+func securityQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+func unsupported() error {
+	return fmt.Errorf("keyring: not supported on %s; use vault or ssm", runtime.GOOS)
+}
@@ -0,0 +1,93 @@
+// Package secrets fetches the passwords and API tokens the config refers
+// to from where they are kept safer than in the environment: the OS
+// keyring, HashiCorp Vault or AWS SSM Parameter Store.
+//
+// A config value refers to a secret as ${keyring:NAME},
+// ${vault:PATH#FIELD} or ${ssm:NAME}; see Resolve. Secrets can also be kept
+// in the config itself, encrypted with age or SOPS; see Decrypt and
+// DecryptSOPS.
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// ErrNotFound is returned for a secret that isn't stored.
+var ErrNotFound = errors.New("secret not found")
+
+// Store keeps secrets by name.
+type Store interface {
+	Get(ctx context.Context, name string) (string, error)
+	Set(ctx context.Context, name, value string) error
+}
+
+// Stores lists the stores by the prefix that refers to them.
+var Stores = []string{"keyring", "vault", "ssm"}
+
+// Open returns the store a reference prefix names, set up from the
+// environment: VAULT_ADDR and VAULT_TOKEN (or ~/.vault-token) for Vault,
+// and AWS_REGION and the AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and
+// AWS_SESSION_TOKEN credentials for SSM. client may be nil.
+func Open(prefix string, client *http.Client) (Store, error) {
+	switch prefix {
+	case "keyring":
+		return &Keyring{Service: KeyringService}, nil
+	case "vault":
+		return VaultFromEnv(client)
+	case "ssm":
+		return SSMFromEnv(client)
+	default:
+		return nil, fmt.Errorf("unknown secret store %q (want %s)", prefix, strings.Join(Stores, ", "))
+	}
+}
+
+// IsRef reports whether ref, the inside of a ${...} in the config, refers
+// to a secret store rather than an environment variable.
+func IsRef(ref string) bool {
+	prefix, _, ok := strings.Cut(ref, ":")
+	if !ok {
+		return false
+	}
+	for _, s := range Stores {
+		if prefix == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Resolve returns the secret ref refers to: "keyring:NAME" from the OS
+// keyring, "vault:PATH#FIELD" from a Vault KV secret (PATH as the API has
+// it, e.g. secret/data/jobwatch for KV version 2) or "ssm:NAME" from a
+// Parameter Store parameter, decrypted.
+func Resolve(ctx context.Context, ref string, client *http.Client) (string, error) {
+	prefix, name, _ := strings.Cut(ref, ":")
+	store, err := Open(prefix, client)
+	if err != nil {
+		return "", err
+	}
+	v, err := store.Get(ctx, name)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", ref, err)
+	}
+	return v, nil
+}
+
+// Getenv returns the environment variable name or, if it isn't set, the
+// secret stored under the same name in the OS keyring, for the settings
+// that are read from the environment without a config file.
+func Getenv(name string) string {
+	if v, ok := os.LookupEnv(name); ok {
+		return v
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	v, _ := (&Keyring{Service: KeyringService}).Get(ctx, name)
+	return v
+}
@@ -0,0 +1,113 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/hunterheston/airbnb/pkg/awssig"
+)
+
+// SSM reads and writes parameters in AWS Systems Manager Parameter Store.
+// Secrets are written as SecureString parameters and decrypted on read.
+type SSM struct {
+	Region string
+	awssig.Credentials
+
+	Client *http.Client
+}
+
+// SSMFromEnv returns Parameter Store in AWS_REGION (or AWS_DEFAULT_REGION)
+// with the credentials in AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and
+// AWS_SESSION_TOKEN.
+func SSMFromEnv(client *http.Client) (*SSM, error) {
+	s := &SSM{
+		Region: os.Getenv("AWS_REGION"),
+		Credentials: awssig.Credentials{
+			AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+			SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+		},
+		Client: client,
+	}
+	if s.Region == "" {
+		s.Region = os.Getenv("AWS_DEFAULT_REGION")
+	}
+	if s.Region == "" || s.AccessKeyID == "" || s.SecretAccessKey == "" {
+		return nil, errors.New("ssm: AWS_REGION, AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY must be set")
+	}
+	return s, nil
+}
+
+// Get implements Store.
+func (s *SSM) Get(ctx context.Context, name string) (string, error) {
+	var out struct {
+		Parameter struct {
+			Value string `json:"Value"`
+		} `json:"Parameter"`
+	}
+	err := s.call(ctx, "GetParameter", map[string]any{"Name": name, "WithDecryption": true}, &out)
+	if err != nil {
+		return "", err
+	}
+	return out.Parameter.Value, nil
+}
+
+// Set implements Store.
+func (s *SSM) Set(ctx context.Context, name, value string) error {
+	return s.call(ctx, "PutParameter", map[string]any{
+		"Name": name, "Value": value, "Type": "SecureString", "Overwrite": true,
+	}, nil)
+}
+
+// call makes an AmazonSSM API call and decodes the response into out, if
+// given.
+func (s *SSM) call(ctx context.Context, action string, in, out any) error {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	endpoint := fmt.Sprintf("https://ssm.%s.amazonaws.com/", s.Region)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "AmazonSSM."+action)
+	awssig.Sign(req, body, s.Credentials, s.Region, "ssm", time.Now())
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("ssm: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		var e struct {
+			Type    string `json:"__type"`
+			Message string `json:"message"`
+		}
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		if json.Unmarshal(msg, &e) == nil && strings.HasSuffix(e.Type, "ParameterNotFound") {
+			return ErrNotFound
+		}
+		return fmt.Errorf("ssm: %s: HTTP %d: %s", action, resp.StatusCode, bytes.TrimSpace(msg))
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("ssm: %s: %w", action, err)
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Vault reads and writes fields of secrets in a HashiCorp Vault KV secrets
+// engine, version 1 or 2.
+type Vault struct {
+	// Addr is the server, e.g. https://vault.example.com:8200.
+	Addr  string
+	Token string
+
+	Client *http.Client
+}
+
+// VaultFromEnv returns the Vault at VAULT_ADDR, with the token in
+// VAULT_TOKEN or else the one "vault login" left in ~/.vault-token.
+func VaultFromEnv(client *http.Client) (*Vault, error) {
+	v := &Vault{Addr: os.Getenv("VAULT_ADDR"), Token: os.Getenv("VAULT_TOKEN"), Client: client}
+	if v.Addr == "" {
+		return nil, errors.New("vault: VAULT_ADDR is not set")
+	}
+	if v.Token == "" {
+		if home, err := os.UserHomeDir(); err == nil {
+			b, _ := os.ReadFile(filepath.Join(home, ".vault-token"))
+			v.Token = strings.TrimSpace(string(b))
+		}
+	}
+	if v.Token == "" {
+		return nil, errors.New("vault: VAULT_TOKEN is not set and there is no ~/.vault-token")
+	}
+	return v, nil
+}
+
+// splitRef splits "PATH#FIELD"; the field defaults to "value".
+func splitRef(name string) (path, field string) {
+	path, field, _ = strings.Cut(name, "#")
+	if field == "" {
+		field = "value"
+	}
+	return strings.Trim(path, "/"), field
+}
+
+// kv2 reports whether path is in a KV version 2 engine, whose API paths
+// have "data" after the mount.
+func kv2(path string) bool {
+	_, rest, _ := strings.Cut(path, "/")
+	return strings.HasPrefix(rest, "data/")
+}
+
+// Get implements Store. name is PATH#FIELD.
+func (v *Vault) Get(ctx context.Context, name string) (string, error) {
+	path, field := splitRef(name)
+	var body struct {
+		Data map[string]json.RawMessage `json:"data"`
+	}
+	status, err := v.do(ctx, http.MethodGet, path, nil, &body)
+	if status == http.StatusNotFound {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	data := body.Data
+	if raw, ok := data["data"]; ok && kv2(path) {
+		data = nil
+		if err := json.Unmarshal(raw, &data); err != nil {
+			return "", fmt.Errorf("vault: %s: %w", path, err)
+		}
+	}
+	raw, ok := data[field]
+	if !ok {
+		return "", ErrNotFound
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return "", fmt.Errorf("vault: %s#%s is not a string", path, field)
+	}
+	return s, nil
+}
+
+// Set implements Store. name is PATH#FIELD; the secret's other fields are
+// kept.
+func (v *Vault) Set(ctx context.Context, name, value string) error {
+	path, field := splitRef(name)
+	var current struct {
+		Data map[string]any `json:"data"`
+	}
+	status, err := v.do(ctx, http.MethodGet, path, nil, &current)
+	if err != nil && status != http.StatusNotFound {
+		return err
+	}
+	data := current.Data
+	if kv2(path) {
+		data, _ = data["data"].(map[string]any)
+	}
+	if data == nil {
+		data = map[string]any{}
+	}
+	data[field] = value
+
+	var payload any = data
+	if kv2(path) {
+		payload = map[string]any{"data": data}
+	}
+	_, err = v.do(ctx, http.MethodPost, path, payload, nil)
+	return err
+}
+
+// do calls the API at /v1/path and decodes the response into out, if
+// given. It returns the HTTP status along with any error.
+func (v *Vault) do(ctx context.Context, method, path string, in, out any) (int, error) {
+	var body io.Reader
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return 0, err
+		}
+		body = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(v.Addr, "/")+"/v1/"+path, body)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("X-Vault-Token", v.Token)
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	client := v.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("vault: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return resp.StatusCode, fmt.Errorf("vault: %s %s: HTTP %d: %s", method, path, resp.StatusCode, bytes.TrimSpace(msg))
+	}
+	if out == nil {
+		return resp.StatusCode, nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return resp.StatusCode, fmt.Errorf("vault: %s: %w", path, err)
+	}
+	return resp.StatusCode, nil
+}
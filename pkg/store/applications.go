@@ -0,0 +1,169 @@
+package store
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Application states, as stored in Job.Application, in the order an
+// application goes through them.
+const (
+	ApplicationNone        = ""
+	ApplicationApplied     = "applied"
+	ApplicationPhoneScreen = "phone_screen"
+	ApplicationOnsite      = "onsite"
+	ApplicationOffer       = "offer"
+	ApplicationRejected    = "rejected"
+)
+
+// ApplicationStates lists the application states in order, starting with
+// not having applied.
+var ApplicationStates = []string{
+	ApplicationNone, ApplicationApplied, ApplicationPhoneScreen, ApplicationOnsite, ApplicationOffer, ApplicationRejected,
+}
+
+// awaiting are the states in which the next move is the employer's, so
+// that a long silence calls for a follow-up.
+const awaiting = `('applied', 'phone_screen', 'onsite')`
+
+// Awaiting reports whether an application in state waits on the employer.
+func Awaiting(state string) bool {
+	switch state {
+	case ApplicationApplied, ApplicationPhoneScreen, ApplicationOnsite:
+		return true
+	}
+	return false
+}
+
+// ApplicationEvent records an application reaching a state.
+type ApplicationEvent struct {
+	State string    `json:"state"`
+	At    time.Time `json:"at"`
+}
+
+// SetApplication records at now that the application for the job with the
+// given ID has reached state, one of the Application constants. Setting
+// ApplicationNone withdraws it.
+func (s *Store) SetApplication(id int64, state string, now time.Time) error {
+	if !slices.Contains(ApplicationStates, state) {
+		return fmt.Errorf("unknown application state %q", state)
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`UPDATE jobs SET application = ?, application_at = ? WHERE id = ?`, state, now, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	if _, err := tx.Exec(`INSERT INTO application_events (job_id, state, at) VALUES (?, ?, ?)`, id, state, now); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Applications returns the states the application for the job with the
+// given ID went through, oldest first.
+func (s *Store) Applications(id int64) ([]ApplicationEvent, error) {
+	rows, err := s.db.Query(`SELECT state, at FROM application_events WHERE job_id = ? ORDER BY at, id`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []ApplicationEvent
+	for rows.Next() {
+		var e ApplicationEvent
+		if err := rows.Scan(&e.State, &e.At); err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+// FollowUps returns the jobs whose applications have waited on the
+// employer since before the given time and haven't been the subject of a
+// reminder at this stage yet, longest waiting first.
+func (s *Store) FollowUps(before time.Time) ([]Job, error) {
+	return s.query(`WHERE application IN `+awaiting+` AND application_at <= ?
+		AND (reminded_at IS NULL OR reminded_at < application_at)
+		ORDER BY application_at, id`, before)
+}
+
+// Tracked returns the jobs with an interview or deadline set or an
+// application waiting on the employer, in the order they were stored.
+func (s *Store) Tracked() ([]Job, error) {
+	return s.query(`WHERE interview_at IS NOT NULL OR deadline IS NOT NULL OR application IN ` + awaiting + `
+		ORDER BY id`)
+}
+
+// MarkReminded records that a follow-up reminder for each of jobs went out
+// at now.
+func (s *Store) MarkReminded(jobs []Job, now time.Time) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, j := range jobs {
+		if _, err := tx.Exec(`UPDATE jobs SET reminded_at = ? WHERE id = ?`, now, j.ID); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// SetInterview records at now that the next interview for the job with the
+// given ID is at the given time; nil clears it.
+func (s *Store) SetInterview(id int64, at *time.Time, now time.Time) error {
+	return s.setDate(id, "interview_at", at, now)
+}
+
+// SetDeadline records at now that applications for the job with the given
+// ID close on the day of the given time; nil clears it.
+func (s *Store) SetDeadline(id int64, day *time.Time, now time.Time) error {
+	return s.setDate(id, "deadline", day, now)
+}
+
+// setDate sets one of the jobs' date columns, noting when for
+// CalendarPending.
+func (s *Store) setDate(id int64, column string, at *time.Time, now time.Time) error {
+	if at != nil {
+		// Times in a zone that is only an offset, as parsed from RFC 3339,
+		// wouldn't read back.
+		utc := at.UTC()
+		at = &utc
+	}
+	res, err := s.db.Exec(`UPDATE jobs SET `+column+` = ?, dates_at = ? WHERE id = ?`, at, now, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// CalendarPending returns the jobs whose interview or deadline changed
+// since they were last written to the calendar, in the order they were
+// changed.
+func (s *Store) CalendarPending() ([]Job, error) {
+	return s.query(`WHERE dates_at IS NOT NULL AND (calendar_at IS NULL OR calendar_at < dates_at)
+		ORDER BY dates_at, id`)
+}
+
+// MarkCalendared records that the dates of the job with the given ID were
+// written to the calendar as they stood at the given time, which should be
+// from before they were read.
+func (s *Store) MarkCalendared(id int64, at time.Time) error {
+	_, err := s.db.Exec(`UPDATE jobs SET calendar_at = ? WHERE id = ?`, at, id)
+	return err
+}
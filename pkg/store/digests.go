@@ -0,0 +1,25 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// RecordDigest records that a digest with newJobs new and closed closed
+// jobs was sent at now.
+func (s *Store) RecordDigest(newJobs, closed int, now time.Time) error {
+	_, err := s.db.Exec(`INSERT INTO digests (sent_at, new, closed) VALUES (?, ?, ?)`, now, newJobs, closed)
+	return err
+}
+
+// LastDigest returns when the last digest was sent, or the zero time if
+// none was.
+func (s *Store) LastDigest() (time.Time, error) {
+	var t time.Time
+	err := s.db.QueryRow(`SELECT sent_at FROM digests ORDER BY sent_at DESC, id DESC LIMIT 1`).Scan(&t)
+	if errors.Is(err, sql.ErrNoRows) {
+		return time.Time{}, nil
+	}
+	return t, err
+}
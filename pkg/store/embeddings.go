@@ -0,0 +1,42 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// Embedding returns the vector cached under key, or nil if there is none.
+// It implements fit.Cache.
+func (s *Store) Embedding(key string) ([]float32, error) {
+	var blob []byte
+	err := s.db.QueryRow(`SELECT vector FROM embeddings WHERE key = ?`, key).Scan(&blob)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(blob)%4 != 0 {
+		return nil, fmt.Errorf("embedding %s is %d bytes long", key, len(blob))
+	}
+	vec := make([]float32, len(blob)/4)
+	for i := range vec {
+		vec[i] = math.Float32frombits(binary.LittleEndian.Uint32(blob[i*4:]))
+	}
+	return vec, nil
+}
+
+// SaveEmbedding caches vec under key at now, as little-endian float32s.
+func (s *Store) SaveEmbedding(key string, vec []float32, now time.Time) error {
+	blob := make([]byte, len(vec)*4)
+	for i, f := range vec {
+		binary.LittleEndian.PutUint32(blob[i*4:], math.Float32bits(f))
+	}
+	_, err := s.db.Exec(`INSERT INTO embeddings (key, vector, created_at) VALUES (?, ?, ?)
+		ON CONFLICT (key) DO UPDATE SET vector = excluded.vector, created_at = excluded.created_at`, key, blob, now)
+	return err
+}
@@ -0,0 +1,26 @@
+package store
+
+import "fmt"
+
+// Feedback, as stored in Job.Feedback.
+const (
+	FeedbackNone = ""
+	FeedbackUp   = "up"
+	FeedbackDown = "down"
+)
+
+// SetFeedback gives the job with the given ID a thumbs-up or -down, one of
+// the Feedback constants. FeedbackNone takes it back.
+func (s *Store) SetFeedback(id int64, feedback string) error {
+	switch feedback {
+	case FeedbackNone, FeedbackUp, FeedbackDown:
+	default:
+		return fmt.Errorf("unknown feedback %q", feedback)
+	}
+	return s.update(id, `feedback = ?`, feedback)
+}
+
+// Rated returns the jobs that were given feedback, oldest first.
+func (s *Store) Rated() ([]Job, error) {
+	return s.query(`WHERE feedback != '' ORDER BY first_seen, id`)
+}
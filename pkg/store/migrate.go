@@ -0,0 +1,350 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/hunterheston/airbnb/pkg/scraper"
+)
+
+// migrations bring the schema from one version to the next; migrations[i]
+// takes a database at version i to version i+1. The version is kept in
+// SQLite's user_version. Append new migrations and never edit released
+// ones.
+var migrations = []struct {
+	name string
+	up   func(tx *sql.Tx) error
+}{
+	{"baseline", baseline},
+	{"notifications", execMigration(`
+CREATE TABLE notifications (
+	id      INTEGER PRIMARY KEY,
+	job_id  INTEGER NOT NULL REFERENCES jobs (id),
+	kind    TEXT NOT NULL,
+	sent_at TIMESTAMP NOT NULL
+);
+CREATE INDEX notifications_job ON notifications (job_id);`)},
+	{"salary", execMigration(`
+ALTER TABLE jobs ADD COLUMN salary_min INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE jobs ADD COLUMN salary_max INTEGER NOT NULL DEFAULT 0;`)},
+	{"outbox", execMigration(`
+CREATE TABLE outbox (
+	id              INTEGER PRIMARY KEY,
+	message         BLOB NOT NULL,
+	attempts        INTEGER NOT NULL,
+	last_error      TEXT NOT NULL,
+	queued_at       TIMESTAMP NOT NULL,
+	next_attempt_at TIMESTAMP NOT NULL,
+	alerted_at      TIMESTAMP
+);`)},
+	{"requisition", execMigration(`ALTER TABLE jobs ADD COLUMN requisition TEXT NOT NULL DEFAULT '';`)},
+	{"job_ids", rekey},
+	{"application", execMigration(`
+ALTER TABLE jobs ADD COLUMN application TEXT NOT NULL DEFAULT '';
+ALTER TABLE jobs ADD COLUMN application_at TIMESTAMP;`)},
+	{"application_events", execMigration(`
+CREATE TABLE application_events (
+	id     INTEGER PRIMARY KEY,
+	job_id INTEGER NOT NULL REFERENCES jobs (id),
+	state  TEXT NOT NULL,
+	at     TIMESTAMP NOT NULL
+);
+CREATE INDEX application_events_job ON application_events (job_id);
+INSERT INTO application_events (job_id, state, at)
+	SELECT id, application, application_at FROM jobs WHERE application != '' AND application_at IS NOT NULL;
+ALTER TABLE jobs ADD COLUMN reminded_at TIMESTAMP;`)},
+	{"digests", execMigration(`
+CREATE TABLE digests (
+	id      INTEGER PRIMARY KEY,
+	sent_at TIMESTAMP NOT NULL,
+	new     INTEGER NOT NULL,
+	closed  INTEGER NOT NULL
+);`)},
+	{"run_details", execMigration(`
+ALTER TABLE runs ADD COLUMN pages INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE runs ADD COLUMN found INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE runs ADD COLUMN notification TEXT NOT NULL DEFAULT '';`)},
+	{"mutes", execMigration(`
+ALTER TABLE jobs ADD COLUMN muted_at TIMESTAMP;
+CREATE TABLE muted_titles (
+	id       INTEGER PRIMARY KEY,
+	company  TEXT NOT NULL,
+	title    TEXT NOT NULL,
+	muted_at TIMESTAMP NOT NULL,
+	UNIQUE (company, title)
+);`)},
+	{"posted_at", execMigration(`ALTER TABLE jobs ADD COLUMN posted_at TIMESTAMP;`)},
+	{"source_health", execMigration(`
+CREATE TABLE source_health (
+	source     TEXT PRIMARY KEY,
+	failures   INTEGER NOT NULL,
+	last_error TEXT NOT NULL DEFAULT '',
+	opened_at  TIMESTAMP
+);`)},
+	{"jobs_fts", execMigration(`
+CREATE VIRTUAL TABLE jobs_fts USING fts5 (
+	title, company, location, team, description,
+	content = 'jobs', content_rowid = 'id', tokenize = 'porter unicode61'
+);
+CREATE TRIGGER jobs_fts_insert AFTER INSERT ON jobs BEGIN
+	INSERT INTO jobs_fts (rowid, title, company, location, team, description)
+		VALUES (new.id, new.title, new.company, new.location, new.team, new.description);
+END;
+CREATE TRIGGER jobs_fts_delete AFTER DELETE ON jobs BEGIN
+	INSERT INTO jobs_fts (jobs_fts, rowid, title, company, location, team, description)
+		VALUES ('delete', old.id, old.title, old.company, old.location, old.team, old.description);
+END;
+CREATE TRIGGER jobs_fts_update AFTER UPDATE OF id, title, company, location, team, description ON jobs
+	WHEN old.id IS NOT new.id OR old.title IS NOT new.title OR old.company IS NOT new.company
+		OR old.location IS NOT new.location OR old.team IS NOT new.team OR old.description IS NOT new.description
+BEGIN
+	INSERT INTO jobs_fts (jobs_fts, rowid, title, company, location, team, description)
+		VALUES ('delete', old.id, old.title, old.company, old.location, old.team, old.description);
+	INSERT INTO jobs_fts (rowid, title, company, location, team, description)
+		VALUES (new.id, new.title, new.company, new.location, new.team, new.description);
+END;
+INSERT INTO jobs_fts (jobs_fts) VALUES ('rebuild');`)},
+	{"tags", execMigration(`ALTER TABLE jobs ADD COLUMN tags TEXT NOT NULL DEFAULT '';`)},
+	{"summaries", execMigration(`
+CREATE TABLE summaries (
+	key        TEXT PRIMARY KEY,
+	text       TEXT NOT NULL,
+	level      TEXT NOT NULL,
+	remote     TEXT NOT NULL,
+	created_at TIMESTAMP NOT NULL
+);`)},
+	{"fit", execMigration(`
+ALTER TABLE jobs ADD COLUMN fit INTEGER NOT NULL DEFAULT 0;
+CREATE TABLE embeddings (
+	key        TEXT PRIMARY KEY,
+	vector     BLOB NOT NULL,
+	created_at TIMESTAMP NOT NULL
+);`)},
+	{"feedback", execMigration(`ALTER TABLE jobs ADD COLUMN feedback TEXT NOT NULL DEFAULT '';`)},
+	{"dates", execMigration(`
+ALTER TABLE jobs ADD COLUMN interview_at TIMESTAMP;
+ALTER TABLE jobs ADD COLUMN deadline TIMESTAMP;
+ALTER TABLE jobs ADD COLUMN dates_at TIMESTAMP;
+ALTER TABLE jobs ADD COLUMN calendar_at TIMESTAMP;`)},
+}
+
+// Version returns the schema version of the database.
+func (s *Store) Version() (int, error) {
+	var v int
+	err := s.db.QueryRow(`PRAGMA user_version`).Scan(&v)
+	return v, err
+}
+
+// LatestVersion is the schema version Migrate brings databases to.
+func LatestVersion() int {
+	return len(migrations)
+}
+
+// Migrate applies the migrations the database hasn't had yet, each in its
+// own transaction, and returns the versions before and after. A database
+// from a newer release is left alone and reported as an error.
+func (s *Store) Migrate() (from, to int, err error) {
+	from, err = s.Version()
+	if err != nil {
+		return 0, 0, err
+	}
+	if from > len(migrations) {
+		return from, from, fmt.Errorf("database schema version %d is newer than this program's %d", from, len(migrations))
+	}
+
+	for v := from; v < len(migrations); v++ {
+		m := migrations[v]
+		if err := s.migrate(v+1, m.up); err != nil {
+			return from, v, fmt.Errorf("migration %d (%s): %w", v+1, m.name, err)
+		}
+	}
+	return from, len(migrations), nil
+}
+
+func (s *Store) migrate(version int, up func(tx *sql.Tx) error) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := up(tx); err != nil {
+		return err
+	}
+	// PRAGMA doesn't take parameters.
+	if _, err := tx.Exec(fmt.Sprintf(`PRAGMA user_version = %d`, version)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func execMigration(query string) func(tx *sql.Tx) error {
+	return func(tx *sql.Tx) error {
+		_, err := tx.Exec(query)
+		return err
+	}
+}
+
+// Vacuum rebuilds the database file, reclaiming the space of deleted rows.
+func (s *Store) Vacuum() error {
+	_, err := s.db.Exec(`VACUUM`)
+	return err
+}
+
+// baseline creates the schema as it was before versioned migrations, and
+// upgrades databases from those releases, which grew columns as needed.
+func baseline(tx *sql.Tx) error {
+	if _, err := tx.Exec(`
+CREATE TABLE IF NOT EXISTS jobs (
+	key         TEXT PRIMARY KEY,
+	title       TEXT NOT NULL,
+	url         TEXT NOT NULL,
+	first_seen  TIMESTAMP NOT NULL,
+	last_seen   TIMESTAMP NOT NULL
+);`); err != nil {
+		return err
+	}
+	if err := addColumns(tx, "jobs", []column{
+		{"company", "TEXT NOT NULL DEFAULT ''", ""},
+		{"location", "TEXT NOT NULL DEFAULT ''", ""},
+		{"team", "TEXT NOT NULL DEFAULT ''", ""},
+		{"description", "TEXT NOT NULL DEFAULT ''", ""},
+		// Jobs recorded before notifications were tracked were already emailed.
+		{"notified_at", "TIMESTAMP", "UPDATE jobs SET notified_at = last_seen"},
+		{"closed_at", "TIMESTAMP", ""},
+		{"closed_notified_at", "TIMESTAMP", ""},
+		{"interested_at", "TIMESTAMP", ""},
+		{"level", "TEXT NOT NULL DEFAULT ''", ""},
+		// A column of its own rather than the rowid, which VACUUM may renumber.
+		{"id", "INTEGER", "UPDATE jobs SET id = rowid"},
+	}); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS jobs_id ON jobs (id)`); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`
+CREATE TABLE IF NOT EXISTS runs (
+	id          INTEGER PRIMARY KEY,
+	started_at  TIMESTAMP NOT NULL,
+	finished_at TIMESTAMP NOT NULL,
+	matched     INTEGER NOT NULL,
+	new         INTEGER NOT NULL,
+	closed      INTEGER NOT NULL,
+	error       TEXT NOT NULL DEFAULT ''
+);`); err != nil {
+		return err
+	}
+	return addColumns(tx, "runs", []column{
+		// Set once a failed run has been mentioned in a digest.
+		{"reported_at", "TIMESTAMP", ""},
+	})
+}
+
+// rekey switches the jobs' keys from their URLs to the job IDs in them, as
+// scraper.JobPosting.Key now has it. Jobs that turn out to have been
+// recorded twice under different URLs are merged into the one seen first,
+// which takes over the other's notifications and, if the other was seen
+// more recently, its details and open or closed state.
+func rekey(tx *sql.Tx) error {
+	type job struct {
+		id  int64
+		url string
+	}
+	rows, err := tx.Query(`SELECT id, url FROM jobs ORDER BY first_seen, id`)
+	if err != nil {
+		return err
+	}
+	var jobs []job
+	for rows.Next() {
+		var j job
+		if err := rows.Scan(&j.id, &j.url); err != nil {
+			rows.Close()
+			return err
+		}
+		jobs = append(jobs, j)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	kept := map[string]int64{}
+	var order []string
+	for _, j := range jobs {
+		key := scraper.JobPosting{URL: j.url}.Key()
+		keep, dup := kept[key]
+		if !dup {
+			kept[key] = j.id
+			order = append(order, key)
+			continue
+		}
+		for _, q := range []string{
+			`UPDATE notifications SET job_id = ? WHERE job_id = ?`,
+			`UPDATE jobs SET (url, company, title, location, team, description, level, salary_min, salary_max,
+					requisition, last_seen, closed_at, closed_notified_at) =
+				(SELECT url, company, title, location, team, description, level, salary_min, salary_max,
+					requisition, last_seen, closed_at, closed_notified_at FROM jobs WHERE id = ?2)
+			WHERE id = ?1 AND last_seen <= (SELECT last_seen FROM jobs WHERE id = ?2)`,
+			`UPDATE jobs SET notified_at = COALESCE(notified_at, (SELECT notified_at FROM jobs WHERE id = ?2)),
+				interested_at = COALESCE(interested_at, (SELECT interested_at FROM jobs WHERE id = ?2))
+			WHERE id = ?1`,
+			`DELETE FROM jobs WHERE id = ?2`,
+		} {
+			if _, err := tx.Exec(q, keep, j.id); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Move every key out of the way first, since a new key may be another
+	// job's old one.
+	if _, err := tx.Exec(`UPDATE jobs SET key = 'rekey:' || id`); err != nil {
+		return err
+	}
+	for _, key := range order {
+		if _, err := tx.Exec(`UPDATE jobs SET key = ? WHERE id = ?`, key, kept[key]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// column is a column added to a table, with the statement that backfills
+// existing rows.
+type column struct{ name, def, backfill string }
+
+// addColumns adds whichever of cols table doesn't have yet, running each
+// one's backfill after adding it.
+func addColumns(tx *sql.Tx, table string, cols []column) error {
+	existing := map[string]bool{}
+	rows, err := tx.Query(`SELECT name FROM pragma_table_info(?)`, table)
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return err
+		}
+		existing[name] = true
+	}
+	rows.Close()
+
+	for _, col := range cols {
+		if existing[col.name] {
+			continue
+		}
+		if _, err := tx.Exec(`ALTER TABLE ` + table + ` ADD COLUMN ` + col.name + ` ` + col.def); err != nil {
+			return err
+		}
+		if col.backfill != "" {
+			if _, err := tx.Exec(col.backfill); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package store
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/hunterheston/airbnb/pkg/dedup"
+	"github.com/hunterheston/airbnb/pkg/scraper"
+)
+
+// titleKey is what jobs with similar titles have in common: the company and
+// the title without a location it repeats.
+func titleKey(job scraper.JobPosting) (company, title string) {
+	return strings.ToLower(strings.TrimSpace(job.Company)), dedup.NormalizeTitle(job.Title, job.Location)
+}
+
+// Mute marks the job with the given ID as not interesting at now, which
+// keeps it out of every later digest and closing notice even though it
+// matches the filters. With similar, jobs of the same company with the same
+// title, whatever their location, are muted too, those found later
+// included.
+func (s *Store) Mute(id int64, similar bool, now time.Time) error {
+	job, err := s.Get(id)
+	if err != nil {
+		return err
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`UPDATE jobs SET muted_at = COALESCE(muted_at, ?) WHERE id = ?`, now, id); err != nil {
+		return err
+	}
+	if similar {
+		company, title := titleKey(job.JobPosting)
+		if _, err := tx.Exec(`INSERT INTO muted_titles (company, title, muted_at) VALUES (?, ?, ?)
+			ON CONFLICT (company, title) DO NOTHING`, company, title, now); err != nil {
+			return err
+		}
+		ids, err := similarJobs(tx, company, title)
+		if err != nil {
+			return err
+		}
+		if err := setMuted(tx, ids, now); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// similarJobs returns the IDs of the jobs of company whose title normalizes to
+// title.
+func similarJobs(tx *sql.Tx, company, title string) ([]int64, error) {
+	rows, err := tx.Query(`SELECT id, company, title, location FROM jobs WHERE lower(company) = ?`, company)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		var job scraper.JobPosting
+		if err := rows.Scan(&id, &job.Company, &job.Title, &job.Location); err != nil {
+			return nil, err
+		}
+		if _, t := titleKey(job); t == title {
+			ids = append(ids, id)
+		}
+	}
+	return ids, rows.Err()
+}
+
+// setMuted sets muted_at on the jobs with the given IDs; a nil at unmutes
+// them.
+func setMuted(tx *sql.Tx, ids []int64, at any) error {
+	for _, id := range ids {
+		if _, err := tx.Exec(`UPDATE jobs SET muted_at = ? WHERE id = ?`, at, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Unmute undoes Mute for the job with the given ID. If its title was muted,
+// that is undone too, for the jobs muted with it as well.
+func (s *Store) Unmute(id int64) error {
+	job, err := s.Get(id)
+	if err != nil {
+		return err
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`UPDATE jobs SET muted_at = NULL WHERE id = ?`, id); err != nil {
+		return err
+	}
+	company, title := titleKey(job.JobPosting)
+	res, err := tx.Exec(`DELETE FROM muted_titles WHERE company = ? AND title = ?`, company, title)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n > 0 {
+		ids, err := similarJobs(tx, company, title)
+		if err != nil {
+			return err
+		}
+		if err := setMuted(tx, ids, nil); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// mutedTitles returns the muted titles, keyed by company and title as
+// titleKey has them.
+func mutedTitles(tx *sql.Tx) (map[[2]string]bool, error) {
+	rows, err := tx.Query(`SELECT company, title FROM muted_titles`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	muted := map[[2]string]bool{}
+	for rows.Next() {
+		var company, title string
+		if err := rows.Scan(&company, &title); err != nil {
+			return nil, err
+		}
+		muted[[2]string{company, title}] = true
+	}
+	return muted, rows.Err()
+}
@@ -0,0 +1,70 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/hunterheston/airbnb/pkg/scraper"
+)
+
+// Notification kinds.
+const (
+	// NotifiedNew is a job announced in a digest.
+	NotifiedNew = "new"
+	// NotifiedClosed is a job's closing announced in a digest.
+	NotifiedClosed = "closed"
+	// NotifiedPush is a job pushed as soon as it was found.
+	NotifiedPush = "push"
+)
+
+// Notification records one message about a job.
+type Notification struct {
+	Kind   string    `json:"kind"`
+	SentAt time.Time `json:"sent_at"`
+}
+
+// RecordPush records that jobs were pushed at now.
+func (s *Store) RecordPush(jobs []scraper.JobPosting, now time.Time) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, job := range jobs {
+		if err := recordNotification(tx, job, NotifiedPush, now); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// Notifications returns what was sent about the job with the given ID,
+// oldest first.
+func (s *Store) Notifications(id int64) ([]Notification, error) {
+	rows, err := s.db.Query(`SELECT kind, sent_at FROM notifications WHERE job_id = ? ORDER BY sent_at, id`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []Notification
+	for rows.Next() {
+		var n Notification
+		if err := rows.Scan(&n.Kind, &n.SentAt); err != nil {
+			return nil, err
+		}
+		out = append(out, n)
+	}
+	return out, rows.Err()
+}
+
+func recordNotification(tx *sql.Tx, job scraper.JobPosting, kind string, now time.Time) error {
+	_, err := tx.Exec(`INSERT INTO notifications (job_id, kind, sent_at) SELECT id, ?, ? FROM jobs WHERE key = ?`,
+		kind, now, job.Key())
+	if err != nil {
+		return fmt.Errorf("recording %s notification for %s: %w", kind, job.URL, err)
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package store
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Message is an undelivered message waiting in the outbox.
+type Message struct {
+	ID int64 `json:"id"`
+	// Body is the message as the sender encoded it.
+	Body []byte `json:"body"`
+	// Attempts counts the failed deliveries so far and LastError describes
+	// the latest.
+	Attempts    int       `json:"attempts"`
+	LastError   string    `json:"last_error"`
+	QueuedAt    time.Time `json:"queued_at"`
+	NextAttempt time.Time `json:"next_attempt_at"`
+	// AlertedAt is when someone was told the message is stuck, if they
+	// were.
+	AlertedAt *time.Time `json:"alerted_at,omitempty"`
+}
+
+// Enqueue adds a message whose first delivery failed with lastErr, to be
+// tried again at next.
+func (s *Store) Enqueue(body []byte, lastErr string, now, next time.Time) error {
+	_, err := s.db.Exec(`INSERT INTO outbox (message, attempts, last_error, queued_at, next_attempt_at) VALUES (?, 1, ?, ?, ?)`,
+		body, lastErr, now, next)
+	return err
+}
+
+// Due returns the queued messages whose next attempt is at or before now,
+// oldest first.
+func (s *Store) Due(now time.Time) ([]Message, error) {
+	return s.messages(`WHERE next_attempt_at <= ? ORDER BY queued_at, id`, now)
+}
+
+// Outbox returns every queued message, oldest first.
+func (s *Store) Outbox() ([]Message, error) {
+	return s.messages(`ORDER BY queued_at, id`)
+}
+
+// Delivered removes a message from the outbox.
+func (s *Store) Delivered(id int64) error {
+	_, err := s.db.Exec(`DELETE FROM outbox WHERE id = ?`, id)
+	return err
+}
+
+// Failed records another failed delivery of a message, to be tried again
+// at next.
+func (s *Store) Failed(id int64, lastErr string, next time.Time) error {
+	_, err := s.db.Exec(`UPDATE outbox SET attempts = attempts + 1, last_error = ?, next_attempt_at = ? WHERE id = ?`,
+		lastErr, next, id)
+	return err
+}
+
+// MarkAlerted records that someone was told about a stuck message.
+func (s *Store) MarkAlerted(id int64, now time.Time) error {
+	_, err := s.db.Exec(`UPDATE outbox SET alerted_at = ? WHERE id = ?`, now, id)
+	return err
+}
+
+func (s *Store) messages(suffix string, args ...any) ([]Message, error) {
+	rows, err := s.db.Query(`SELECT id, message, attempts, last_error, queued_at, next_attempt_at, alerted_at FROM outbox `+suffix, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []Message
+	for rows.Next() {
+		var m Message
+		var alerted sql.NullTime
+		if err := rows.Scan(&m.ID, &m.Body, &m.Attempts, &m.LastError, &m.QueuedAt, &m.NextAttempt, &alerted); err != nil {
+			return nil, err
+		}
+		if alerted.Valid {
+			m.AlertedAt = &alerted.Time
+		}
+		out = append(out, m)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,120 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// Run is the record of one scrape.
+type Run struct {
+	ID         int64     `json:"id"`
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+	// Pages counts the pages fetched, listings and job pages alike.
+	Pages int `json:"pages"`
+	// Found, Matched, New and Closed count the jobs scraped, the ones that
+	// passed the filters, the ones among those seen for the first time and
+	// the ones no longer listed.
+	Found   int `json:"found"`
+	Matched int `json:"matched"`
+	New     int `json:"new"`
+	Closed  int `json:"closed"`
+	// Error describes what failed, if anything; the counts then cover
+	// whatever was scraped.
+	Error string `json:"error,omitempty"`
+	// Notification is the outcome of the last send after the run, e.g.
+	// "sent 3 new and 1 closed jobs", or "" if there was none.
+	Notification string `json:"notification,omitempty"`
+}
+
+// RecordRun stores r and returns it with its ID set.
+func (s *Store) RecordRun(r Run) (Run, error) {
+	res, err := s.db.Exec(`INSERT INTO runs (started_at, finished_at, pages, found, matched, new, closed, error, notification)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		r.StartedAt, r.FinishedAt, r.Pages, r.Found, r.Matched, r.New, r.Closed, r.Error, r.Notification)
+	if err != nil {
+		return Run{}, err
+	}
+	r.ID, err = res.LastInsertId()
+	return r, err
+}
+
+// ErrNoRun is returned by Run when there is no run with the given ID.
+var ErrNoRun = errors.New("store: no such run")
+
+// Run returns the run with the given ID, or ErrNoRun.
+func (s *Store) Run(id int64) (Run, error) {
+	rows, err := s.db.Query(`SELECT `+runColumns+` FROM runs WHERE id = ?`, id)
+	if err != nil {
+		return Run{}, err
+	}
+	runs, err := scanRuns(rows)
+	if err != nil {
+		return Run{}, err
+	}
+	if len(runs) == 0 {
+		return Run{}, ErrNoRun
+	}
+	return runs[0], nil
+}
+
+// SetNotification records outcome as the Notification of the latest run.
+// It does nothing if there is no run yet.
+func (s *Store) SetNotification(outcome string) error {
+	_, err := s.db.Exec(`UPDATE runs SET notification = ?
+		WHERE id = (SELECT id FROM runs ORDER BY started_at DESC, id DESC LIMIT 1)`, outcome)
+	return err
+}
+
+// Partial returns the runs that had errors and haven't been reported in a
+// digest yet, oldest first.
+func (s *Store) Partial() ([]Run, error) {
+	rows, err := s.db.Query(`SELECT ` + runColumns + ` FROM runs
+		WHERE error != '' AND reported_at IS NULL ORDER BY started_at, id`)
+	if err != nil {
+		return nil, err
+	}
+	return scanRuns(rows)
+}
+
+// MarkReported records that runs have been reported in a digest.
+func (s *Store) MarkReported(runs []Run, now time.Time) error {
+	for _, r := range runs {
+		if _, err := s.db.Exec(`UPDATE runs SET reported_at = ? WHERE id = ?`, now, r.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Runs returns up to limit runs, newest first. A limit of 0 returns them
+// all.
+func (s *Store) Runs(limit int) ([]Run, error) {
+	if limit <= 0 {
+		limit = -1
+	}
+	rows, err := s.db.Query(`SELECT `+runColumns+` FROM runs
+		ORDER BY started_at DESC, id DESC LIMIT ?`, limit)
+	if err != nil {
+		return nil, err
+	}
+	return scanRuns(rows)
+}
+
+const runColumns = `id, started_at, finished_at, pages, found, matched, new, closed, error, notification`
+
+func scanRuns(rows *sql.Rows) ([]Run, error) {
+	defer rows.Close()
+
+	var runs []Run
+	for rows.Next() {
+		var r Run
+		if err := rows.Scan(&r.ID, &r.StartedAt, &r.FinishedAt, &r.Pages, &r.Found, &r.Matched, &r.New, &r.Closed,
+			&r.Error, &r.Notification); err != nil {
+			return nil, err
+		}
+		runs = append(runs, r)
+	}
+	return runs, rows.Err()
+}
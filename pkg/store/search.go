@@ -0,0 +1,99 @@
+package store
+
+import "strings"
+
+// Hit is a job Search found, with the passage that matched.
+type Hit struct {
+	Job
+	// Snippet is the passage of the best-matching field around the match,
+	// the matched words in [brackets].
+	Snippet string `json:"snippet"`
+}
+
+// Search returns up to limit stored jobs, closed ones included, whose
+// title, company, location, team or description match query, best match
+// first. A limit of 0 returns them all.
+//
+// Every word of query must appear, in any form ("systems" matches
+// "system"); words in double quotes must appear together, and a word
+// ending in * matches any word it starts.
+func (s *Store) Search(query string, limit int) ([]Hit, error) {
+	match := ftsQuery(query)
+	if match == "" {
+		return nil, nil
+	}
+	if limit <= 0 {
+		limit = -1
+	}
+	rows, err := s.db.Query(`SELECT jobs_fts.rowid, snippet(jobs_fts, -1, '[', ']', '…', 16) FROM jobs_fts
+		WHERE jobs_fts MATCH ? ORDER BY bm25(jobs_fts, 10, 5, 2, 2, 1) LIMIT ?`, match, limit)
+	if err != nil {
+		return nil, err
+	}
+	var ids []int64
+	snippets := map[int64]string{}
+	for rows.Next() {
+		var id int64
+		var snippet string
+		if err := rows.Scan(&id, &snippet); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		ids = append(ids, id)
+		snippets[id] = snippet
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	args := make([]any, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	jobs, err := s.query(`WHERE id IN (?`+strings.Repeat(", ?", len(ids)-1)+`)`, args...)
+	if err != nil {
+		return nil, err
+	}
+	byID := map[int64]Job{}
+	for _, j := range jobs {
+		byID[j.ID] = j
+	}
+	hits := make([]Hit, 0, len(ids))
+	for _, id := range ids {
+		if j, ok := byID[id]; ok {
+			hits = append(hits, Hit{Job: j, Snippet: snippets[id]})
+		}
+	}
+	return hits, nil
+}
+
+// ftsQuery turns a search as typed into an FTS5 query, quoting every word
+// and phrase so that punctuation in it isn't read as query syntax.
+func ftsQuery(query string) string {
+	var terms []string
+	add := func(term string, prefix bool) {
+		term = strings.TrimSpace(term)
+		if term == "" {
+			return
+		}
+		q := `"` + strings.ReplaceAll(term, `"`, `""`) + `"`
+		if prefix {
+			q += "*"
+		}
+		terms = append(terms, q)
+	}
+	for i, part := range strings.Split(query, `"`) {
+		if i%2 == 1 {
+			add(part, false)
+			continue
+		}
+		for _, word := range strings.Fields(part) {
+			add(strings.TrimSuffix(word, "*"), strings.HasSuffix(word, "*"))
+		}
+	}
+	return strings.Join(terms, " ")
+}
@@ -0,0 +1,67 @@
+package store
+
+import (
+	"database/sql"
+	"time"
+)
+
+// SourceHealth is how a source has fared in recent scrapes, for the
+// circuit breaker that stops scraping a source that keeps failing.
+type SourceHealth struct {
+	Source string `json:"source"`
+	// Failures counts the scrapes in a row that failed outright.
+	Failures  int    `json:"failures"`
+	LastError string `json:"last_error,omitempty"`
+	// OpenedAt is when the breaker last opened, skipping the source, or
+	// nil while it is closed.
+	OpenedAt *time.Time `json:"opened_at,omitempty"`
+}
+
+// SourceFailed counts another failed scrape of source and returns its
+// health afterwards.
+func (s *Store) SourceFailed(source, lastErr string) (SourceHealth, error) {
+	_, err := s.db.Exec(`INSERT INTO source_health (source, failures, last_error) VALUES (?, 1, ?)
+		ON CONFLICT (source) DO UPDATE SET failures = failures + 1, last_error = excluded.last_error`,
+		source, lastErr)
+	if err != nil {
+		return SourceHealth{}, err
+	}
+	health, err := s.SourceHealth()
+	return health[source], err
+}
+
+// SourceSucceeded resets source's failures and closes its breaker.
+func (s *Store) SourceSucceeded(source string) error {
+	_, err := s.db.Exec(`DELETE FROM source_health WHERE source = ?`, source)
+	return err
+}
+
+// OpenBreaker records that source's breaker opened at now.
+func (s *Store) OpenBreaker(source string, now time.Time) error {
+	_, err := s.db.Exec(`UPDATE source_health SET opened_at = ? WHERE source = ?`, now, source)
+	return err
+}
+
+// SourceHealth returns the sources that have failed since they last
+// succeeded, by name.
+func (s *Store) SourceHealth() (map[string]SourceHealth, error) {
+	rows, err := s.db.Query(`SELECT source, failures, last_error, opened_at FROM source_health`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	health := map[string]SourceHealth{}
+	for rows.Next() {
+		var h SourceHealth
+		var opened sql.NullTime
+		if err := rows.Scan(&h.Source, &h.Failures, &h.LastError, &opened); err != nil {
+			return nil, err
+		}
+		if opened.Valid {
+			h.OpenedAt = &opened.Time
+		}
+		health[h.Source] = h
+	}
+	return health, rows.Err()
+}
@@ -0,0 +1,439 @@
+// Package store persists the job postings seen by previous runs.
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite" // registers the "sqlite" driver
+
+	"github.com/hunterheston/airbnb/pkg/scraper"
+)
+
+// ErrNotFound is returned for an ID that isn't in the store.
+var ErrNotFound = errors.New("store: no such job")
+
+// Store is a SQLite database of seen job postings, keyed by
+// scraper.JobPosting.Key: the job ID in the posting's URL, or the URL
+// itself when it has none.
+type Store struct {
+	db *sql.DB
+}
+
+// Job is a stored job posting.
+type Job struct {
+	// ID is a number identifying the job, stable for the life of the
+	// database.
+	ID int64 `json:"id"`
+	scraper.JobPosting
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+	// NotifiedAt is nil until the job has been sent in a digest.
+	NotifiedAt *time.Time `json:"notified_at"`
+	// ClosedAt is when the job was first missing from its source, or nil
+	// while it is still listed.
+	ClosedAt *time.Time `json:"closed_at"`
+	// InterestedAt is when the job was marked as interesting, or nil.
+	InterestedAt *time.Time `json:"interested_at"`
+	// Application is where an application for the job stands, one of the
+	// Application constants.
+	Application string `json:"application"`
+	// ApplicationAt is when Application was last changed, or nil.
+	ApplicationAt *time.Time `json:"application_at"`
+	// MutedAt is when the job was marked as not interesting, keeping it out
+	// of digests, or nil.
+	MutedAt *time.Time `json:"muted_at"`
+	// Feedback is the thumbs-up or -down the job was given, one of the
+	// Feedback constants, which trains the bayes classifier.
+	Feedback string `json:"feedback"`
+	// InterviewAt is when the next interview for the job is, or nil.
+	InterviewAt *time.Time `json:"interview_at"`
+	// Deadline is the day applications for the job close, or nil.
+	Deadline *time.Time `json:"deadline"`
+}
+
+// Job statuses, as returned by Job.Status.
+const (
+	StatusNew    = "new"
+	StatusSeen   = "seen"
+	StatusClosed = "closed"
+)
+
+// Status is StatusClosed for a job that is no longer listed, StatusSeen for
+// one that has been sent in a digest and StatusNew otherwise.
+func (j Job) Status() string {
+	switch {
+	case j.ClosedAt != nil:
+		return StatusClosed
+	case j.NotifiedAt != nil:
+		return StatusSeen
+	default:
+		return StatusNew
+	}
+}
+
+// Open opens (creating if necessary) the database at path and brings its
+// schema up to date.
+func Open(path string) (*Store, error) {
+	s, err := OpenUnmigrated(path)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := s.Migrate(); err != nil {
+		s.Close()
+		return nil, fmt.Errorf("migrating schema: %w", err)
+	}
+	return s, nil
+}
+
+// OpenUnmigrated opens the database at path without touching its schema,
+// for commands that manage the schema themselves.
+func OpenUnmigrated(path string) (*Store, error) {
+	// Wait for other processes' writes instead of failing, since the
+	// dashboard and the scheduled runs share the file.
+	db, err := sql.Open("sqlite", path+"?_pragma=busy_timeout(5000)")
+	if err != nil {
+		return nil, err
+	}
+	return &Store{db: db}, nil
+}
+
+// Close closes the underlying database.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// CopyTo writes a consistent copy of the database to path, which must not
+// exist yet.
+func (s *Store) CopyTo(path string) error {
+	_, err := s.db.Exec(`VACUUM INTO ?`, path)
+	return err
+}
+
+// Record marks every job as seen at now and returns the ones that had never
+// been seen before, preserving order. A closed job that is listed again is
+// reopened. New jobs with a muted title are recorded muted and not
+// returned.
+func (s *Store) Record(jobs []scraper.JobPosting, now time.Time) ([]scraper.JobPosting, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	muted, err := mutedTitles(tx)
+	if err != nil {
+		return nil, err
+	}
+	var fresh []scraper.JobPosting
+	for _, job := range jobs {
+		company, title := titleKey(job)
+		var mutedAt any
+		if muted[[2]string{company, title}] {
+			mutedAt = now
+		}
+		res, err := tx.Exec(`INSERT INTO jobs (id, key, company, title, url, location, team, description, level,
+				salary_min, salary_max, requisition, posted_at, tags, fit, first_seen, last_seen, muted_at)
+			VALUES ((SELECT COALESCE(MAX(id), 0) + 1 FROM jobs), ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?) ON CONFLICT (key) DO NOTHING`,
+			job.Key(), job.Company, job.Title, job.URL, job.Location, job.Team, job.Description, job.Level,
+			job.SalaryMin, job.SalaryMax, job.Requisition, job.PostedAt, joinTags(job.Tags), job.Fit, now, now, mutedAt)
+		if err != nil {
+			return nil, fmt.Errorf("recording %s: %w", job.URL, err)
+		}
+		if n, _ := res.RowsAffected(); n > 0 {
+			if mutedAt == nil {
+				fresh = append(fresh, job)
+			}
+			continue
+		}
+
+		// A description is kept when a later scrape comes back without one,
+		// e.g. because the job page failed to load.
+		if _, err := tx.Exec(`UPDATE jobs SET company = ?, title = ?, url = ?, location = ?, team = ?,
+				description = COALESCE(NULLIF(?, ''), description), level = ?,
+				salary_min = ?, salary_max = ?, requisition = ?, posted_at = COALESCE(?, posted_at),
+				tags = COALESCE(NULLIF(?, ''), tags), fit = COALESCE(NULLIF(?, 0), fit), last_seen = ?,
+				closed_at = NULL, closed_notified_at = NULL
+			WHERE key = ?`,
+			job.Company, job.Title, job.URL, job.Location, job.Team, job.Description, job.Level,
+			job.SalaryMin, job.SalaryMax, job.Requisition, job.PostedAt, joinTags(job.Tags), job.Fit, now, job.Key()); err != nil {
+			return nil, fmt.Errorf("recording %s: %w", job.URL, err)
+		}
+	}
+	return fresh, tx.Commit()
+}
+
+// Pending returns the jobs that haven't been sent in a digest yet, oldest
+// first, leaving out muted ones.
+func (s *Store) Pending() ([]scraper.JobPosting, error) {
+	jobs, err := s.query(`WHERE notified_at IS NULL AND closed_at IS NULL AND muted_at IS NULL ORDER BY first_seen, id`)
+	if err != nil {
+		return nil, err
+	}
+	return postings(jobs), nil
+}
+
+func postings(jobs []Job) []scraper.JobPosting {
+	p := make([]scraper.JobPosting, len(jobs))
+	for i, j := range jobs {
+		p[i] = j.JobPosting
+	}
+	return p
+}
+
+// MarkNotified records that jobs were sent at now.
+func (s *Store) MarkNotified(jobs []scraper.JobPosting, now time.Time) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, job := range jobs {
+		if _, err := tx.Exec(`UPDATE jobs SET notified_at = ? WHERE key = ?`, now, job.Key()); err != nil {
+			return fmt.Errorf("marking %s notified: %w", job.URL, err)
+		}
+		if err := recordNotification(tx, job, NotifiedNew, now); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// SkipPending marks every job that hasn't been sent yet as if it had been
+// at now, without recording a notification, so that the digest leaves them
+// out. It returns how many it marked.
+func (s *Store) SkipPending(now time.Time) (int, error) {
+	res, err := s.db.Exec(`UPDATE jobs SET notified_at = ? WHERE notified_at IS NULL AND closed_at IS NULL`, now)
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
+
+// CloseMissing marks the open jobs of company that aren't in listed as
+// closed at now and returns how many it closed. listed must be everything
+// the company currently lists, not just the jobs that passed the filters.
+func (s *Store) CloseMissing(company string, listed []scraper.JobPosting, now time.Time) (int, error) {
+	present := make(map[string]bool, len(listed))
+	for _, job := range listed {
+		present[job.Key()] = true
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(`SELECT key FROM jobs WHERE company = ? AND closed_at IS NULL`, company)
+	if err != nil {
+		return 0, err
+	}
+	var missing []string
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		if !present[key] {
+			missing = append(missing, key)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, key := range missing {
+		if _, err := tx.Exec(`UPDATE jobs SET closed_at = ? WHERE key = ?`, now, key); err != nil {
+			return 0, fmt.Errorf("closing %s: %w", key, err)
+		}
+	}
+	return len(missing), tx.Commit()
+}
+
+// PendingClosed returns the jobs that closed since the last digest. Only
+// jobs that were announced in an earlier digest and aren't muted are
+// included.
+func (s *Store) PendingClosed() ([]scraper.JobPosting, error) {
+	jobs, err := s.query(`WHERE closed_at IS NOT NULL AND closed_notified_at IS NULL AND notified_at IS NOT NULL
+		AND muted_at IS NULL ORDER BY closed_at, id`)
+	if err != nil {
+		return nil, err
+	}
+	return postings(jobs), nil
+}
+
+// MarkClosedNotified records that the closing of jobs was reported at now.
+func (s *Store) MarkClosedNotified(jobs []scraper.JobPosting, now time.Time) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, job := range jobs {
+		if _, err := tx.Exec(`UPDATE jobs SET closed_notified_at = ? WHERE key = ?`, now, job.Key()); err != nil {
+			return fmt.Errorf("marking %s closed: %w", job.URL, err)
+		}
+		if err := recordNotification(tx, job, NotifiedClosed, now); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// Get returns the job with the given ID, or ErrNotFound.
+func (s *Store) Get(id int64) (Job, error) {
+	jobs, err := s.query(`WHERE id = ?`, id)
+	if err != nil {
+		return Job{}, err
+	}
+	if len(jobs) == 0 {
+		return Job{}, ErrNotFound
+	}
+	return jobs[0], nil
+}
+
+// Lookup returns the job posted at link, or ErrNotFound.
+func (s *Store) Lookup(link string) (Job, error) {
+	return s.ByKey(scraper.JobPosting{URL: scraper.CanonicalURL(link)}.Key())
+}
+
+// ByKey returns the job whose scraper.JobPosting.Key is key, or
+// ErrNotFound.
+func (s *Store) ByKey(key string) (Job, error) {
+	jobs, err := s.query(`WHERE key = ?`, key)
+	if err != nil {
+		return Job{}, err
+	}
+	if len(jobs) == 0 {
+		return Job{}, ErrNotFound
+	}
+	return jobs[0], nil
+}
+
+// ByPostingID returns the job whose URL carries id, the site's own ID for
+// it as scraper.JobID finds it, or ErrNotFound. If several sites use the
+// same ID, the most recently seen job wins.
+func (s *Store) ByPostingID(id string) (Job, error) {
+	jobs, err := s.query(`WHERE key LIKE '%#' || ? ORDER BY last_seen DESC LIMIT 1`, id)
+	if err != nil {
+		return Job{}, err
+	}
+	if len(jobs) == 0 {
+		return Job{}, ErrNotFound
+	}
+	return jobs[0], nil
+}
+
+// SetInterested marks the job with the given ID as interesting at now, or
+// clears the mark when interested is false.
+func (s *Store) SetInterested(id int64, interested bool, now time.Time) error {
+	var at any
+	if interested {
+		at = now
+	}
+	return s.update(id, `interested_at = ?`, at)
+}
+
+// SetStatus moves the job with the given ID to status by hand: StatusNew
+// queues it for the next digest again, StatusSeen drops it from the queue
+// and StatusClosed closes it without announcing the closing.
+func (s *Store) SetStatus(id int64, status string, now time.Time) error {
+	switch status {
+	case StatusNew:
+		return s.update(id, `notified_at = NULL, closed_at = NULL, closed_notified_at = NULL`)
+	case StatusSeen:
+		return s.update(id, `notified_at = COALESCE(notified_at, ?), closed_at = NULL, closed_notified_at = NULL`, now)
+	case StatusClosed:
+		return s.update(id, `closed_at = COALESCE(closed_at, ?), closed_notified_at = COALESCE(closed_notified_at, ?)`, now, now)
+	default:
+		return fmt.Errorf("unknown status %q", status)
+	}
+}
+
+// update runs an UPDATE with the given SET clause on one job. It returns
+// ErrNotFound if there is no job with that ID.
+func (s *Store) update(id int64, set string, args ...any) error {
+	res, err := s.db.Exec(`UPDATE jobs SET `+set+` WHERE id = ?`, append(args, id)...)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// List returns every stored job, most recently discovered first.
+func (s *Store) List() ([]Job, error) {
+	return s.query(`ORDER BY first_seen DESC, id DESC`)
+}
+
+const jobColumns = `id, company, title, url, location, team, description, level, salary_min, salary_max,
+	requisition, posted_at, first_seen, last_seen, notified_at, closed_at, interested_at, application, application_at, muted_at, tags, fit, feedback,
+	interview_at, deadline`
+
+// joinTags is how tags are stored: comma-separated, since tag names can't
+// hold commas.
+func joinTags(tags []string) string {
+	return strings.Join(tags, ",")
+}
+
+// query selects jobs with the given WHERE/ORDER BY suffix.
+func (s *Store) query(suffix string, args ...any) ([]Job, error) {
+	rows, err := s.db.Query(`SELECT `+jobColumns+` FROM jobs `+suffix, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var jobs []Job
+	for rows.Next() {
+		var j Job
+		var posted, notified, closed, interested, application, muted, interview, deadline sql.NullTime
+		var tags string
+		if err := rows.Scan(&j.ID, &j.Company, &j.Title, &j.URL, &j.Location, &j.Team, &j.Description, &j.Level,
+			&j.SalaryMin, &j.SalaryMax, &j.Requisition, &posted, &j.FirstSeen, &j.LastSeen, &notified, &closed, &interested,
+			&j.Application, &application, &muted, &tags, &j.Fit, &j.Feedback,
+			&interview, &deadline); err != nil {
+			return nil, err
+		}
+		if tags != "" {
+			j.Tags = strings.Split(tags, ",")
+		}
+		if posted.Valid {
+			j.PostedAt = &posted.Time
+		}
+		if notified.Valid {
+			j.NotifiedAt = &notified.Time
+		}
+		if closed.Valid {
+			j.ClosedAt = &closed.Time
+		}
+		if interested.Valid {
+			j.InterestedAt = &interested.Time
+		}
+		if application.Valid {
+			j.ApplicationAt = &application.Time
+		}
+		if muted.Valid {
+			j.MutedAt = &muted.Time
+		}
+		if interview.Valid {
+			j.InterviewAt = &interview.Time
+		}
+		if deadline.Valid {
+			j.Deadline = &deadline.Time
+		}
+		jobs = append(jobs, j)
+	}
+	return jobs, rows.Err()
+}
@@ -0,0 +1,31 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/hunterheston/airbnb/pkg/scraper"
+)
+
+// Summary returns the job summary cached under key, or nil if there is
+// none. It implements summary.Cache.
+func (s *Store) Summary(key string) (*scraper.Summary, error) {
+	var sum scraper.Summary
+	err := s.db.QueryRow(`SELECT text, level, remote FROM summaries WHERE key = ?`, key).Scan(&sum.Text, &sum.Level, &sum.Remote)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &sum, nil
+}
+
+// SaveSummary caches sum under key at now, replacing what was there.
+func (s *Store) SaveSummary(key string, sum scraper.Summary, now time.Time) error {
+	_, err := s.db.Exec(`INSERT INTO summaries (key, text, level, remote, created_at) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (key) DO UPDATE SET text = excluded.text, level = excluded.level, remote = excluded.remote,
+			created_at = excluded.created_at`, key, sum.Text, sum.Level, sum.Remote, now)
+	return err
+}
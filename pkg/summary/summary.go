@@ -0,0 +1,267 @@
+// Package summary has a language model summarize job descriptions for the
+// digest: two sentences on what the job is, and how senior and how
+// remote-friendly it reads. Any chat completions API compatible with
+// OpenAI's works, hosted or local (Ollama, llama.cpp, vLLM...).
+//
+// Summaries are cached by job title and description, so a job is
+// summarized once however many digests it is in, and requests are spaced
+// out and capped per digest to stay under the API's rate limits and the
+// budget.
+package summary
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hunterheston/airbnb/pkg/level"
+	"github.com/hunterheston/airbnb/pkg/scraper"
+)
+
+// DefaultURL is OpenAI's API.
+const DefaultURL = "https://api.openai.com/v1"
+
+// maxDescription caps how much of a description the model is sent, in
+// runes, to bound the cost of very long postings.
+const maxDescription = 8000
+
+// Remote-friendliness classes.
+const (
+	Remote = "remote"
+	Hybrid = "hybrid"
+	Onsite = "onsite"
+)
+
+// Config is the summary section of the config file.
+type Config struct {
+	// Model is the model asked, e.g. "gpt-4o-mini". Without one there are
+	// no summaries.
+	Model string `yaml:"model"`
+	// URL is the base URL of the API, DefaultURL unless set; requests go
+	// to its /chat/completions.
+	URL string `yaml:"url"`
+	// APIKey is sent as a bearer token, if set.
+	APIKey string `yaml:"api_key"`
+	// Interval is the least time between two requests.
+	Interval time.Duration `yaml:"interval"`
+	// Max caps the jobs summarized for one digest; the others go out
+	// without a summary. Cached summaries don't count.
+	Max int `yaml:"max"`
+
+	Client *http.Client `yaml:"-"`
+}
+
+// Validate checks c.
+func (c Config) Validate() error {
+	if c.Interval < 0 {
+		return errors.New("summary: interval must not be negative")
+	}
+	if c.Max < 0 {
+		return errors.New("summary: max must not be negative")
+	}
+	return nil
+}
+
+// Cache keeps summaries by Key.
+type Cache interface {
+	// Summary returns the summary cached under key, or nil.
+	Summary(key string) (*scraper.Summary, error)
+	SaveSummary(key string, s scraper.Summary, now time.Time) error
+}
+
+// Summarizer summarizes jobs, caching the summaries.
+type Summarizer struct {
+	Config
+	Cache Cache
+
+	last time.Time
+}
+
+// New returns a Summarizer for c caching in cache.
+func New(c Config, cache Cache) *Summarizer {
+	return &Summarizer{Config: c, Cache: cache}
+}
+
+// Key is what a job's summary is cached under: a hash of the model, the
+// title and the description, so that a changed posting or model is
+// summarized again.
+func (s *Summarizer) Key(job scraper.JobPosting) string {
+	h := sha256.Sum256([]byte(s.Model + "\n" + job.Title + "\n" + job.Description))
+	return hex.EncodeToString(h[:])
+}
+
+// Summarize sets the Summary of the jobs with a description, from the
+// cache or by asking the model. It stops at the first failed request,
+// keeping the summaries it got; the jobs without one go out as they are.
+func (s *Summarizer) Summarize(ctx context.Context, jobs []scraper.JobPosting) error {
+	asked, skipped := 0, 0
+	for i := range jobs {
+		if strings.TrimSpace(jobs[i].Description) == "" {
+			continue
+		}
+		key := s.Key(jobs[i])
+		cached, err := s.Cache.Summary(key)
+		if err != nil {
+			return fmt.Errorf("loading summary: %w", err)
+		}
+		if cached != nil {
+			jobs[i].Summary = cached
+			continue
+		}
+		if s.Max > 0 && asked >= s.Max {
+			skipped++
+			continue
+		}
+		asked++
+		sum, err := s.ask(ctx, jobs[i])
+		if err != nil {
+			return fmt.Errorf("summarizing %s: %w", jobs[i].URL, err)
+		}
+		if err := s.Cache.SaveSummary(key, sum, time.Now()); err != nil {
+			return fmt.Errorf("saving summary: %w", err)
+		}
+		jobs[i].Summary = &sum
+	}
+	if skipped > 0 {
+		slog.Info("jobs left unsummarized", "max", s.Max, "skipped", skipped)
+	}
+	return nil
+}
+
+// prompt is the system message: the model is told to answer in JSON.
+const prompt = `You summarize job postings for a job seeker. Reply with a JSON object with these fields:
+"summary": two plain sentences on what the job is and what it asks for;
+"level": one of "intern", "junior", "mid", "senior", "staff+" or "manager";
+"remote": "remote" if the job can be done fully remotely, "hybrid" if it mixes office and remote days, or "onsite".`
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type chatRequest struct {
+	Model          string        `json:"model"`
+	Messages       []chatMessage `json:"messages"`
+	Temperature    float64       `json:"temperature"`
+	ResponseFormat struct {
+		Type string `json:"type"`
+	} `json:"response_format"`
+}
+
+type chatResponse struct {
+	Choices []struct {
+		Message chatMessage `json:"message"`
+	} `json:"choices"`
+}
+
+// ask has the model summarize job, waiting out Interval first.
+func (s *Summarizer) ask(ctx context.Context, job scraper.JobPosting) (scraper.Summary, error) {
+	if err := s.wait(ctx); err != nil {
+		return scraper.Summary{}, err
+	}
+
+	desc := []rune(job.Description)
+	if len(desc) > maxDescription {
+		desc = desc[:maxDescription]
+	}
+	req := chatRequest{
+		Model: s.Model,
+		Messages: []chatMessage{
+			{Role: "system", Content: prompt},
+			{Role: "user", Content: fmt.Sprintf("Title: %s\nCompany: %s\nLocation: %s\n\n%s", job.Title, job.Company, job.Location, string(desc))},
+		},
+	}
+	req.ResponseFormat.Type = "json_object"
+	var resp chatResponse
+	if err := s.post(ctx, req, &resp); err != nil {
+		return scraper.Summary{}, err
+	}
+	if len(resp.Choices) == 0 {
+		return scraper.Summary{}, errors.New("the response has no choices")
+	}
+
+	var answer struct {
+		Summary string `json:"summary"`
+		Level   string `json:"level"`
+		Remote  string `json:"remote"`
+	}
+	content := strings.TrimSpace(resp.Choices[0].Message.Content)
+	// Some models fence their JSON in Markdown however they are asked.
+	content = strings.TrimSuffix(strings.TrimPrefix(strings.TrimPrefix(content, "```json"), "```"), "```")
+	if err := json.Unmarshal([]byte(content), &answer); err != nil {
+		return scraper.Summary{}, fmt.Errorf("decoding the model's answer: %w", err)
+	}
+	sum := scraper.Summary{Text: strings.TrimSpace(answer.Summary)}
+	if l, err := level.Parse(answer.Level); err == nil {
+		sum.Level = l.String()
+	}
+	switch r := strings.ToLower(strings.TrimSpace(answer.Remote)); r {
+	case Remote, Hybrid, Onsite:
+		sum.Remote = r
+	}
+	if sum.Text == "" {
+		return scraper.Summary{}, errors.New("the model's answer has no summary")
+	}
+	return sum, nil
+}
+
+// wait sleeps until Interval has passed since the last request.
+func (s *Summarizer) wait(ctx context.Context) error {
+	if !s.last.IsZero() {
+		if d := time.Until(s.last.Add(s.Interval)); d > 0 {
+			t := time.NewTimer(d)
+			defer t.Stop()
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-t.C:
+			}
+		}
+	}
+	s.last = time.Now()
+	return nil
+}
+
+// post sends req to the chat completions endpoint and decodes the
+// response into resp.
+func (s *Summarizer) post(ctx context.Context, req chatRequest, resp *chatResponse) error {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	base := s.URL
+	if base == "" {
+		base = DefaultURL
+	}
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(base, "/")+"/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	r.Header.Set("Content-Type", "application/json")
+	if s.APIKey != "" {
+		r.Header.Set("Authorization", "Bearer "+s.APIKey)
+	}
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	res, err := client.Do(r)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 512))
+		return fmt.Errorf("HTTP %d: %s", res.StatusCode, bytes.TrimSpace(msg))
+	}
+	return json.NewDecoder(res.Body).Decode(resp)
+}
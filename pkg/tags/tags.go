@@ -0,0 +1,161 @@
+// Package tags picks out the technologies a job description mentions, such
+// as Go, React or Kubernetes, from a dictionary of their spellings.
+package tags
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hunterheston/airbnb/pkg/scraper"
+)
+
+// Dictionary maps each tag to the other ways of spelling it, e.g.
+// {"Kubernetes": ["k8s"]}. A tag's own name is always one of them.
+type Dictionary map[string][]string
+
+// Default is the dictionary used unless the config replaces it.
+var Default = Dictionary{
+	"Go":               {"Golang"},
+	"Java":             nil,
+	"Kotlin":           nil,
+	"Scala":            nil,
+	"Python":           nil,
+	"Ruby":             {"Rails", "Ruby on Rails"},
+	"Rust":             nil,
+	"C++":              {"cpp"},
+	"C#":               nil,
+	"PHP":              nil,
+	"TypeScript":       nil,
+	"JavaScript":       nil,
+	"React":            {"React.js", "ReactJS"},
+	"Vue":              {"Vue.js"},
+	"Angular":          nil,
+	"Node.js":          {"NodeJS"},
+	"GraphQL":          nil,
+	"Swift":            nil,
+	"SQL":              nil,
+	"PostgreSQL":       {"Postgres"},
+	"MySQL":            nil,
+	"Redis":            nil,
+	"Kafka":            nil,
+	"Spark":            {"Apache Spark"},
+	"Airflow":          nil,
+	"Kubernetes":       {"k8s"},
+	"Docker":           nil,
+	"Terraform":        nil,
+	"AWS":              {"Amazon Web Services"},
+	"GCP":              {"Google Cloud"},
+	"Azure":            nil,
+	"Machine Learning": {"ML"},
+}
+
+// Config is the tags section of the config file.
+type Config struct {
+	// Dictionary adds tags to Default, or respells the default tags of the
+	// same names.
+	Dictionary Dictionary `yaml:"dictionary"`
+	// Replace drops Default, so that only Dictionary is used.
+	Replace bool `yaml:"replace"`
+}
+
+// Validate checks the dictionary's tag names, which are stored joined by
+// commas.
+func (c Config) Validate() error {
+	for tag := range c.Dictionary {
+		if strings.TrimSpace(tag) == "" {
+			return errors.New("tags: a tag has no name")
+		}
+		if strings.Contains(tag, ",") {
+			return fmt.Errorf("tags: tag %q must not contain a comma", tag)
+		}
+	}
+	return nil
+}
+
+// Tagger returns the tagger for c.
+func (c Config) Tagger() *Tagger {
+	d := Dictionary{}
+	if !c.Replace {
+		for tag, spellings := range Default {
+			d[tag] = spellings
+		}
+	}
+	for tag, spellings := range c.Dictionary {
+		d[tag] = spellings
+	}
+	return New(d)
+}
+
+// Tagger tags jobs from a Dictionary.
+type Tagger struct {
+	tags []tag
+}
+
+type tag struct {
+	name string
+	re   *regexp.Regexp
+}
+
+// New returns a Tagger for d.
+func New(d Dictionary) *Tagger {
+	names := make([]string, 0, len(d))
+	for name := range d {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	t := &Tagger{}
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		var alts []string
+		for _, s := range append([]string{name}, d[name]...) {
+			if s = strings.TrimSpace(s); s != "" {
+				alts = append(alts, spelling(s))
+			}
+		}
+		if name == "" || len(alts) == 0 {
+			continue
+		}
+		// Spelled-out word boundaries, as in the score package, so that
+		// spellings like "C++" and "Node.js" match.
+		re := regexp.MustCompile(`(?:^|[^\pL\pN])(?:` + strings.Join(alts, "|") + `)(?:$|[^\pL\pN])`)
+		t.tags = append(t.tags, tag{name, re})
+	}
+	return t
+}
+
+// spelling returns the pattern matching s. Spellings of up to three letters
+// match only as written, since "Go" is a language but "go" mostly isn't and
+// "ML" is not "ml"; longer ones ignore case.
+func spelling(s string) string {
+	if len([]rune(s)) <= 3 {
+		return regexp.QuoteMeta(s)
+	}
+	return `(?i:` + regexp.QuoteMeta(s) + `)`
+}
+
+// Tags returns the tags whose spellings job's title or description
+// mentions as whole words, sorted by name.
+func (t *Tagger) Tags(job scraper.JobPosting) []string {
+	text := job.Title + "\n" + job.Description
+	var found []string
+	for _, tg := range t.tags {
+		if tg.re.MatchString(text) {
+			found = append(found, tg.name)
+		}
+	}
+	return found
+}
+
+// Has reports whether tags holds tag, ignoring case.
+func Has(tags []string, tag string) bool {
+	for _, t := range tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,141 @@
+// Package trace times the stages of a run (fetching and parsing each
+// source's pages, filtering, storing, notifying) as OpenTelemetry spans
+// and exports them to a collector over OTLP/HTTP, so that a slow run can be
+// pinned on a stage and a source.
+//
+// Spans are started with Start, which records nothing until SetDefault is
+// given a Tracer, and are exported in batches and when the Tracer is
+// flushed.
+package trace
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	oteltrace "go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// scope names the instrumentation in the traces.
+const scope = "github.com/hunterheston/airbnb"
+
+// Config is the tracing section of the config file.
+type Config struct {
+	// Endpoint is the OTLP/HTTP collector, e.g. http://localhost:4318;
+	// spans are posted to its /v1/traces unless it has a path of its own.
+	// Without one nothing is traced.
+	Endpoint string `yaml:"endpoint"`
+	// Headers are sent with every export, e.g. a hosted backend's API key.
+	Headers map[string]string `yaml:"headers"`
+	// ServiceName names the program in the traces, "jobwatch" unless set.
+	ServiceName string `yaml:"service_name"`
+
+	Client *http.Client `yaml:"-"`
+}
+
+// Validate checks c.
+func (c Config) Validate() error {
+	if c.Endpoint == "" {
+		return nil
+	}
+	u, err := url.Parse(c.Endpoint)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("tracing: endpoint must be an http or https URL")
+	}
+	return nil
+}
+
+// Tracer returns the tracer exporting to c's endpoint, or nil if it has
+// none.
+func (c Config) Tracer() *Tracer {
+	if c.Endpoint == "" {
+		return nil
+	}
+	endpoint := c.Endpoint
+	if u, err := url.Parse(endpoint); err == nil && strings.Trim(u.Path, "/") == "" {
+		endpoint = strings.TrimSuffix(endpoint, "/") + "/v1/traces"
+	}
+	opts := []otlptracehttp.Option{otlptracehttp.WithEndpointURL(endpoint), otlptracehttp.WithHeaders(c.Headers)}
+	if c.Client != nil {
+		opts = append(opts, otlptracehttp.WithHTTPClient(c.Client))
+	}
+	// The HTTP exporter doesn't connect until it exports, so creating it
+	// can't fail.
+	exporter, _ := otlptracehttp.New(context.Background(), opts...)
+
+	service := c.ServiceName
+	if service == "" {
+		service = "jobwatch"
+	}
+	return &Tracer{provider: sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(service))),
+	)}
+}
+
+// Tracer exports the spans recorded while it is the default. It is safe
+// for concurrent use.
+type Tracer struct {
+	provider *sdktrace.TracerProvider
+}
+
+var defaultTracer atomic.Pointer[Tracer]
+
+// SetDefault makes t the tracer Start records spans for, shutting down
+// the one it replaces. With nil, which is how it starts, spans aren't
+// recorded.
+func SetDefault(t *Tracer) {
+	if t == nil {
+		otel.SetTracerProvider(noop.NewTracerProvider())
+	} else {
+		otel.SetTracerProvider(t.provider)
+	}
+	if old := defaultTracer.Swap(t); old != nil && old != t {
+		old.provider.Shutdown(context.Background())
+	}
+}
+
+// Default returns the tracer set with SetDefault.
+func Default() *Tracer {
+	return defaultTracer.Load()
+}
+
+// Flush exports the spans ended since the last export.
+func (t *Tracer) Flush(ctx context.Context) error {
+	if t == nil {
+		return nil
+	}
+	return t.provider.ForceFlush(ctx)
+}
+
+// Start starts a span named name, as a child of ctx's span if it has one,
+// and returns ctx carrying it.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, oteltrace.Span) {
+	return otel.Tracer(scope).Start(ctx, name, oteltrace.WithAttributes(attrs...))
+}
+
+// StartClient is Start for a request to another service, such as fetching
+// a page.
+func StartClient(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, oteltrace.Span) {
+	return otel.Tracer(scope).Start(ctx, name, oteltrace.WithAttributes(attrs...), oteltrace.WithSpanKind(oteltrace.SpanKindClient))
+}
+
+// SetError marks span as failed with err, if err isn't nil.
+func SetError(span oteltrace.Span, err error) {
+	if err == nil {
+		return
+	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
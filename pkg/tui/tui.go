@@ -0,0 +1,453 @@
+// Package tui is a terminal browser for the stored jobs: fuzzy search,
+// interested marks, application stages and opening postings in the
+// browser, for triaging without leaving the terminal.
+package tui
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/hunterheston/airbnb/pkg/store"
+)
+
+// Run browses the jobs in db until the user quits or ctx is cancelled.
+func Run(ctx context.Context, db *store.Store) error {
+	jobs, err := db.List()
+	if err != nil {
+		return err
+	}
+	m := &model{db: db, jobs: jobs}
+	m.filter()
+	_, err = tea.NewProgram(m, tea.WithContext(ctx), tea.WithAltScreen()).Run()
+	return err
+}
+
+const help = "↑/↓ move  / search  i interested  x not interested (X: and similar)  +/- more/less like this  a applied  n next stage  r rejected  o open  q quit"
+
+// detailLines is how many lines the selected job's details take under the
+// list.
+const detailLines = 6
+
+type model struct {
+	db   *store.Store
+	jobs []store.Job
+	// shown indexes the jobs matching the search, best match first.
+	shown  []int
+	cursor int
+	// top is the first shown job on screen.
+	top int
+
+	query     string
+	searching bool
+	// status is the outcome of the last action, shown until the next key.
+	status string
+
+	width, height int
+}
+
+func (m *model) Init() tea.Cmd {
+	return nil
+}
+
+func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+	case tea.KeyMsg:
+		m.status = ""
+		if m.searching {
+			return m, m.search(msg)
+		}
+		return m, m.key(msg)
+	}
+	return m, nil
+}
+
+// search edits the query while the search field has focus.
+func (m *model) search(msg tea.KeyMsg) tea.Cmd {
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return tea.Quit
+	case tea.KeyEnter:
+		m.searching = false
+	case tea.KeyEsc:
+		m.searching = false
+		m.query = ""
+	case tea.KeyBackspace:
+		if m.query != "" {
+			_, size := utf8.DecodeLastRuneInString(m.query)
+			m.query = m.query[:len(m.query)-size]
+		}
+	case tea.KeyRunes, tea.KeySpace:
+		m.query += string(msg.Runes)
+	default:
+		return nil
+	}
+	m.filter()
+	return nil
+}
+
+func (m *model) key(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "ctrl+c", "q":
+		return tea.Quit
+	case "esc":
+		m.query = ""
+		m.filter()
+	case "/":
+		m.searching = true
+	case "up", "k":
+		m.move(-1)
+	case "down", "j":
+		m.move(1)
+	case "pgup":
+		m.move(-m.listHeight())
+	case "pgdown", " ":
+		m.move(m.listHeight())
+	case "home", "g":
+		m.move(-len(m.shown))
+	case "end", "G":
+		m.move(len(m.shown))
+	case "i":
+		m.toggleInterested()
+	case "x":
+		m.toggleMuted(false)
+	case "X":
+		m.toggleMuted(true)
+	case "+":
+		m.toggleFeedback(store.FeedbackUp)
+	case "-":
+		m.toggleFeedback(store.FeedbackDown)
+	case "a":
+		m.toggleApplication(store.ApplicationApplied)
+	case "n":
+		m.advance()
+	case "r":
+		m.toggleApplication(store.ApplicationRejected)
+	case "o", "enter":
+		if job := m.selected(); job != nil {
+			if err := openURL(job.URL); err != nil {
+				m.status = "Opening the link: " + err.Error()
+			} else {
+				m.status = "Opened " + job.URL
+			}
+		}
+	}
+	return nil
+}
+
+func (m *model) move(n int) {
+	m.cursor = max(0, min(m.cursor+n, len(m.shown)-1))
+}
+
+func (m *model) selected() *store.Job {
+	if m.cursor >= len(m.shown) {
+		return nil
+	}
+	return &m.jobs[m.shown[m.cursor]]
+}
+
+func (m *model) toggleInterested() {
+	job := m.selected()
+	if job == nil {
+		return
+	}
+	now := time.Now()
+	interested := job.InterestedAt == nil
+	if err := m.db.SetInterested(job.ID, interested, now); err != nil {
+		m.status = "Saving: " + err.Error()
+		return
+	}
+	job.InterestedAt = nil
+	if interested {
+		job.InterestedAt = &now
+	}
+}
+
+// toggleMuted mutes the selected job, with similar titles if similar is
+// set, or unmutes it if it was muted. Similar jobs on screen are only
+// shown as muted once the TUI is restarted.
+func (m *model) toggleMuted(similar bool) {
+	job := m.selected()
+	if job == nil {
+		return
+	}
+	now := time.Now()
+	var err error
+	if job.MutedAt != nil {
+		err = m.db.Unmute(job.ID)
+	} else {
+		err = m.db.Mute(job.ID, similar, now)
+	}
+	if err != nil {
+		m.status = "Saving: " + err.Error()
+		return
+	}
+	if job.MutedAt != nil {
+		job.MutedAt = nil
+		m.status = "Unmuted " + job.Title
+		return
+	}
+	job.MutedAt = &now
+	if similar {
+		m.status = "Muted " + job.Title + " and similar titles at " + job.Company
+	} else {
+		m.status = "Muted " + job.Title
+	}
+}
+
+// toggleFeedback gives the selected job feedback, or takes it back if it
+// already had that feedback.
+func (m *model) toggleFeedback(feedback string) {
+	job := m.selected()
+	if job == nil {
+		return
+	}
+	if job.Feedback == feedback {
+		feedback = store.FeedbackNone
+	}
+	if err := m.db.SetFeedback(job.ID, feedback); err != nil {
+		m.status = "Saving: " + err.Error()
+		return
+	}
+	job.Feedback = feedback
+	switch feedback {
+	case store.FeedbackUp:
+		m.status = "Jobs like " + job.Title + " will rank higher"
+	case store.FeedbackDown:
+		m.status = "Jobs like " + job.Title + " will rank lower"
+	}
+}
+
+// toggleApplication sets the selected job's application state, or clears
+// it if it was already state.
+func (m *model) toggleApplication(state string) {
+	job := m.selected()
+	if job == nil {
+		return
+	}
+	if job.Application == state {
+		state = store.ApplicationNone
+	}
+	m.setApplication(job, state)
+}
+
+// advance moves the selected job's application to the next stage, up to
+// an offer.
+func (m *model) advance() {
+	job := m.selected()
+	if job == nil || job.Application == store.ApplicationOffer || job.Application == store.ApplicationRejected {
+		return
+	}
+	i := slices.Index(store.ApplicationStates, job.Application)
+	m.setApplication(job, store.ApplicationStates[i+1])
+}
+
+func (m *model) setApplication(job *store.Job, state string) {
+	now := time.Now()
+	if err := m.db.SetApplication(job.ID, state, now); err != nil {
+		m.status = "Saving: " + err.Error()
+		return
+	}
+	job.Application = state
+	job.ApplicationAt = &now
+}
+
+// filter lists the jobs matching the query, best match first, or every job
+// in the store's order when the query is empty.
+func (m *model) filter() {
+	type match struct{ index, score int }
+	var matches []match
+	for i, job := range m.jobs {
+		text := strings.Join([]string{job.Title, job.Company, job.Location, job.Team}, " ")
+		if score, ok := fuzzy(m.query, text); ok {
+			matches = append(matches, match{i, score})
+		}
+	}
+	sort.SliceStable(matches, func(a, b int) bool { return matches[a].score > matches[b].score })
+	m.shown = m.shown[:0]
+	for _, match := range matches {
+		m.shown = append(m.shown, match.index)
+	}
+	m.cursor, m.top = 0, 0
+}
+
+// fuzzy reports whether every character of query appears in text in order,
+// ignoring case, and scores the match: characters that follow each other
+// or start a word count more.
+func fuzzy(query, text string) (score int, ok bool) {
+	q := []rune(strings.ToLower(query))
+	if len(q) == 0 {
+		return 0, true
+	}
+	t := []rune(strings.ToLower(text))
+	qi, prev := 0, -2
+	for ti, r := range t {
+		if qi == len(q) {
+			break
+		}
+		if r != q[qi] {
+			continue
+		}
+		score++
+		if ti == prev+1 {
+			score += 2
+		}
+		if ti == 0 || t[ti-1] == ' ' {
+			score += 3
+		}
+		prev = ti
+		qi++
+	}
+	return score, qi == len(q)
+}
+
+// listHeight is how many jobs fit on screen.
+func (m *model) listHeight() int {
+	// The header, the search line, the details and the help.
+	return max(1, m.height-3-detailLines-1)
+}
+
+func (m *model) View() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "\x1b[1mjobwatch\x1b[0m  %d of %d jobs\n", len(m.shown), len(m.jobs))
+	switch {
+	case m.searching:
+		fmt.Fprintf(&b, "Search: %s█\n", m.query)
+	case m.query != "":
+		fmt.Fprintf(&b, "Search: %s  (esc clears)\n", m.query)
+	default:
+		b.WriteString("\n")
+	}
+
+	height := m.listHeight()
+	if m.cursor < m.top {
+		m.top = m.cursor
+	} else if m.cursor >= m.top+height {
+		m.top = m.cursor - height + 1
+	}
+	for row := 0; row < height; row++ {
+		i := m.top + row
+		if i >= len(m.shown) {
+			b.WriteString("\n")
+			continue
+		}
+		line := m.row(m.jobs[m.shown[i]])
+		if i == m.cursor {
+			line = "\x1b[7m" + line + "\x1b[0m"
+		} else if job := m.jobs[m.shown[i]]; job.ClosedAt != nil || job.MutedAt != nil {
+			line = "\x1b[2m" + line + "\x1b[0m"
+		}
+		b.WriteString(line + "\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(m.details())
+	if m.status != "" {
+		b.WriteString(m.status)
+	} else {
+		b.WriteString("\x1b[2m" + help + "\x1b[0m")
+	}
+	return b.String()
+}
+
+// row is one job in the list, cut to the terminal's width.
+func (m *model) row(job store.Job) string {
+	mark := " "
+	switch {
+	case job.InterestedAt != nil:
+		mark = "★"
+	case job.MutedAt != nil:
+		mark = "✗"
+	}
+	state := ""
+	if job.Application != store.ApplicationNone {
+		state = " [" + stage(job.Application) + "]"
+	}
+	line := fmt.Sprintf("%s %-10s %s — %s%s", mark, job.Status(), job.Title, job.Company, state)
+	return fit(line, m.width)
+}
+
+// details describes the selected job in detailLines lines.
+func (m *model) details() string {
+	lines := make([]string, detailLines)
+	if job := m.selected(); job != nil {
+		lines[0] = job.Title
+		lines[1] = job.URL
+		lines[2] = joinSet(" · ", job.Company, job.Location, job.Team, job.Salary(), strings.Join(job.Tags, ", "))
+		lines[3] = "First seen " + job.FirstSeen.Local().Format("2006-01-02")
+		if job.ClosedAt != nil {
+			lines[3] += ", closed " + job.ClosedAt.Local().Format("2006-01-02")
+		}
+		if job.Fit > 0 {
+			lines[3] += fmt.Sprintf(" · %d%% fit", job.Fit)
+		}
+		switch job.Feedback {
+		case store.FeedbackUp:
+			lines[3] += " · 👍"
+		case store.FeedbackDown:
+			lines[3] += " · 👎"
+		}
+		if job.Application != store.ApplicationNone && job.ApplicationAt != nil {
+			lines[4] = "Application: " + stage(job.Application) + " since " + job.ApplicationAt.Local().Format("2006-01-02")
+		}
+	}
+	var b strings.Builder
+	for _, line := range lines {
+		b.WriteString(fit(line, m.width) + "\n")
+	}
+	return b.String()
+}
+
+// stage names an application state for display, e.g. "phone screen".
+func stage(state string) string {
+	return strings.ReplaceAll(state, "_", " ")
+}
+
+// joinSet joins the non-empty parts with sep.
+func joinSet(sep string, parts ...string) string {
+	var set []string
+	for _, p := range parts {
+		if p != "" {
+			set = append(set, p)
+		}
+	}
+	return strings.Join(set, sep)
+}
+
+// fit cuts s to width runes; a width of 0, before the terminal's size is
+// known, leaves it whole.
+func fit(s string, width int) string {
+	r := []rune(s)
+	if width <= 0 || len(r) <= width {
+		return s
+	}
+	return string(r[:width-1]) + "…"
+}
+
+// openURL opens url in the default browser without waiting for it.
+func openURL(url string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	go cmd.Wait()
+	return nil
+}
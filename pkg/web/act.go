@@ -0,0 +1,86 @@
+package web
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/hunterheston/airbnb/pkg/actions"
+	"github.com/hunterheston/airbnb/pkg/store"
+)
+
+type actData struct {
+	Job    store.Job
+	Action string
+	// Key and Sig are the link's, carried over to the form that confirms
+	// it.
+	Key, Sig string
+	// Done is set once the action has been taken.
+	Done bool
+}
+
+// ActionHandler serves the digest's action links alone, for exposing them
+// where the dashboard, which takes changes from anyone, shouldn't be.
+func (s *Server) ActionHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /act", s.act)
+	mux.HandleFunc("POST /act", s.act)
+	return mux
+}
+
+// act serves the action links of the digest. Following one (GET) shows a
+// page that confirms it by posting back to the same address; browsers do
+// so at once through a script, while the link scanners of mail services,
+// which fetch links without running scripts, don't take the action.
+func (s *Server) act(w http.ResponseWriter, r *http.Request) {
+	if s.Actions == nil {
+		http.NotFound(w, r)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	data := actData{Key: r.Form.Get("job"), Action: r.Form.Get("do"), Sig: r.Form.Get("sig")}
+	if !s.Actions.Verify(data.Action, data.Key, data.Sig) {
+		http.Error(w, "This link is invalid.", http.StatusForbidden)
+		return
+	}
+	job, err := s.Store.ByKey(data.Key)
+	if errors.Is(err, store.ErrNotFound) {
+		http.Error(w, "This job is no longer in the database.", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		s.fail(w, err)
+		return
+	}
+	data.Job = job
+
+	if r.Method == http.MethodPost {
+		now := time.Now()
+		switch data.Action {
+		case actions.Interested:
+			err = s.Store.SetInterested(job.ID, true, now)
+		case actions.Applied:
+			err = s.Store.SetApplication(job.ID, store.ApplicationApplied, now)
+		case actions.Mute:
+			err = s.Store.Mute(job.ID, false, now)
+		default:
+			http.Error(w, "unknown action", http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			s.fail(w, err)
+			return
+		}
+		data.Done = true
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Referrer-Policy", "no-referrer")
+	if err := pages.ExecuteTemplate(w, "act.html.tmpl", data); err != nil {
+		slog.Warn("rendering action page", "err", err)
+	}
+}
@@ -0,0 +1,235 @@
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/hunterheston/airbnb/pkg/store"
+)
+
+// apiJob is a job as the API returns it.
+type apiJob struct {
+	store.Job
+	Status string `json:"status"`
+	// Notifications and Applications are only filled in for a single job.
+	Notifications []store.Notification     `json:"notifications,omitempty"`
+	Applications  []store.ApplicationEvent `json:"applications,omitempty"`
+}
+
+func newAPIJob(j store.Job) apiJob {
+	return apiJob{Job: j, Status: j.Status()}
+}
+
+// jobPatch is the body of PATCH /jobs/{id}. Fields left out are unchanged.
+type jobPatch struct {
+	Status      *string `json:"status"`
+	Interested  *bool   `json:"interested"`
+	Application *string `json:"application"`
+	Muted       *bool   `json:"muted"`
+	Feedback    *string `json:"feedback"`
+	// InterviewAt is an RFC 3339 time and Deadline a YYYY-MM-DD date; ""
+	// clears either.
+	InterviewAt *string `json:"interview_at"`
+	Deadline    *string `json:"deadline"`
+	// MuteSimilar, with Muted true, mutes the company's jobs with the same
+	// title too.
+	MuteSimilar bool `json:"mute_similar"`
+}
+
+// apiListJobs serves GET /jobs. It takes the dashboard's q, status and
+// interested parameters; with q the best matches come first.
+func (s *Server) apiListJobs(w http.ResponseWriter, r *http.Request) {
+	jobs, _, err := s.find(parseQuery(r.URL.Query()))
+	if err != nil {
+		s.apiError(w, http.StatusInternalServerError, err)
+		return
+	}
+	out := []apiJob{}
+	for _, j := range jobs {
+		out = append(out, newAPIJob(j))
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// apiGetJob serves GET /jobs/{id}.
+func (s *Server) apiGetJob(w http.ResponseWriter, r *http.Request) {
+	j, ok := s.apiJob(w, r)
+	if !ok {
+		return
+	}
+	out := newAPIJob(j)
+	var err error
+	if out.Notifications, err = s.Store.Notifications(j.ID); err != nil {
+		s.apiError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if out.Applications, err = s.Store.Applications(j.ID); err != nil {
+		s.apiError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// apiPatchJob serves PATCH /jobs/{id}, which sets the job's status ("new",
+// "seen" or "closed"), interested mark and application state ("",
+// "applied", "phone_screen", "onsite", "offer" or "rejected"), feedback
+// ("up", "down" or ""), interview time and deadline, and mutes or unmutes
+// it. It returns the updated job.
+func (s *Server) apiPatchJob(w http.ResponseWriter, r *http.Request) {
+	j, ok := s.apiJob(w, r)
+	if !ok {
+		return
+	}
+
+	var patch jobPatch
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<16))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&patch); err != nil {
+		s.apiError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	now := time.Now()
+	if patch.Status != nil {
+		if err := s.Store.SetStatus(j.ID, *patch.Status, now); err != nil {
+			s.apiError(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+	if patch.Interested != nil {
+		if err := s.Store.SetInterested(j.ID, *patch.Interested, now); err != nil {
+			s.apiError(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+	if patch.Application != nil {
+		if err := s.Store.SetApplication(j.ID, *patch.Application, now); err != nil {
+			s.apiError(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+	if patch.Muted != nil {
+		if err := setMuted(s.Store, j.ID, *patch.Muted, patch.MuteSimilar, now); err != nil {
+			s.apiError(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+	if patch.Feedback != nil {
+		if err := s.Store.SetFeedback(j.ID, *patch.Feedback); err != nil {
+			s.apiError(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+	if patch.InterviewAt != nil {
+		at, err := parseDate(*patch.InterviewAt, time.RFC3339)
+		if err != nil {
+			s.apiError(w, http.StatusBadRequest, err)
+			return
+		}
+		if err := s.Store.SetInterview(j.ID, at, now); err != nil {
+			s.apiError(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+	if patch.Deadline != nil {
+		day, err := parseDate(*patch.Deadline, time.DateOnly)
+		if err != nil {
+			s.apiError(w, http.StatusBadRequest, err)
+			return
+		}
+		if err := s.Store.SetDeadline(j.ID, day, now); err != nil {
+			s.apiError(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	if j, err := s.Store.Get(j.ID); err != nil {
+		s.apiError(w, http.StatusInternalServerError, err)
+	} else {
+		writeJSON(w, http.StatusOK, newAPIJob(j))
+	}
+}
+
+// parseDate parses s with layout in local time; "" is nil.
+func parseDate(s, layout string) (*time.Time, error) {
+	if s == "" {
+		return nil, nil
+	}
+	t, err := time.ParseInLocation(layout, s, time.Local)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// apiListRuns serves GET /runs, newest first. limit caps the number
+// returned; it defaults to 50.
+func (s *Server) apiListRuns(w http.ResponseWriter, r *http.Request) {
+	limit := 50
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			s.apiError(w, http.StatusBadRequest, errors.New("limit must be a non-negative number"))
+			return
+		}
+		limit = n
+	}
+	runs, err := s.Store.Runs(limit)
+	if err != nil {
+		s.apiError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if runs == nil {
+		runs = []store.Run{}
+	}
+	writeJSON(w, http.StatusOK, runs)
+}
+
+// apiJob loads the job named by the {id} path parameter, answering the
+// request itself when that fails.
+func (s *Server) apiJob(w http.ResponseWriter, r *http.Request) (store.Job, bool) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		s.apiError(w, http.StatusBadRequest, errors.New("invalid job id"))
+		return store.Job{}, false
+	}
+	j, err := s.Store.Get(id)
+	if errors.Is(err, store.ErrNotFound) {
+		s.apiError(w, http.StatusNotFound, err)
+		return store.Job{}, false
+	}
+	if err != nil {
+		s.apiError(w, http.StatusInternalServerError, err)
+		return store.Job{}, false
+	}
+	return j, true
+}
+
+// apiError answers with {"error": "..."}. Internal errors are logged and
+// not shown to the client.
+func (s *Server) apiError(w http.ResponseWriter, code int, err error) {
+	msg := err.Error()
+	if code == http.StatusInternalServerError {
+		slog.Error("API request failed", "err", err)
+		msg = "internal error"
+	}
+	writeJSON(w, code, map[string]string{"error": msg})
+}
+
+// writeJSON writes v as indented JSON, as application/json unless the
+// caller chose a Content-Type.
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	if w.Header().Get("Content-Type") == "" {
+		w.Header().Set("Content-Type", "application/json")
+	}
+	w.WriteHeader(code)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		slog.Warn("writing API response", "err", err)
+	}
+}
@@ -0,0 +1,60 @@
+package web
+
+import (
+	"crypto/subtle"
+	"expvar"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"strings"
+)
+
+func init() {
+	expvar.Publish("goroutines", expvar.Func(func() any { return runtime.NumGoroutine() }))
+}
+
+// EnableDebug serves the Go runtime's profiles under /debug/pprof/ and its
+// variables, memory statistics included, at /debug/vars. Requests must
+// carry token, as a bearer token or a token query parameter, which suits
+// "go tool pprof". With an empty token only requests made on this machine
+// are served, so the server should then listen on a loopback address only:
+// a reverse proxy on the same machine makes every request look local,
+// though those that say they were forwarded are refused.
+func (s *Server) EnableDebug(token string) {
+	guard := func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !debugAllowed(r, token) {
+				http.Error(w, "forbidden", http.StatusForbidden)
+				return
+			}
+			h.ServeHTTP(w, r)
+		})
+	}
+	s.mux.Handle("GET /debug/vars", guard(expvar.Handler()))
+	s.mux.Handle("GET /debug/pprof/", guard(http.HandlerFunc(pprof.Index)))
+	s.mux.Handle("GET /debug/pprof/cmdline", guard(http.HandlerFunc(pprof.Cmdline)))
+	s.mux.Handle("GET /debug/pprof/profile", guard(http.HandlerFunc(pprof.Profile)))
+	s.mux.Handle("GET /debug/pprof/symbol", guard(http.HandlerFunc(pprof.Symbol)))
+	s.mux.Handle("POST /debug/pprof/symbol", guard(http.HandlerFunc(pprof.Symbol)))
+	s.mux.Handle("GET /debug/pprof/trace", guard(http.HandlerFunc(pprof.Trace)))
+}
+
+// debugAllowed reports whether r may see the debug endpoints.
+func debugAllowed(r *http.Request, token string) bool {
+	if token == "" {
+		for _, h := range []string{"Forwarded", "X-Forwarded-For", "X-Real-Ip"} {
+			if r.Header.Get(h) != "" {
+				return false
+			}
+		}
+		host, _, err := net.SplitHostPort(r.RemoteAddr)
+		ip := net.ParseIP(host)
+		return err == nil && ip != nil && ip.IsLoopback()
+	}
+	got := r.URL.Query().Get("token")
+	if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		got = bearer
+	}
+	return subtle.ConstantTimeCompare([]byte(got), []byte(token)) == 1
+}
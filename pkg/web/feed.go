@@ -0,0 +1,277 @@
+package web
+
+import (
+	"encoding/xml"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hunterheston/airbnb/pkg/scraper"
+	"github.com/hunterheston/airbnb/pkg/store"
+)
+
+const (
+	feedTitle = "Job postings"
+	// feedLimit is how many jobs a feed lists unless ?limit= says otherwise.
+	feedLimit = 50
+)
+
+// feedJobs returns the jobs a feed request selects, newest first. The
+// dashboard's query parameters apply; without a status, closed jobs are
+// left out.
+func (s *Server) feedJobs(r *http.Request) ([]store.Job, error) {
+	v := r.URL.Query()
+	q := parseQuery(v)
+	jobs, _, err := s.find(q)
+	if err != nil {
+		return nil, err
+	}
+	// Searches come best match first; feeds are by date.
+	sort.SliceStable(jobs, func(a, b int) bool { return jobs[a].FirstSeen.After(jobs[b].FirstSeen) })
+	limit := feedLimit
+	if n, err := strconv.Atoi(v.Get("limit")); err == nil && n > 0 {
+		limit = n
+	}
+
+	var out []store.Job
+	for _, j := range jobs {
+		if len(out) == limit {
+			break
+		}
+		if q.Status == "" && j.Status() == store.StatusClosed {
+			continue
+		}
+		out = append(out, j)
+	}
+	return out, nil
+}
+
+// baseURL is the scheme and host the request was made to, for the links a
+// feed reader needs to be absolute.
+func baseURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if proto := r.Header.Get("X-Forwarded-Proto"); proto != "" {
+		scheme = proto
+	}
+	return scheme + "://" + r.Host
+}
+
+// summary describes a job in one short paragraph.
+func summary(j scraper.JobPosting) string {
+	parts := []string{j.Company}
+	for _, s := range []string{j.Location, j.Team} {
+		if s != "" {
+			parts = append(parts, s)
+		}
+	}
+	text := strings.Join(parts, " · ")
+	if j.Description != "" {
+		text += "\n\n" + scraper.Excerpt(j.Description, 500)
+	}
+	return text
+}
+
+// updated returns when the newest of jobs was first seen, or now.
+func updated(jobs []store.Job) time.Time {
+	if len(jobs) == 0 {
+		return time.Now()
+	}
+	return jobs[0].FirstSeen
+}
+
+type rss struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Atom    string     `xml:"xmlns:atom,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Self          atomLink  `xml:"atom:link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string  `xml:"title"`
+	Link        string  `xml:"link"`
+	GUID        rssGUID `xml:"guid"`
+	Description string  `xml:"description"`
+	Category    string  `xml:"category,omitempty"`
+	PubDate     string  `xml:"pubDate"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+// rssFeed serves GET /feed.xml, an RSS 2.0 feed.
+func (s *Server) rssFeed(w http.ResponseWriter, r *http.Request) {
+	jobs, err := s.feedJobs(r)
+	if err != nil {
+		s.fail(w, err)
+		return
+	}
+	base := baseURL(r)
+	feed := rss{
+		Version: "2.0",
+		Atom:    "http://www.w3.org/2005/Atom",
+		Channel: rssChannel{
+			Title:         feedTitle,
+			Link:          base + "/",
+			Self:          atomLink{Href: base + r.URL.RequestURI(), Rel: "self", Type: "application/rss+xml"},
+			Description:   "Job postings matching the scraper's filters",
+			LastBuildDate: updated(jobs).Format(time.RFC1123Z),
+		},
+	}
+	for _, j := range jobs {
+		feed.Channel.Items = append(feed.Channel.Items, rssItem{
+			Title:       j.Title,
+			Link:        j.URL,
+			GUID:        rssGUID{IsPermaLink: true, Value: j.URL},
+			Description: summary(j.JobPosting),
+			Category:    j.Company,
+			PubDate:     j.FirstSeen.Format(time.RFC1123Z),
+		})
+	}
+	writeXML(w, "application/rss+xml; charset=utf-8", feed)
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomEntry struct {
+	ID        string     `xml:"id"`
+	Title     string     `xml:"title"`
+	Updated   string     `xml:"updated"`
+	Published string     `xml:"published"`
+	Link      atomLink   `xml:"link"`
+	Author    atomAuthor `xml:"author"`
+	Summary   string     `xml:"summary"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+// atomFeed serves GET /feed.atom, an Atom feed.
+func (s *Server) atomFeed(w http.ResponseWriter, r *http.Request) {
+	jobs, err := s.feedJobs(r)
+	if err != nil {
+		s.fail(w, err)
+		return
+	}
+	base := baseURL(r)
+	self := base + r.URL.RequestURI()
+	feed := atomFeed{
+		ID:      self,
+		Title:   feedTitle,
+		Updated: updated(jobs).Format(time.RFC3339),
+		Links: []atomLink{
+			{Href: self, Rel: "self", Type: "application/atom+xml"},
+			{Href: base + "/", Rel: "alternate", Type: "text/html"},
+		},
+	}
+	for _, j := range jobs {
+		feed.Entries = append(feed.Entries, atomEntry{
+			ID:        j.URL,
+			Title:     j.Title,
+			Updated:   j.LastSeen.Format(time.RFC3339),
+			Published: j.FirstSeen.Format(time.RFC3339),
+			Link:      atomLink{Href: j.URL, Rel: "alternate"},
+			Author:    atomAuthor{Name: j.Company},
+			Summary:   summary(j.JobPosting),
+		})
+	}
+	writeXML(w, "application/atom+xml; charset=utf-8", feed)
+}
+
+type jsonFeed struct {
+	Version     string         `json:"version"`
+	Title       string         `json:"title"`
+	HomePageURL string         `json:"home_page_url"`
+	FeedURL     string         `json:"feed_url"`
+	Items       []jsonFeedItem `json:"items"`
+}
+
+type jsonFeedItem struct {
+	ID            string           `json:"id"`
+	URL           string           `json:"url"`
+	Title         string           `json:"title"`
+	ContentText   string           `json:"content_text"`
+	DatePublished time.Time        `json:"date_published"`
+	DateModified  time.Time        `json:"date_modified"`
+	Authors       []jsonFeedAuthor `json:"authors,omitempty"`
+	Tags          []string         `json:"tags,omitempty"`
+}
+
+type jsonFeedAuthor struct {
+	Name string `json:"name"`
+}
+
+// jsonFeed serves GET /feed.json, a JSON Feed 1.1.
+func (s *Server) jsonFeed(w http.ResponseWriter, r *http.Request) {
+	jobs, err := s.feedJobs(r)
+	if err != nil {
+		s.fail(w, err)
+		return
+	}
+	base := baseURL(r)
+	feed := jsonFeed{
+		Version:     "https://jsonfeed.org/version/1.1",
+		Title:       feedTitle,
+		HomePageURL: base + "/",
+		FeedURL:     base + r.URL.RequestURI(),
+		Items:       []jsonFeedItem{},
+	}
+	for _, j := range jobs {
+		item := jsonFeedItem{
+			ID:            j.URL,
+			URL:           j.URL,
+			Title:         j.Title,
+			ContentText:   summary(j.JobPosting),
+			DatePublished: j.FirstSeen,
+			DateModified:  j.LastSeen,
+			Authors:       []jsonFeedAuthor{{Name: j.Company}},
+		}
+		if j.Level != "" {
+			item.Tags = []string{j.Level}
+		}
+		item.Tags = append(item.Tags, j.Tags...)
+		feed.Items = append(feed.Items, item)
+	}
+	w.Header().Set("Content-Type", "application/feed+json; charset=utf-8")
+	writeJSON(w, http.StatusOK, feed)
+}
+
+func writeXML(w http.ResponseWriter, contentType string, v any) {
+	w.Header().Set("Content-Type", contentType)
+	w.Write([]byte(xml.Header))
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		slog.Warn("writing feed", "err", err)
+	}
+}
@@ -0,0 +1,137 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/hunterheston/airbnb/pkg/store"
+)
+
+const icsStamp = "20060102T150405Z"
+
+// icsEvent is a VEVENT: timed from Start to End, or all day on Start's
+// date.
+type icsEvent struct {
+	UID         string
+	Summary     string
+	Description string
+	URL         string
+	Start, End  time.Time
+	AllDay      bool
+}
+
+// calendar serves GET /calendar.ics, an iCalendar feed of the application
+// tracker that calendar apps can subscribe to: each job's interview and
+// application deadline, and with FollowUp set, the day each application
+// waiting on the employer is due a follow-up.
+func (s *Server) calendar(w http.ResponseWriter, r *http.Request) {
+	jobs, err := s.Store.Tracked()
+	if err != nil {
+		s.fail(w, err)
+		return
+	}
+	length := s.InterviewLength
+	if length <= 0 {
+		length = time.Hour
+	}
+
+	var events []icsEvent
+	for _, j := range jobs {
+		title := fmt.Sprintf("%s at %s", j.Title, j.Company)
+		description := j.URL
+		if j.Application != "" {
+			description = "Application: " + strings.ReplaceAll(j.Application, "_", " ") + "\n" + description
+		}
+		event := func(kind, summary string, start, end time.Time, allDay bool) {
+			events = append(events, icsEvent{
+				UID:         fmt.Sprintf("%s-%d@jobwatch", kind, j.ID),
+				Summary:     summary + title,
+				Description: description,
+				URL:         j.URL,
+				Start:       start,
+				End:         end,
+				AllDay:      allDay,
+			})
+		}
+		if j.InterviewAt != nil {
+			event("interview", "Interview: ", *j.InterviewAt, j.InterviewAt.Add(length), false)
+		}
+		if j.Deadline != nil {
+			event("deadline", "Application deadline: ", *j.Deadline, *j.Deadline, true)
+		}
+		if s.FollowUp > 0 && j.ApplicationAt != nil && store.Awaiting(j.Application) {
+			due := j.ApplicationAt.Add(s.FollowUp)
+			event("follow-up", "Follow up: ", due, due, true)
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Write([]byte(ics(events, time.Now())))
+}
+
+// ics writes events as an iCalendar (RFC 5545) file stamped now. All-day
+// events fall on their dates in local time.
+func ics(events []icsEvent, now time.Time) string {
+	var b strings.Builder
+	line := func(name, value string) {
+		b.WriteString(fold(name + ":" + value))
+		b.WriteString("\r\n")
+	}
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", "-//jobwatch//application tracker//EN")
+	line("CALSCALE", "GREGORIAN")
+	line("X-WR-CALNAME", "Job applications")
+	for _, e := range events {
+		line("BEGIN", "VEVENT")
+		line("UID", e.UID)
+		line("DTSTAMP", now.UTC().Format(icsStamp))
+		if e.AllDay {
+			// DTEND is exclusive.
+			line("DTSTART;VALUE=DATE", e.Start.Local().Format("20060102"))
+			line("DTEND;VALUE=DATE", e.End.Local().AddDate(0, 0, 1).Format("20060102"))
+		} else {
+			line("DTSTART", e.Start.UTC().Format(icsStamp))
+			line("DTEND", e.End.UTC().Format(icsStamp))
+		}
+		line("SUMMARY", icsText(e.Summary))
+		line("DESCRIPTION", icsText(e.Description))
+		if e.URL != "" {
+			line("URL", e.URL)
+		}
+		line("END", "VEVENT")
+	}
+	line("END", "VCALENDAR")
+	return b.String()
+}
+
+var icsEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+// icsText escapes s as an iCalendar TEXT value.
+func icsText(s string) string {
+	return icsEscaper.Replace(s)
+}
+
+// fold breaks a content line into lines of at most 75 bytes, as RFC 5545
+// requires, each continuation starting with a space. It doesn't split
+// UTF-8 sequences.
+func fold(s string) string {
+	var b strings.Builder
+	limit := 75
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		b.WriteString(s[:cut])
+		b.WriteString("\r\n ")
+		s = s[cut:]
+		// The leading space counts.
+		limit = 74
+	}
+	b.WriteString(s)
+	return b.String()
+}
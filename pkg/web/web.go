@@ -0,0 +1,309 @@
+// Package web serves a small dashboard for browsing the stored jobs and
+// marking the interesting ones, the JSON API behind it, feeds of the open
+// jobs and a calendar of the applications.
+package web
+
+import (
+	"embed"
+	"errors"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hunterheston/airbnb/pkg/actions"
+	"github.com/hunterheston/airbnb/pkg/store"
+	"github.com/hunterheston/airbnb/pkg/tags"
+)
+
+//go:embed templates
+var templates embed.FS
+
+var pages = template.Must(template.New("").Funcs(template.FuncMap{
+	"date": func(t time.Time) string { return t.Local().Format("2006-01-02") },
+}).ParseFS(templates, "templates/*.tmpl"))
+
+// Server is the dashboard's HTTP handler.
+type Server struct {
+	Store *store.Store
+	// Actions, if set, checks the digest's action links, which are only
+	// served when it is.
+	Actions *actions.Signer
+	// FollowUp is how long after an application last moved the calendar
+	// says to follow up on it; zero leaves follow-ups out.
+	FollowUp time.Duration
+	// InterviewLength is how long the calendar's interviews last, an hour
+	// unless set.
+	InterviewLength time.Duration
+	mux             *http.ServeMux
+}
+
+// NewServer returns a dashboard backed by db.
+func NewServer(db *store.Store) *Server {
+	s := &Server{Store: db, mux: http.NewServeMux()}
+	s.mux.HandleFunc("GET /{$}", s.index)
+	s.mux.HandleFunc("POST /interested", sameOrigin(s.interested))
+	s.mux.HandleFunc("POST /mute", sameOrigin(s.mute))
+	s.mux.HandleFunc("POST /feedback", sameOrigin(s.feedback))
+	s.mux.HandleFunc("GET /act", s.act)
+	s.mux.HandleFunc("POST /act", s.act)
+	s.mux.HandleFunc("GET /jobs", s.apiListJobs)
+	s.mux.HandleFunc("GET /jobs/{id}", s.apiGetJob)
+	s.mux.HandleFunc("PATCH /jobs/{id}", s.apiPatchJob)
+	s.mux.HandleFunc("GET /runs", s.apiListRuns)
+	s.mux.HandleFunc("GET /feed.xml", s.rssFeed)
+	s.mux.HandleFunc("GET /feed.atom", s.atomFeed)
+	s.mux.HandleFunc("GET /feed.json", s.jsonFeed)
+	s.mux.HandleFunc("GET /calendar.ics", s.calendar)
+	return s
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// sameOrigin refuses form posts that another site's page made the browser
+// send, so that visiting it can't mark jobs behind the user's back. Browsers
+// say where a post came from in Sec-Fetch-Site or, older ones, in Origin;
+// a request with neither didn't come from a browser and is let through.
+func sameOrigin(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ok := true
+		switch site := r.Header.Get("Sec-Fetch-Site"); {
+		case site != "":
+			ok = site == "same-origin" || site == "none"
+		case r.Header.Get("Origin") != "":
+			u, err := url.Parse(r.Header.Get("Origin"))
+			ok = err == nil && u.Host == r.Host
+		}
+		if !ok {
+			http.Error(w, "cross-origin request refused", http.StatusForbidden)
+			return
+		}
+		h(w, r)
+	}
+}
+
+// Query is what the dashboard's search form selects.
+type Query struct {
+	// Search finds jobs by the words in their title, company, location,
+	// team or description; see store.Search.
+	Search string
+	// Status is "", store.StatusNew, store.StatusSeen or store.StatusClosed.
+	Status string
+	// Interested keeps only jobs marked as interesting.
+	Interested bool
+	// Level keeps only jobs of that seniority, e.g. "senior", and Location
+	// those whose location contains it, case-insensitively.
+	Level    string
+	Location string
+	// Tag keeps only jobs with that tag, e.g. "Go", ignoring case.
+	Tag string
+}
+
+func parseQuery(v url.Values) Query {
+	return Query{
+		Search:     strings.TrimSpace(v.Get("q")),
+		Status:     v.Get("status"),
+		Interested: v.Get("interested") != "",
+		Level:      v.Get("level"),
+		Location:   strings.TrimSpace(v.Get("location")),
+		Tag:        strings.TrimSpace(v.Get("tag")),
+	}
+}
+
+// Encode returns q as URL query parameters.
+func (q Query) Encode() string {
+	v := url.Values{}
+	if q.Search != "" {
+		v.Set("q", q.Search)
+	}
+	if q.Status != "" {
+		v.Set("status", q.Status)
+	}
+	if q.Interested {
+		v.Set("interested", "1")
+	}
+	if q.Level != "" {
+		v.Set("level", q.Level)
+	}
+	if q.Location != "" {
+		v.Set("location", q.Location)
+	}
+	if q.Tag != "" {
+		v.Set("tag", q.Tag)
+	}
+	return v.Encode()
+}
+
+// Match reports whether j is selected by q's other criteria than Search,
+// which the store applies.
+func (q Query) Match(j store.Job) bool {
+	if q.Status != "" && j.Status() != q.Status {
+		return false
+	}
+	if q.Interested && j.InterestedAt == nil {
+		return false
+	}
+	if q.Level != "" && !strings.EqualFold(j.Level, q.Level) {
+		return false
+	}
+	if q.Location != "" && !strings.Contains(strings.ToLower(j.Location), strings.ToLower(q.Location)) {
+		return false
+	}
+	if q.Tag != "" && !tags.Has(j.Tags, q.Tag) {
+		return false
+	}
+	return true
+}
+
+// find returns the stored jobs q selects, newest first or, when q
+// searches, best match first, with the matching passages by job ID.
+func (s *Server) find(q Query) ([]store.Job, map[int64]string, error) {
+	var jobs []store.Job
+	snippets := map[int64]string{}
+	if q.Search == "" {
+		all, err := s.Store.List()
+		if err != nil {
+			return nil, nil, err
+		}
+		jobs = all
+	} else {
+		hits, err := s.Store.Search(q.Search, 0)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, h := range hits {
+			jobs = append(jobs, h.Job)
+			snippets[h.ID] = h.Snippet
+		}
+	}
+	var out []store.Job
+	for _, j := range jobs {
+		if q.Match(j) {
+			out = append(out, j)
+		}
+	}
+	return out, snippets, nil
+}
+
+type indexData struct {
+	Query    Query
+	Statuses []string
+	Jobs     []store.Job
+	// Snippets are the passages a search matched, by job ID.
+	Snippets map[int64]string
+	Total    int
+}
+
+func (s *Server) index(w http.ResponseWriter, r *http.Request) {
+	jobs, err := s.Store.List()
+	if err != nil {
+		s.fail(w, err)
+		return
+	}
+
+	data := indexData{
+		Query:    parseQuery(r.URL.Query()),
+		Statuses: []string{store.StatusNew, store.StatusSeen, store.StatusClosed},
+		Total:    len(jobs),
+	}
+	if data.Jobs, data.Snippets, err = s.find(data.Query); err != nil {
+		s.fail(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := pages.ExecuteTemplate(w, "index.html.tmpl", data); err != nil {
+		slog.Warn("rendering dashboard", "err", err)
+	}
+}
+
+// interested sets or clears a job's interested mark, then goes back to the
+// list the form was submitted from.
+func (s *Server) interested(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	id, err := strconv.ParseInt(r.PostForm.Get("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid job id", http.StatusBadRequest)
+		return
+	}
+	err = s.Store.SetInterested(id, r.PostForm.Get("interested") == "1", time.Now())
+	s.back(w, r, err)
+}
+
+// mute marks a job as not interesting, with its similar titles if similar
+// is set, or unmutes it, then goes back to the list.
+func (s *Server) mute(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	id, err := strconv.ParseInt(r.PostForm.Get("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid job id", http.StatusBadRequest)
+		return
+	}
+	err = setMuted(s.Store, id, r.PostForm.Get("muted") == "1", r.PostForm.Get("similar") == "1", time.Now())
+	s.back(w, r, err)
+}
+
+// feedback gives a job a thumbs-up or -down, or takes it back, then goes
+// back to the list.
+func (s *Server) feedback(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	id, err := strconv.ParseInt(r.PostForm.Get("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid job id", http.StatusBadRequest)
+		return
+	}
+	feedback := r.PostForm.Get("feedback")
+	if feedback != store.FeedbackNone && feedback != store.FeedbackUp && feedback != store.FeedbackDown {
+		http.Error(w, "invalid feedback", http.StatusBadRequest)
+		return
+	}
+	err = s.Store.SetFeedback(id, feedback)
+	s.back(w, r, err)
+}
+
+// setMuted mutes or unmutes the job with the given ID.
+func setMuted(db *store.Store, id int64, muted, similar bool, now time.Time) error {
+	if muted {
+		return db.Mute(id, similar, now)
+	}
+	return db.Unmute(id)
+}
+
+// back answers a form post that ended with err by going back to the list
+// the form was submitted from.
+func (s *Server) back(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, store.ErrNotFound) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		s.fail(w, err)
+		return
+	}
+
+	back := "/"
+	if q := r.PostForm.Get("return"); q != "" {
+		back += "?" + q
+	}
+	http.Redirect(w, r, back, http.StatusSeeOther)
+}
+
+func (s *Server) fail(w http.ResponseWriter, err error) {
+	slog.Error("dashboard request failed", "err", err)
+	http.Error(w, "internal error", http.StatusInternalServerError)
+}